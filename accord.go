@@ -12,11 +12,20 @@ var (
 	ErrDone = errors.New("accord: done")
 	// ErrClosed error is returned by the handle if closed.
 	ErrClosed = errors.New("accord: closed")
+	// ErrInvalidTTL error is returned if the TTL is below one second.
+	ErrInvalidTTL = errors.New("accord: invalid TTL")
+	// ErrInvalidTransfer error is returned if a transfer token cannot be resumed.
+	ErrInvalidTransfer = errors.New("accord: invalid transfer token")
+	// ErrVersionMismatch error is returned if the expected metadata version does not match.
+	ErrVersionMismatch = errors.New("accord: metadata version mismatch")
 )
 
 type metadata struct {
-	kv map[string]string
-	mu sync.RWMutex
+	kv      map[string]string
+	deleted map[string]struct{} // keys pending deletion
+	replace uint64              // sequence number of the last Replace
+	synced  uint64              // sequence number of the last persisted Replace
+	mu      sync.RWMutex
 }
 
 // Snap returns a snapshot of metadata.
@@ -38,6 +47,66 @@ func (m *metadata) Set(k, v string) {
 		m.kv = make(map[string]string)
 	}
 	m.kv[k] = v
+	delete(m.deleted, k)
+	m.mu.Unlock()
+}
+
+// Delete deletes key.
+func (m *metadata) Delete(k string) {
+	m.mu.Lock()
+	delete(m.kv, k)
+	if m.deleted == nil {
+		m.deleted = make(map[string]struct{})
+	}
+	m.deleted[k] = struct{}{}
+	m.mu.Unlock()
+}
+
+// Replace replaces all metadata with kv.
+func (m *metadata) Replace(kv map[string]string) {
+	m.mu.Lock()
+	m.kv = make(map[string]string, len(kv))
+	for k, v := range kv {
+		m.kv[k] = v
+	}
+	m.deleted = nil
+	m.replace++
+	m.mu.Unlock()
+}
+
+// Pending returns a snapshot of metadata, the keys pending deletion and
+// the sequence number of a pending Replace, which is zero if there is none.
+func (m *metadata) Pending() (map[string]string, []string, uint64) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snap := make(map[string]string, len(m.kv))
+	for k, v := range m.kv {
+		snap[k] = v
+	}
+
+	var deleted []string
+	for k := range m.deleted {
+		deleted = append(deleted, k)
+	}
+
+	var replace uint64
+	if m.replace != m.synced {
+		replace = m.replace
+	}
+	return snap, deleted, replace
+}
+
+// Ack clears keys which have been successfully deleted and marks a
+// Replace as persisted.
+func (m *metadata) Ack(deleted []string, replace uint64) {
+	m.mu.Lock()
+	for _, k := range deleted {
+		delete(m.deleted, k)
+	}
+	if replace > m.synced {
+		m.synced = replace
+	}
 	m.mu.Unlock()
 }
 
@@ -49,6 +118,7 @@ func (m *metadata) Update(kv map[string]string) {
 	}
 	for k, v := range kv {
 		m.kv[k] = v
+		delete(m.deleted, k)
 	}
 	m.mu.Unlock()
 }
@@ -3,6 +3,8 @@ package backend
 import (
 	"context"
 	"errors"
+	"maps"
+	"strings"
 	"time"
 
 	"github.com/bsm/accord/rpc"
@@ -14,6 +16,10 @@ var (
 	ErrIteratorDone = errors.New("accord: iterator done")
 	// ErrInvalidHandle returned by the backend if the handle cannot be used or has expired.
 	ErrInvalidHandle = errors.New("accord: invalid handle")
+	// ErrVersionMismatch returned by the backend if the expected metadata version does not match.
+	ErrVersionMismatch = errors.New("accord: metadata version mismatch")
+	// ErrStaleToken returned by the backend if the fencing token is not the current one.
+	ErrStaleToken = errors.New("accord: stale fencing token")
 )
 
 // Iterator function. Return ErrIteratorDone to cancel gracefully.
@@ -22,20 +28,43 @@ type Iterator func(*HandleData) error
 // Backend represents a storage/persistent backend for handle information.
 type Backend interface {
 	// Acquire acquires a new named resource handle within namespace until exp time.
-	Acquire(ctx context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string) (*HandleData, error)
+	Acquire(ctx context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string, opt *AcquireOptions) (*HandleData, error)
 
 	// Renew renews a handle with a specific exp time and returns the updated handle.
-	Renew(ctx context.Context, owner string, handleID uuid.UUID, exp time.Time, metadata map[string]string) error
+	Renew(ctx context.Context, owner string, handleID uuid.UUID, exp time.Time, metadata map[string]string, opt *UpdateOptions) error
 
 	// Done marks the resource as done.
-	Done(ctx context.Context, owner string, handleID uuid.UUID, metadata map[string]string) error
+	Done(ctx context.Context, owner string, handleID uuid.UUID, metadata map[string]string, opt *UpdateOptions) error
+
+	// Transfer atomically reassigns a held handle to a new owner, optionally
+	// rotating the handle ID. It issues a new fencing token and returns the
+	// updated handle.
+	Transfer(ctx context.Context, owner string, handleID uuid.UUID, newOwner string, rotateID bool) (*HandleData, error)
 
 	// Get retrieves handle data by ID.
 	Get(ctx context.Context, handleID uuid.UUID) (*HandleData, error)
 
+	// ValidateToken returns ErrStaleToken unless the handle is still pending
+	// and token is its current fencing token. Unlike Get, it must not be
+	// served from stale replicas.
+	ValidateToken(ctx context.Context, handleID uuid.UUID, token int64) error
+
 	// List iterates over done resources within a namespace
 	List(ctx context.Context, req *rpc.ListRequest, iter Iterator) error
 
+	// Count returns the number of handles matching the filter. Backends may
+	// return an estimate.
+	Count(ctx context.Context, filter *rpc.ListRequest_Filter) (int64, error)
+
+	// MarkDone inserts resources as done, without acquiring them first. It
+	// returns an error per record, which is nil on success, accord.ErrDone or
+	// accord.ErrAcquired if the resource already exists.
+	MarkDone(ctx context.Context, records []DoneRecord) ([]error, error)
+
+	// Purge deletes done handles matching the filter and returns the number
+	// of deleted handles.
+	Purge(ctx context.Context, filter *PurgeFilter) (int64, error)
+
 	// Ping pings the backend connection.
 	Ping() error
 
@@ -43,18 +72,115 @@ type Backend interface {
 	Close() error
 }
 
+// AcquireOptions contains optional Acquire arguments.
+type AcquireOptions struct {
+	// Capacity allows up to Capacity concurrent holders of the resource,
+	// each occupying one slot. Done releases a slot, rather than marking the
+	// whole resource as done. All holders of a resource should use the same
+	// capacity. Defaults to 1, i.e. exclusive ownership.
+	Capacity int
+
+	// Mode allows shared handles to be held by many owners at the same
+	// time. Exclusive handles are queued while shared handles are held and
+	// block new shared handles, see SelectSlot. Defaults to exclusive.
+	Mode rpc.Mode
+}
+
+// GetCapacity returns the capacity, nil-safe.
+func (o *AcquireOptions) GetCapacity() int {
+	if o == nil || o.Capacity < 1 {
+		return 1
+	}
+	return o.Capacity
+}
+
+// GetMode returns the mode, nil-safe.
+func (o *AcquireOptions) GetMode() rpc.Mode {
+	if o == nil {
+		return rpc.Mode_EXCLUSIVE
+	}
+	return o.Mode
+}
+
+// UpdateOptions contains optional Renew and Done arguments.
+type UpdateOptions struct {
+	// ExpectedVersion fails the update with ErrVersionMismatch unless the
+	// current metadata version matches. Ignored when zero.
+	ExpectedVersion int64
+
+	// DeleteMeta lists metadata keys to delete before metadata is merged.
+	DeleteMeta []string
+
+	// ReplaceMeta replaces the stored metadata instead of merging it.
+	ReplaceMeta bool
+
+	// FencingToken fails the update with ErrStaleToken unless it matches the
+	// current fencing token. Ignored when zero.
+	FencingToken int64
+}
+
+// GetExpectedVersion returns the expected version, nil-safe.
+func (o *UpdateOptions) GetExpectedVersion() int64 {
+	if o == nil {
+		return 0
+	}
+	return o.ExpectedVersion
+}
+
+// GetDeleteMeta returns the metadata keys to delete, nil-safe.
+func (o *UpdateOptions) GetDeleteMeta() []string {
+	if o == nil {
+		return nil
+	}
+	return o.DeleteMeta
+}
+
+// GetReplaceMeta returns true if metadata should be replaced, nil-safe.
+func (o *UpdateOptions) GetReplaceMeta() bool {
+	return o != nil && o.ReplaceMeta
+}
+
+// GetFencingToken returns the fencing token, nil-safe.
+func (o *UpdateOptions) GetFencingToken() int64 {
+	if o == nil {
+		return 0
+	}
+	return o.FencingToken
+}
+
+// Middleware wraps a Backend to add cross-cutting behaviour.
+type Middleware func(Backend) Backend
+
+// Chain wraps the backend with middlewares. The first middleware is the
+// outermost, i.e. it is invoked first on every call.
+func Chain(b Backend, middlewares ...Middleware) Backend {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		b = middlewares[i](b)
+	}
+	return b
+}
+
 // --------------------------------------------------------------------
 
 // HandleData is retrieved by the backend.
 type HandleData struct {
-	ID          uuid.UUID         // a unique identifier
-	Namespace   string            // the namespace of the handle
-	Name        string            // the name of the handle
-	Owner       string            // last/current owner identifier
-	ExpTime     time.Time         // expiration time
-	DoneTime    time.Time         // done time
-	NumAcquired int               // number of times acquired
-	Metadata    map[string]string // custom metadata
+	ID            uuid.UUID         // a unique identifier
+	Namespace     string            // the namespace of the handle
+	Name          string            // the name of the handle
+	Owner         string            // last/current owner identifier
+	PreviousOwner string            // owner before the last takeover
+	CreatedTime   time.Time         // creation time
+	UpdatedTime   time.Time         // last update time
+	ExpTime       time.Time         // expiration time
+	DoneTime      time.Time         // done time
+	NumAcquired   int               // number of times acquired
+	Metadata      map[string]string // custom metadata
+	MetaVersion   int64             // metadata version
+	FencingToken  int64             // increases with every acquisition
+	Slot          int               // slot of the resource, zero for plain exclusive handles
+	Capacity      int               // maximum number of concurrent holders
+	Mode          rpc.Mode          // acquire mode
+	Queued        bool              // exclusive handle queued behind shared holders
 }
 
 // IsDone indicates when a resource is marked as done.
@@ -62,15 +188,223 @@ func (h *HandleData) IsDone() bool {
 	return h.DoneTime.After(zeroTime)
 }
 
-// UpdateMetadata merged metadata.
-func (h *HandleData) UpdateMetadata(meta map[string]string) {
+// IsResourceDone returns true if the resource has been marked as done. Done
+// semaphore slots and shared handles are merely released, they are not
+// included.
+func (h *HandleData) IsResourceDone() bool {
+	return h.IsDone() && h.Capacity <= 1 && h.Mode != rpc.Mode_SHARED
+}
+
+// IsHeld returns true if the handle is pending and has not expired.
+func (h *HandleData) IsHeld(now time.Time) bool {
+	return !h.IsDone() && !h.ExpTime.Before(now)
+}
+
+// IsReleased returns true if the slot of the handle can be re-acquired,
+// i.e. if the handle has expired or if it is a done semaphore slot or
+// shared handle.
+func (h *HandleData) IsReleased(now time.Time) bool {
+	if h.IsDone() {
+		return h.Capacity > 1 || h.Mode == rpc.Mode_SHARED
+	}
+	return h.ExpTime.Before(now)
+}
+
+// Cursor returns the list position of the handle.
+func (h *HandleData) Cursor() *Cursor {
+	return &Cursor{CreatedTime: h.CreatedTime, Namespace: h.Namespace, Name: h.Name, Slot: h.Slot}
+}
+
+// UpdateMetadata merged metadata. It returns true if the metadata has changed.
+func (h *HandleData) UpdateMetadata(meta map[string]string) bool {
 	if h.Metadata == nil {
 		h.Metadata = make(map[string]string, len(meta))
 	}
 
+	changed := false
 	for key, value := range meta {
-		h.Metadata[key] = value
+		if stored, ok := h.Metadata[key]; !ok || stored != value {
+			h.Metadata[key] = value
+			changed = true
+		}
+	}
+	return changed
+}
+
+// DeleteMetadata removes keys. It returns true if the metadata has changed.
+func (h *HandleData) DeleteMetadata(keys []string) bool {
+	changed := false
+	for _, key := range keys {
+		if _, ok := h.Metadata[key]; ok {
+			delete(h.Metadata, key)
+			changed = true
+		}
 	}
+	return changed
+}
+
+// ApplyUpdate checks the fencing token and the expected version, deletes, replaces or merges
+// metadata and increments the metadata version if the metadata has changed.
+func (h *HandleData) ApplyUpdate(meta map[string]string, opt *UpdateOptions) error {
+	if t := opt.GetFencingToken(); t != 0 && t != h.FencingToken {
+		return ErrStaleToken
+	}
+	if v := opt.GetExpectedVersion(); v != 0 && v != h.MetaVersion {
+		return ErrVersionMismatch
+	}
+
+	var changed bool
+	if opt.GetReplaceMeta() {
+		changed = !maps.Equal(h.Metadata, meta)
+		h.Metadata = maps.Clone(meta)
+	} else {
+		deleted := h.DeleteMetadata(opt.GetDeleteMeta())
+		changed = h.UpdateMetadata(meta) || deleted
+	}
+	if changed {
+		h.MetaVersion++
+	}
+	return nil
 }
 
 var zeroTime = time.Unix(0, 0)
+
+// ValidateFencingToken returns ErrStaleToken unless the handle is still
+// pending and token is its current fencing token. The handle may be nil.
+func ValidateFencingToken(handle *HandleData, token int64) error {
+	if handle == nil || handle.IsDone() || handle.FencingToken != token {
+		return ErrStaleToken
+	}
+	return nil
+}
+
+// --------------------------------------------------------------------
+
+// ListFilter is a parsed rpc.ListRequest_Filter.
+type ListFilter struct {
+	Status     rpc.ListRequest_Filter_Status // only certain status
+	Prefix     string                        // namespace prefix
+	Namespace  string                        // exact namespace
+	NamePrefix string                        // name prefix
+	Owner      string                        // exact owner
+	Metadata   map[string]string             // exact metadata matches
+	Selector   Selector                      // metadata selector
+
+	// Time ranges, bounds are exclusive and ignored when zero.
+	CreatedAfter, CreatedBefore time.Time
+	UpdatedAfter, UpdatedBefore time.Time
+	DoneAfter, DoneBefore       time.Time
+}
+
+// ParseListFilter parses a list request filter. It returns nil for a nil filter.
+func ParseListFilter(f *rpc.ListRequest_Filter) (*ListFilter, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	sel, err := ParseSelector(f.MetadataSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListFilter{
+		Status:     f.Status,
+		Prefix:     f.Prefix,
+		Namespace:  f.Namespace,
+		NamePrefix: f.NamePrefix,
+		Owner:      f.Owner,
+		Metadata:   f.Metadata,
+		Selector:   sel,
+
+		CreatedAfter:  f.CreatedAfter(),
+		CreatedBefore: f.CreatedBefore(),
+		UpdatedAfter:  f.UpdatedAfter(),
+		UpdatedBefore: f.UpdatedBefore(),
+		DoneAfter:     f.DoneAfter(),
+		DoneBefore:    f.DoneBefore(),
+	}, nil
+}
+
+// Matches returns true if the handle matches the filter.
+func (f *ListFilter) Matches(h *HandleData) bool {
+	if f == nil {
+		return true
+	}
+
+	if f.Status == rpc.ListRequest_Filter_DONE && !h.IsResourceDone() {
+		return false
+	} else if f.Status == rpc.ListRequest_Filter_PENDING && h.IsDone() {
+		return false
+	}
+
+	if f.Prefix != "" && !strings.HasPrefix(h.Namespace, f.Prefix) {
+		return false
+	}
+	if f.Namespace != "" && h.Namespace != f.Namespace {
+		return false
+	}
+	if f.NamePrefix != "" && !strings.HasPrefix(h.Name, f.NamePrefix) {
+		return false
+	}
+	if f.Owner != "" && h.Owner != f.Owner {
+		return false
+	}
+
+	for k, v := range f.Metadata {
+		if val, ok := h.Metadata[k]; !ok || val != v {
+			return false
+		}
+	}
+
+	if !inRange(h.CreatedTime, f.CreatedAfter, f.CreatedBefore) || !inRange(h.UpdatedTime, f.UpdatedAfter, f.UpdatedBefore) {
+		return false
+	}
+	if !f.DoneAfter.IsZero() || !f.DoneBefore.IsZero() {
+		if !h.IsDone() || !inRange(h.DoneTime, f.DoneAfter, f.DoneBefore) {
+			return false
+		}
+	}
+
+	return f.Selector.Matches(h.Metadata)
+}
+
+func inRange(t, after, before time.Time) bool {
+	return (after.IsZero() || t.After(after)) && (before.IsZero() || t.Before(before))
+}
+
+// --------------------------------------------------------------------
+
+// DoneRecord describes a resource to be marked as done.
+type DoneRecord struct {
+	Owner     string            // owner identifier
+	Namespace string            // the namespace of the resource
+	Name      string            // the name of the resource
+	DoneTime  time.Time         // done time, defaults to now
+	Metadata  map[string]string // custom metadata
+}
+
+// --------------------------------------------------------------------
+
+// PurgeFilter selects done handles for purging. Released semaphore slots and
+// shared handles are not purged.
+type PurgeFilter struct {
+	Prefix  string    // namespace prefix
+	Exclude []string  // namespace prefixes to exclude
+	Before  time.Time // only handles marked as done before this time
+}
+
+// Matches returns true if the handle matches the filter.
+func (f *PurgeFilter) Matches(h *HandleData) bool {
+	if !h.IsResourceDone() || !h.DoneTime.Before(f.Before) {
+		return false
+	}
+	if !strings.HasPrefix(h.Namespace, f.Prefix) {
+		return false
+	}
+	for _, prefix := range f.Exclude {
+		if strings.HasPrefix(h.Namespace, prefix) {
+			return false
+		}
+	}
+	return true
+}
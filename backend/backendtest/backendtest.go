@@ -0,0 +1,85 @@
+// Package backendtest contains a conformance test suite for backend.Backend
+// implementations. It can be used with plain testing.T as well as ginkgo:
+//
+//	func TestBackend(t *testing.T) {
+//		backendtest.Run(t, func(t *testing.T) backend.Backend {
+//			return mybackend.New()
+//		})
+//	}
+//
+// Each spec is run against a fresh, empty backend instance.
+package backendtest
+
+import (
+	"testing"
+
+	"github.com/bsm/accord/backend"
+	G "github.com/bsm/ginkgo/v2"
+	Ω "github.com/bsm/gomega"
+)
+
+// Spec is a single conformance spec.
+type Spec struct {
+	Name string
+	Run  func(g *Ω.WithT, subject backend.Backend)
+}
+
+// Specs returns all conformance specs.
+func Specs() []Spec {
+	specs := make([]Spec, 0, len(basicSpecs)+len(concurrencySpecs)+len(listSpecs)+len(purgeSpecs))
+	specs = append(specs, basicSpecs...)
+	specs = append(specs, concurrencySpecs...)
+	specs = append(specs, listSpecs...)
+	specs = append(specs, purgeSpecs...)
+	return specs
+}
+
+// Run runs all specs as sub-tests of t. The open function must return a
+// fresh, empty backend for every sub-test, the backend is closed
+// automatically after each sub-test.
+func Run(t *testing.T, open func(*testing.T) backend.Backend) {
+	t.Helper()
+
+	for _, spec := range Specs() {
+		spec := spec
+		t.Run(spec.Name, func(t *testing.T) {
+			subject := open(t)
+			defer func() {
+				if err := subject.Close(); err != nil {
+					t.Errorf("close failed with %v", err)
+				}
+			}()
+
+			spec.Run(Ω.NewWithT(t), subject)
+		})
+	}
+}
+
+// BehavesLikeBackendData contains a subject
+type BehavesLikeBackendData struct {
+	Subject backend.Backend
+}
+
+// BehavesLikeBackend is a ginkgo shared block, Subject must be assigned
+// a fresh, empty backend in a BeforeEach block. The subject is closed
+// automatically after each spec.
+func BehavesLikeBackend(data *BehavesLikeBackendData) func() {
+	return func() {
+		var subject backend.Backend
+
+		G.BeforeEach(func() {
+			subject = data.Subject
+		})
+
+		G.AfterEach(func() {
+			Ω.Expect(subject.Close()).To(Ω.Succeed())
+		})
+
+		for _, spec := range Specs() {
+			spec := spec
+			G.It(spec.Name, func() {
+				spec.Run(Ω.NewWithT(G.GinkgoT()), subject)
+			})
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package backendtest_test
+
+import (
+	"testing"
+
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/backend/backendtest"
+	"github.com/bsm/accord/backend/mock"
+)
+
+func TestRun(t *testing.T) {
+	backendtest.Run(t, func(*testing.T) backend.Backend {
+		return mock.New()
+	})
+}
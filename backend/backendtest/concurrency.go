@@ -0,0 +1,141 @@
+package backendtest
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	Ω "github.com/bsm/gomega"
+)
+
+const numRacers = 10
+
+var concurrencySpecs = []Spec{
+	{"should allow only one of many concurrent acquires", func(g *Ω.WithT, subject backend.Backend) {
+		exp := time.Now().Add(minute)
+		results := race(numRacers, func(i int) error {
+			_, err := subject.Acquire(ctx, "owner"+strconv.Itoa(i), namespace, name, exp, nil, nil)
+			return err
+		})
+
+		g.Expect(results[nil]).To(Ω.Equal(1))
+		g.Expect(results[accord.ErrAcquired]).To(Ω.Equal(numRacers - 1))
+	}},
+
+	{"should allow only one of many concurrent takeovers after expiry", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(subject.Renew(ctx, owner1, h.ID, now.Add(-time.Second), nil, nil)).To(Ω.Succeed())
+
+		var (
+			acquired *backend.HandleData
+			mu       sync.Mutex
+		)
+		results := race(numRacers, func(i int) error {
+			h, err := subject.Acquire(ctx, "owner"+strconv.Itoa(i), namespace, name, now.Add(minute), nil, nil)
+			if err == nil {
+				mu.Lock()
+				acquired = h
+				mu.Unlock()
+			}
+			return err
+		})
+
+		g.Expect(results[nil]).To(Ω.Equal(1))
+		g.Expect(results[accord.ErrAcquired]).To(Ω.Equal(numRacers - 1))
+		g.Expect(acquired.ID).NotTo(Ω.Equal(h.ID))
+		g.Expect(acquired.NumAcquired).To(Ω.Equal(2))
+
+		// the previous handle must be invalid
+		g.Expect(subject.Renew(ctx, owner1, h.ID, now.Add(minute), nil, nil)).To(Ω.Equal(backend.ErrInvalidHandle))
+	}},
+
+	{"should mark as done only once when racing", func(g *Ω.WithT, subject backend.Backend) {
+		h, err := subject.Acquire(ctx, owner1, namespace, name, time.Now().Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+
+		results := race(numRacers, func(i int) error {
+			return subject.Done(ctx, owner1, h.ID, map[string]string{"k": strconv.Itoa(i)}, nil)
+		})
+		g.Expect(results[nil]).To(Ω.Equal(1))
+		g.Expect(results[backend.ErrInvalidHandle]).To(Ω.Equal(numRacers - 1))
+	}},
+
+	{"should not renew after done when racing", func(g *Ω.WithT, subject backend.Backend) {
+		h, err := subject.Acquire(ctx, owner1, namespace, name, time.Now().Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+
+		var (
+			doneAt  time.Time
+			renewed []time.Time
+			mu      sync.Mutex
+		)
+		results := race(numRacers, func(i int) error {
+			if i == numRacers/2 {
+				err := subject.Done(ctx, owner1, h.ID, map[string]string{"done": "true"}, nil)
+				if err == nil {
+					mu.Lock()
+					doneAt = time.Now()
+					mu.Unlock()
+				}
+				return err
+			}
+
+			start := time.Now()
+			err := subject.Renew(ctx, owner1, h.ID, start.Add(2*minute), map[string]string{"renewed": "true"}, nil)
+			if err == nil {
+				mu.Lock()
+				renewed = append(renewed, start)
+				mu.Unlock()
+			}
+			return err
+		})
+
+		// done must succeed, renews either succeed or fail with invalid handle
+		g.Expect(doneAt).NotTo(Ω.BeZero())
+		g.Expect(results[nil] + results[backend.ErrInvalidHandle]).To(Ω.Equal(numRacers))
+
+		// no renew which started after done may succeed
+		for _, start := range renewed {
+			g.Expect(start).To(Ω.BeTemporally("<=", doneAt))
+		}
+
+		// handle must be done, renews must now fail
+		stored, err := subject.Get(ctx, h.ID)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(stored.IsDone()).To(Ω.BeTrue())
+		g.Expect(stored.Metadata).To(Ω.HaveKeyWithValue("done", "true"))
+		g.Expect(subject.Renew(ctx, owner1, h.ID, time.Now().Add(minute), nil, nil)).To(Ω.Equal(backend.ErrInvalidHandle))
+	}},
+}
+
+// race runs fn concurrently n times and counts the returned errors.
+func race(n int, fn func(int) error) map[error]int {
+	var (
+		results = make(map[error]int)
+		start   = make(chan struct{})
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+	)
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			<-start
+			err := fn(i)
+
+			mu.Lock()
+			results[err]++
+			mu.Unlock()
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,390 @@
+package backendtest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/rpc"
+	Ω "github.com/bsm/gomega"
+)
+
+var listSpecs = []Spec{
+	{"should list", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+
+		// Acquire 3 resources
+		h1, err := subject.Acquire(ctx, owner1, "a/b", "r1", now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		h2, err := subject.Acquire(ctx, owner1, "a/b/c", "r2", now.Add(minute), map[string]string{"a": "1"}, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		h3, err := subject.Acquire(ctx, owner1, "a/x", "r3", now.Add(minute), map[string]string{"a": "1", "b": "2"}, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+
+		// Mark 2+3 as done
+		_ = h1
+		g.Expect(subject.Done(ctx, owner1, h2.ID, nil, nil)).To(Ω.Succeed())
+		g.Expect(subject.Done(ctx, owner1, h3.ID, nil, nil)).To(Ω.Succeed())
+
+		// List all
+		g.Expect(listNames(subject, nil)).To(Ω.Equal([]string{"r3", "r2", "r1"}))
+
+		// List done
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Status: rpc.ListRequest_Filter_DONE}})).To(Ω.HaveLen(2))
+
+		// List pending
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Status: rpc.ListRequest_Filter_PENDING}})).To(Ω.HaveLen(1))
+
+		// With namespace
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Prefix: "a/b"}})).To(Ω.HaveLen(2))
+
+		// With metadata #1
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Metadata: map[string]string{"a": "1"}}})).To(Ω.HaveLen(2))
+
+		// With metadata #2
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Metadata: map[string]string{"b": "2"}}})).To(Ω.HaveLen(1))
+
+		// With exact namespace
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Namespace: "a/b"}})).To(Ω.Equal([]string{"r1"}))
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Namespace: "a"}})).To(Ω.BeEmpty())
+
+		// With name prefix
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{NamePrefix: "r"}})).To(Ω.HaveLen(3))
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{NamePrefix: "r2"}})).To(Ω.Equal([]string{"r2"}))
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{NamePrefix: "r_"}})).To(Ω.BeEmpty())
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Namespace: "a/x", NamePrefix: "r"}})).To(Ω.Equal([]string{"r3"}))
+
+		// With owner
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Owner: owner1}})).To(Ω.HaveLen(3))
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Owner: owner2}})).To(Ω.BeEmpty())
+
+		// No namespace match
+		g.Expect(listNames(subject, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Prefix: "a/x/y"}})).To(Ω.BeEmpty())
+
+		// Stop after first
+		var results []*backend.HandleData
+		g.Expect(subject.List(ctx, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Prefix: "a"}}, func(h *backend.HandleData) error {
+			results = append(results, h)
+			return backend.ErrIteratorDone
+		})).To(Ω.Succeed())
+		g.Expect(results).To(Ω.HaveLen(1))
+
+		// With offset
+		g.Expect(listNames(subject, &rpc.ListRequest{Offset: 2})).To(Ω.Equal([]string{"r1"}))
+	}},
+
+	{"should list with all filter combinations", func(g *Ω.WithT, subject backend.Backend) {
+		fixtures := []struct {
+			Namespace, Name string
+			Metadata        map[string]string
+			Done            bool
+		}{
+			{"a/b", "r1", nil, false},
+			{"a/b/c", "r2", map[string]string{"a": "1"}, true},
+			{"a/x", "r3", map[string]string{"a": "1", "b": "2"}, true},
+			{"b", "r4", map[string]string{"b": "2"}, false},
+			{"", "r5", map[string]string{"a": "2"}, true},
+		}
+		for _, f := range fixtures {
+			h, err := subject.Acquire(ctx, owner1, f.Namespace, f.Name, time.Now().Add(minute), f.Metadata, nil)
+			g.Expect(err).NotTo(Ω.HaveOccurred())
+			if f.Done {
+				g.Expect(subject.Done(ctx, owner1, h.ID, nil, nil)).To(Ω.Succeed())
+			}
+		}
+
+		statuses := []rpc.ListRequest_Filter_Status{
+			rpc.ListRequest_Filter_ALL,
+			rpc.ListRequest_Filter_DONE,
+			rpc.ListRequest_Filter_PENDING,
+		}
+		prefixes := []string{"", "a", "a/b", "b", "x"}
+		metadatas := []map[string]string{
+			nil,
+			{"a": "1"},
+			{"b": "2"},
+			{"a": "1", "b": "2"},
+			{"a": "2", "b": "2"},
+			{"c": "3"},
+		}
+		offsets := []uint64{0, 1, 10}
+		limits := []uint32{0, 2}
+
+		for _, status := range statuses {
+			for _, prefix := range prefixes {
+				for _, metadata := range metadatas {
+					for _, offset := range offsets {
+						for _, limit := range limits {
+							req := &rpc.ListRequest{
+								Filter: &rpc.ListRequest_Filter{
+									Status:   status,
+									Prefix:   prefix,
+									Metadata: metadata,
+								},
+								Offset: offset,
+								Limit:  limit,
+							}
+
+							// expected results, newest first
+							expected := []string{}
+							for i := len(fixtures) - 1; i >= 0; i-- {
+								f := fixtures[i]
+								if status == rpc.ListRequest_Filter_DONE && !f.Done {
+									continue
+								} else if status == rpc.ListRequest_Filter_PENDING && f.Done {
+									continue
+								} else if !strings.HasPrefix(f.Namespace, prefix) {
+									continue
+								} else if !containsAll(f.Metadata, metadata) {
+									continue
+								}
+								expected = append(expected, f.Name)
+							}
+							if offset < uint64(len(expected)) {
+								expected = expected[offset:]
+							} else {
+								expected = []string{}
+							}
+							if limit != 0 && int(limit) < len(expected) {
+								expected = expected[:limit]
+							}
+
+							g.Expect(listNames(subject, req)).To(Ω.Equal(expected), fmt.Sprintf("for %v", req))
+						}
+					}
+				}
+			}
+		}
+	}},
+
+	{"should paginate with cursors", func(g *Ω.WithT, subject backend.Backend) {
+		for i := 1; i <= 5; i++ {
+			_, err := subject.Acquire(ctx, owner1, "a", "r"+strconv.Itoa(i), time.Now().Add(minute), nil, nil)
+			g.Expect(err).NotTo(Ω.HaveOccurred())
+		}
+
+		// invalid cursor
+		g.Expect(subject.List(ctx, &rpc.ListRequest{Cursor: "invalid"}, func(*backend.HandleData) error {
+			return nil
+		})).To(Ω.Equal(backend.ErrInvalidCursor))
+
+		// page through, inserting new handles along the way
+		var names []string
+		req := &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Prefix: "a"}, Limit: 2}
+		for page := 0; ; page++ {
+			var last *backend.HandleData
+			g.Expect(subject.List(ctx, req, func(h *backend.HandleData) error {
+				names = append(names, h.Name)
+				last = h
+				return nil
+			})).To(Ω.Succeed())
+			if last == nil {
+				break
+			}
+			g.Expect(page).To(Ω.BeNumerically("<", 5))
+
+			_, err := subject.Acquire(ctx, owner1, "a", "n"+strconv.Itoa(page), time.Now().Add(minute), nil, nil)
+			g.Expect(err).NotTo(Ω.HaveOccurred())
+
+			req.Cursor = last.Cursor().String()
+		}
+		g.Expect(names).To(Ω.Equal([]string{"r5", "r4", "r3", "r2", "r1"}))
+
+		// cursors are stable across takeovers
+		h, err := subject.Acquire(ctx, owner1, "b", "x", time.Now().Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(subject.Renew(ctx, owner1, h.ID, time.Now().Add(-time.Second), nil, nil)).To(Ω.Succeed())
+		_, err = subject.Acquire(ctx, owner2, "b", "x", time.Now().Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+
+		req = &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Prefix: "a"}, Cursor: h.Cursor().String()}
+		g.Expect(listNames(subject, req)).To(Ω.HaveLen(8))
+
+		// takeovers between pages neither skip nor repeat handles
+		handles := make(map[string]*backend.HandleData)
+		for _, name := range []string{"t1", "t2", "t3", "t4"} {
+			handles[name], err = subject.Acquire(ctx, owner1, "c", name, time.Now().Add(minute), nil, nil)
+			g.Expect(err).NotTo(Ω.HaveOccurred())
+		}
+
+		names = names[:0]
+		req = &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Prefix: "c"}, Limit: 2}
+		for page := 0; ; page++ {
+			var last *backend.HandleData
+			g.Expect(subject.List(ctx, req, func(h *backend.HandleData) error {
+				names = append(names, h.Name)
+				last = h
+				return nil
+			})).To(Ω.Succeed())
+			if last == nil {
+				break
+			}
+			g.Expect(page).To(Ω.BeNumerically("<", 3))
+
+			// take over all handles
+			for name, h := range handles {
+				g.Expect(subject.Renew(ctx, h.Owner, h.ID, time.Now().Add(-time.Second), nil, nil)).To(Ω.Succeed())
+				handles[name], err = subject.Acquire(ctx, owner2, "c", name, time.Now().Add(minute), nil, nil)
+				g.Expect(err).NotTo(Ω.HaveOccurred())
+			}
+			req.Cursor = last.Cursor().String()
+		}
+		g.Expect(names).To(Ω.Equal([]string{"t4", "t3", "t2", "t1"}))
+	}},
+
+	{"should sort and filter by time", func(g *Ω.WithT, subject backend.Backend) {
+		pause := func() time.Time {
+			time.Sleep(10 * time.Millisecond)
+			now := time.Now()
+			time.Sleep(10 * time.Millisecond)
+			return now
+		}
+
+		hb, err := subject.Acquire(ctx, owner1, "a", "b", time.Now().Add(3*minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		pause()
+		hc, err := subject.Acquire(ctx, owner1, "a", "c", time.Now().Add(1*minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		created := pause()
+		ha, err := subject.Acquire(ctx, owner1, "a", "a", time.Now().Add(2*minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		pause()
+		g.Expect(subject.Done(ctx, owner1, ha.ID, nil, nil)).To(Ω.Succeed())
+		updated := pause()
+		g.Expect(subject.Renew(ctx, owner1, hb.ID, time.Now().Add(4*minute), nil, nil)).To(Ω.Succeed())
+		pause()
+		g.Expect(subject.Done(ctx, owner1, hc.ID, nil, nil)).To(Ω.Succeed())
+
+		for _, tc := range []struct {
+			sort     rpc.ListRequest_SortKey
+			order    rpc.ListRequest_SortOrder
+			expected []string
+		}{
+			{rpc.ListRequest_CREATED, rpc.ListRequest_DESC, []string{"a", "c", "b"}},
+			{rpc.ListRequest_CREATED, rpc.ListRequest_ASC, []string{"b", "c", "a"}},
+			{rpc.ListRequest_UPDATED, rpc.ListRequest_DESC, []string{"c", "b", "a"}},
+			{rpc.ListRequest_UPDATED, rpc.ListRequest_ASC, []string{"a", "b", "c"}},
+			{rpc.ListRequest_DONE, rpc.ListRequest_DESC, []string{"c", "a", "b"}},
+			{rpc.ListRequest_DONE, rpc.ListRequest_ASC, []string{"a", "c", "b"}},
+			{rpc.ListRequest_EXPIRES, rpc.ListRequest_DESC, []string{"b", "a", "c"}},
+			{rpc.ListRequest_EXPIRES, rpc.ListRequest_ASC, []string{"c", "a", "b"}},
+			{rpc.ListRequest_NAME, rpc.ListRequest_DESC, []string{"c", "b", "a"}},
+			{rpc.ListRequest_NAME, rpc.ListRequest_ASC, []string{"a", "b", "c"}},
+		} {
+			req := &rpc.ListRequest{Sort: tc.sort, Order: tc.order}
+			g.Expect(listNames(subject, req)).To(Ω.Equal(tc.expected), "for %v", req)
+
+			req.Offset, req.Limit = 1, 1
+			g.Expect(listNames(subject, req)).To(Ω.Equal(tc.expected[1:2]), "for %v", req)
+		}
+
+		for _, tc := range []struct {
+			filter   *rpc.ListRequest_Filter
+			expected []string
+		}{
+			{&rpc.ListRequest_Filter{CreatedBeforeTms: created.UnixMilli()}, []string{"c", "b"}},
+			{&rpc.ListRequest_Filter{CreatedAfterTms: created.UnixMilli()}, []string{"a"}},
+			{&rpc.ListRequest_Filter{UpdatedBeforeTms: updated.UnixMilli()}, []string{"a"}},
+			{&rpc.ListRequest_Filter{UpdatedAfterTms: updated.UnixMilli()}, []string{"c", "b"}},
+			{&rpc.ListRequest_Filter{DoneBeforeTms: updated.UnixMilli()}, []string{"a"}},
+			{&rpc.ListRequest_Filter{DoneAfterTms: updated.UnixMilli()}, []string{"c"}},
+			{&rpc.ListRequest_Filter{DoneAfterTms: created.UnixMilli()}, []string{"a", "c"}},
+			{&rpc.ListRequest_Filter{CreatedAfterTms: created.UnixMilli(), CreatedBeforeTms: updated.UnixMilli()}, []string{"a"}},
+			{&rpc.ListRequest_Filter{CreatedAfterTms: updated.UnixMilli()}, []string{}},
+		} {
+			req := &rpc.ListRequest{Filter: tc.filter}
+			g.Expect(listNames(subject, req)).To(Ω.Equal(tc.expected), "for %v", req)
+		}
+
+		// cursors require the default sort
+		g.Expect(subject.List(ctx, &rpc.ListRequest{
+			Cursor: ha.Cursor().String(),
+			Sort:   rpc.ListRequest_NAME,
+		}, func(*backend.HandleData) error {
+			return nil
+		})).To(Ω.Equal(backend.ErrInvalidCursor))
+	}},
+
+	{"should count", func(g *Ω.WithT, subject backend.Backend) {
+		for i := 1; i <= 3; i++ {
+			_, err := subject.Acquire(ctx, owner1, "a", "r"+strconv.Itoa(i), time.Now().Add(minute), nil, nil)
+			g.Expect(err).NotTo(Ω.HaveOccurred())
+		}
+		h, err := subject.Acquire(ctx, owner2, "b", "r4", time.Now().Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(subject.Done(ctx, owner2, h.ID, nil, nil)).To(Ω.Succeed())
+
+		g.Expect(subject.Count(ctx, nil)).To(Ω.Equal(int64(4)))
+		g.Expect(subject.Count(ctx, &rpc.ListRequest_Filter{Namespace: "a"})).To(Ω.Equal(int64(3)))
+		g.Expect(subject.Count(ctx, &rpc.ListRequest_Filter{Owner: owner2})).To(Ω.Equal(int64(1)))
+		g.Expect(subject.Count(ctx, &rpc.ListRequest_Filter{Status: rpc.ListRequest_Filter_DONE})).To(Ω.Equal(int64(1)))
+		g.Expect(subject.Count(ctx, &rpc.ListRequest_Filter{Status: rpc.ListRequest_Filter_PENDING, Owner: owner2})).To(Ω.Equal(int64(0)))
+
+		_, err = subject.Count(ctx, &rpc.ListRequest_Filter{MetadataSelector: "env in prod"})
+		g.Expect(err).To(Ω.Equal(backend.ErrInvalidSelector))
+	}},
+
+	{"should filter by metadata selector", func(g *Ω.WithT, subject backend.Backend) {
+		for name, meta := range map[string]map[string]string{
+			"r1": nil,
+			"r2": {"env": "prod", "path": "tmp/a_b"},
+			"r3": {"env": "staging", "path": "tmp%/c", "legacy": "1"},
+			"r4": {"env": "dev", "path": "var/d"},
+		} {
+			_, err := subject.Acquire(ctx, owner1, "a", name, time.Now().Add(minute), meta, nil)
+			g.Expect(err).NotTo(Ω.HaveOccurred())
+		}
+
+		for selector, expected := range map[string][]string{
+			"":                              {"r1", "r2", "r3", "r4"},
+			"env":                           {"r2", "r3", "r4"},
+			"!env":                          {"r1"},
+			"env=prod":                      {"r2"},
+			"env==prod":                     {"r2"},
+			"env!=prod":                     {"r1", "r3", "r4"},
+			"env in (prod, staging)":        {"r2", "r3"},
+			"env notin (prod,staging)":      {"r1", "r4"},
+			"path^=tmp/":                    {"r2"},
+			"path^=tmp%":                    {"r3"},
+			"path^=tmp/a_":                  {"r2"},
+			"env in (prod,staging),!legacy": {"r2"},
+			"env,legacy,path^=tmp":          {"r3"},
+			"env=unknown":                   {},
+		} {
+			req := &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{MetadataSelector: selector}}
+			names := listNames(subject, req)
+			sort.Strings(names)
+			g.Expect(names).To(Ω.Equal(expected), "for %q", selector)
+		}
+
+		// invalid selector
+		g.Expect(subject.List(ctx, &rpc.ListRequest{
+			Filter: &rpc.ListRequest_Filter{MetadataSelector: "env in prod"},
+		}, func(*backend.HandleData) error {
+			return nil
+		})).To(Ω.Equal(backend.ErrInvalidSelector))
+	}},
+}
+
+func listNames(subject backend.Backend, req *rpc.ListRequest) []string {
+	names := []string{}
+	if err := subject.List(ctx, req, func(h *backend.HandleData) error {
+		names = append(names, h.Name)
+		return nil
+	}); err != nil {
+		return []string{"ERROR: " + err.Error()}
+	}
+	return names
+}
+
+func containsAll(kv, subset map[string]string) bool {
+	for k, v := range subset {
+		if val, ok := kv[k]; !ok || val != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package backendtest
+
+import (
+	"time"
+
+	"github.com/bsm/accord/backend"
+	Ω "github.com/bsm/gomega"
+)
+
+var purgeSpecs = []Spec{
+	{"should purge done handles", func(g *Ω.WithT, subject backend.Backend) {
+		fixtures := []struct {
+			Namespace, Name string
+			Done            bool
+		}{
+			{"a/b", "r1", true},
+			{"a/b", "r2", false},
+			{"a/c", "r3", true},
+			{"a/x", "r4", true},
+			{"b", "r5", true},
+		}
+
+		handles := make([]*backend.HandleData, 0, len(fixtures))
+		for _, f := range fixtures {
+			h, err := subject.Acquire(ctx, owner1, f.Namespace, f.Name, time.Now().Add(minute), nil, nil)
+			g.Expect(err).NotTo(Ω.HaveOccurred())
+			if f.Done {
+				g.Expect(subject.Done(ctx, owner1, h.ID, nil, nil)).To(Ω.Succeed())
+			}
+			handles = append(handles, h)
+		}
+
+		// nothing done before cutoff
+		g.Expect(subject.Purge(ctx, &backend.PurgeFilter{Prefix: "a", Before: time.Now().Add(-minute)})).To(Ω.BeZero())
+		g.Expect(listNames(subject, nil)).To(Ω.HaveLen(5))
+
+		// purge with prefix and exclusions
+		g.Expect(subject.Purge(ctx, &backend.PurgeFilter{
+			Prefix:  "a",
+			Exclude: []string{"a/x"},
+			Before:  time.Now().Add(minute),
+		})).To(Ω.Equal(int64(2)))
+		g.Expect(listNames(subject, nil)).To(Ω.Equal([]string{"r5", "r4", "r2"}))
+
+		stored, err := subject.Get(ctx, handles[0].ID)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(stored).To(Ω.BeNil())
+
+		// purged resources can be acquired again
+		h, err := subject.Acquire(ctx, owner2, "a/b", "r1", time.Now().Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h.NumAcquired).To(Ω.Equal(1))
+
+		// purge everything
+		g.Expect(subject.Purge(ctx, &backend.PurgeFilter{Before: time.Now().Add(minute)})).To(Ω.Equal(int64(2)))
+		g.Expect(listNames(subject, nil)).To(Ω.Equal([]string{"r1", "r2"}))
+	}},
+	{"should match purge prefixes literally", func(g *Ω.WithT, subject backend.Backend) {
+		for _, f := range []struct{ Namespace, Name string }{
+			{"a_b", "r1"},
+			{"axb", "r2"},
+			{"a%c", "r3"},
+			{"abc", "r4"},
+		} {
+			h, err := subject.Acquire(ctx, owner1, f.Namespace, f.Name, time.Now().Add(minute), nil, nil)
+			g.Expect(err).NotTo(Ω.HaveOccurred())
+			g.Expect(subject.Done(ctx, owner1, h.ID, nil, nil)).To(Ω.Succeed())
+		}
+
+		// underscores are not wildcards
+		g.Expect(subject.Purge(ctx, &backend.PurgeFilter{Prefix: "a_", Before: time.Now().Add(minute)})).To(Ω.Equal(int64(1)))
+		g.Expect(listNames(subject, nil)).To(Ω.Equal([]string{"r4", "r3", "r2"}))
+
+		// neither are percent signs
+		g.Expect(subject.Purge(ctx, &backend.PurgeFilter{
+			Prefix:  "a",
+			Exclude: []string{"a%"},
+			Before:  time.Now().Add(minute),
+		})).To(Ω.Equal(int64(2)))
+		g.Expect(listNames(subject, nil)).To(Ω.Equal([]string{"r3"}))
+	}},
+}
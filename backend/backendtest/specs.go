@@ -0,0 +1,415 @@
+package backendtest
+
+import (
+	"context"
+	"time"
+
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/rpc"
+	Ω "github.com/bsm/gomega"
+	"github.com/google/uuid"
+)
+
+const (
+	owner1    = "THEOWNER"
+	owner2    = "OTHERONE"
+	owner3    = "THIRDONE"
+	namespace = "name:space"
+	name      = "my.resource"
+	minute    = time.Minute
+)
+
+var ctx = context.Background()
+
+var basicSpecs = []Spec{
+	{"should acquire", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), map[string]string{"k": "v"}, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h.ID.String()).To(Ω.HaveLen(36))
+		g.Expect(h.Namespace).To(Ω.Equal(namespace))
+		g.Expect(h.Name).To(Ω.Equal(name))
+		g.Expect(h.Owner).To(Ω.Equal(owner1))
+		g.Expect(h.PreviousOwner).To(Ω.BeEmpty())
+		g.Expect(h.CreatedTime).To(Ω.BeTemporally("~", now, time.Second))
+		g.Expect(h.UpdatedTime).To(Ω.BeTemporally("~", now, time.Second))
+		g.Expect(h.ExpTime).To(Ω.BeTemporally("~", now.Add(minute), time.Second))
+		g.Expect(h.DoneTime).To(Ω.BeZero())
+		g.Expect(h.NumAcquired).To(Ω.Equal(1))
+		g.Expect(h.Metadata).To(Ω.Equal(map[string]string{"k": "v"}))
+	}},
+
+	{"should acquire (once)", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+
+		// try to acquire 2x
+		_, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		_, err = subject.Acquire(ctx, owner1, namespace, name, now.Add(2*minute), nil, nil)
+		g.Expect(err).To(Ω.Equal(accord.ErrAcquired))
+
+		// try to acquire as someone else
+		_, err = subject.Acquire(ctx, owner2, namespace, name, now.Add(2*minute), nil, nil)
+		g.Expect(err).To(Ω.Equal(accord.ErrAcquired))
+	}},
+
+	{"should not allow acquire when done", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(subject.Done(ctx, owner1, h.ID, nil, nil)).To(Ω.Succeed())
+
+		_, err = subject.Acquire(ctx, owner1, namespace, name, now.Add(2*minute), nil, nil)
+		g.Expect(err).To(Ω.Equal(accord.ErrDone))
+	}},
+
+	{"should allow (re-)acquire when expired", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h1, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), map[string]string{"k": "v"}, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(subject.Renew(ctx, owner1, h1.ID, now.Add(-time.Second), map[string]string{"l": "w"}, nil)).To(Ω.Succeed())
+
+		h2, err := subject.Acquire(ctx, owner2, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h2.ID).NotTo(Ω.Equal(h1.ID))
+		g.Expect(h2.Owner).To(Ω.Equal(owner2))
+		g.Expect(h2.PreviousOwner).To(Ω.Equal(owner1))
+		g.Expect(h2.NumAcquired).To(Ω.Equal(2))
+		g.Expect(h2.CreatedTime).To(Ω.Equal(h1.CreatedTime))
+		g.Expect(h2.Metadata).To(Ω.Equal(map[string]string{"k": "v", "l": "w"}))
+	}},
+
+	{"should allow to renew", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h1, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), map[string]string{"k": "v"}, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(subject.Renew(ctx, owner1, h1.ID, now.Add(2*minute), map[string]string{"l": "w"}, nil)).To(Ω.Succeed())
+
+		h2, err := subject.Get(ctx, h1.ID)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h2.ExpTime).To(Ω.BeTemporally("~", now.Add(2*minute), time.Second))
+		g.Expect(h2.Metadata).To(Ω.Equal(map[string]string{"k": "v", "l": "w"}))
+	}},
+
+	{"should not allow renew when done", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(subject.Done(ctx, owner1, h.ID, nil, nil)).To(Ω.Succeed())
+		g.Expect(subject.Renew(ctx, owner1, h.ID, now.Add(2*minute), nil, nil)).To(Ω.Equal(backend.ErrInvalidHandle))
+	}},
+
+	{"should not allow renew when owned by someone else", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+
+		// try to acquire from a 2nd process
+		g.Expect(subject.Renew(ctx, owner2, h.ID, now.Add(2*minute), nil, nil)).To(Ω.Equal(backend.ErrInvalidHandle))
+	}},
+
+	{"should mark as done (once)", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h1, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), map[string]string{"k": "v"}, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(subject.Done(ctx, owner1, h1.ID, map[string]string{"l": "w"}, nil)).To(Ω.Succeed())
+		g.Expect(subject.Done(ctx, owner1, h1.ID, map[string]string{"m": "x"}, nil)).To(Ω.Equal(backend.ErrInvalidHandle))
+
+		_, err = subject.Acquire(ctx, owner1, namespace, name, now.Add(2*minute), nil, nil)
+		g.Expect(err).To(Ω.Equal(accord.ErrDone))
+
+		h2, err := subject.Get(ctx, h1.ID)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h2.IsDone()).To(Ω.BeTrue())
+		g.Expect(h2.Metadata).To(Ω.Equal(map[string]string{"k": "v", "l": "w"}))
+	}},
+
+	{"should version metadata", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), map[string]string{"k": "v"}, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h.MetaVersion).To(Ω.Equal(int64(1)))
+
+		version := func() int64 {
+			stored, err := subject.Get(ctx, h.ID)
+			g.Expect(err).NotTo(Ω.HaveOccurred())
+			return stored.MetaVersion
+		}
+
+		// unchanged metadata does not increment the version
+		g.Expect(subject.Renew(ctx, owner1, h.ID, now.Add(minute), map[string]string{"k": "v"}, nil)).To(Ω.Succeed())
+		g.Expect(version()).To(Ω.Equal(int64(1)))
+
+		g.Expect(subject.Renew(ctx, owner1, h.ID, now.Add(minute), map[string]string{"k": "w"}, &backend.UpdateOptions{ExpectedVersion: 1})).To(Ω.Succeed())
+		g.Expect(version()).To(Ω.Equal(int64(2)))
+
+		// stale versions are rejected
+		g.Expect(subject.Renew(ctx, owner1, h.ID, now.Add(minute), map[string]string{"k": "x"}, &backend.UpdateOptions{ExpectedVersion: 1})).To(Ω.Equal(backend.ErrVersionMismatch))
+		g.Expect(subject.Done(ctx, owner1, h.ID, map[string]string{"k": "x"}, &backend.UpdateOptions{ExpectedVersion: 1})).To(Ω.Equal(backend.ErrVersionMismatch))
+		g.Expect(subject.Renew(ctx, owner2, h.ID, now.Add(minute), nil, &backend.UpdateOptions{ExpectedVersion: 2})).To(Ω.Equal(backend.ErrInvalidHandle))
+
+		g.Expect(subject.Done(ctx, owner1, h.ID, map[string]string{"l": "y"}, &backend.UpdateOptions{ExpectedVersion: 2})).To(Ω.Succeed())
+		g.Expect(version()).To(Ω.Equal(int64(3)))
+		g.Expect(subject.Done(ctx, owner1, h.ID, nil, &backend.UpdateOptions{ExpectedVersion: 3})).To(Ω.Equal(backend.ErrInvalidHandle))
+	}},
+
+	{"should delete and replace metadata", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), map[string]string{"a": "1", "b": "2", "c": "3"}, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+
+		stored := func() *backend.HandleData {
+			stored, err := subject.Get(ctx, h.ID)
+			g.Expect(err).NotTo(Ω.HaveOccurred())
+			return stored
+		}
+
+		// deleting missing keys does not increment the version
+		g.Expect(subject.Renew(ctx, owner1, h.ID, now.Add(minute), nil, &backend.UpdateOptions{DeleteMeta: []string{"x"}})).To(Ω.Succeed())
+		g.Expect(stored().MetaVersion).To(Ω.Equal(int64(1)))
+
+		g.Expect(subject.Renew(ctx, owner1, h.ID, now.Add(minute), map[string]string{"c": "4", "d": "5"}, &backend.UpdateOptions{DeleteMeta: []string{"a", "c", "x"}})).To(Ω.Succeed())
+		g.Expect(stored().Metadata).To(Ω.Equal(map[string]string{"b": "2", "c": "4", "d": "5"}))
+		g.Expect(stored().MetaVersion).To(Ω.Equal(int64(2)))
+
+		g.Expect(subject.Renew(ctx, owner1, h.ID, now.Add(minute), map[string]string{"e": "6"}, &backend.UpdateOptions{ReplaceMeta: true})).To(Ω.Succeed())
+		g.Expect(stored().Metadata).To(Ω.Equal(map[string]string{"e": "6"}))
+		g.Expect(stored().MetaVersion).To(Ω.Equal(int64(3)))
+
+		g.Expect(subject.Done(ctx, owner1, h.ID, map[string]string{"f": "7"}, &backend.UpdateOptions{DeleteMeta: []string{"e"}})).To(Ω.Succeed())
+		g.Expect(stored().Metadata).To(Ω.Equal(map[string]string{"f": "7"}))
+		g.Expect(stored().MetaVersion).To(Ω.Equal(int64(4)))
+	}},
+
+	{"should issue fencing tokens", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h1, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h1.FencingToken).To(Ω.BeNumerically(">", 0))
+		g.Expect(subject.ValidateToken(ctx, h1.ID, h1.FencingToken)).To(Ω.Succeed())
+		g.Expect(subject.ValidateToken(ctx, h1.ID, h1.FencingToken+1)).To(Ω.Equal(backend.ErrStaleToken))
+
+		g.Expect(subject.Renew(ctx, owner1, h1.ID, now.Add(minute), nil, &backend.UpdateOptions{FencingToken: h1.FencingToken + 1})).To(Ω.Equal(backend.ErrStaleToken))
+		g.Expect(subject.Renew(ctx, owner1, h1.ID, now.Add(-time.Second), nil, &backend.UpdateOptions{FencingToken: h1.FencingToken})).To(Ω.Succeed())
+
+		h2, err := subject.Acquire(ctx, owner2, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h2.FencingToken).To(Ω.BeNumerically(">", h1.FencingToken))
+		g.Expect(subject.ValidateToken(ctx, h1.ID, h1.FencingToken)).To(Ω.Equal(backend.ErrStaleToken))
+		g.Expect(subject.ValidateToken(ctx, h2.ID, h2.FencingToken)).To(Ω.Succeed())
+
+		g.Expect(subject.Done(ctx, owner2, h2.ID, nil, &backend.UpdateOptions{FencingToken: h1.FencingToken})).To(Ω.Equal(backend.ErrStaleToken))
+		g.Expect(subject.Done(ctx, owner2, h2.ID, nil, &backend.UpdateOptions{FencingToken: h2.FencingToken})).To(Ω.Succeed())
+		g.Expect(subject.ValidateToken(ctx, h2.ID, h2.FencingToken)).To(Ω.Equal(backend.ErrStaleToken))
+
+		// tokens keep increasing after done handles have been purged
+		g.Expect(subject.Purge(ctx, &backend.PurgeFilter{Prefix: namespace, Before: now.Add(minute)})).To(Ω.Equal(int64(1)))
+		h3, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h3.FencingToken).To(Ω.BeNumerically(">", h2.FencingToken))
+	}},
+
+	{"should transfer", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h1, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), map[string]string{"k": "v"}, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+
+		_, err = subject.Transfer(ctx, owner2, h1.ID, owner1, false)
+		g.Expect(err).To(Ω.Equal(backend.ErrInvalidHandle))
+
+		h2, err := subject.Transfer(ctx, owner1, h1.ID, owner2, false)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h2.ID).To(Ω.Equal(h1.ID))
+		g.Expect(h2.Owner).To(Ω.Equal(owner2))
+		g.Expect(h2.PreviousOwner).To(Ω.Equal(owner1))
+		g.Expect(h2.NumAcquired).To(Ω.Equal(1))
+		g.Expect(h2.ExpTime).To(Ω.BeTemporally("~", now.Add(minute), time.Second))
+		g.Expect(h2.Metadata).To(Ω.Equal(map[string]string{"k": "v"}))
+		g.Expect(h2.FencingToken).To(Ω.BeNumerically(">", h1.FencingToken))
+
+		g.Expect(subject.Renew(ctx, owner1, h1.ID, now.Add(minute), nil, nil)).To(Ω.Equal(backend.ErrInvalidHandle))
+		g.Expect(subject.Renew(ctx, owner2, h2.ID, now.Add(minute), nil, nil)).To(Ω.Succeed())
+
+		h3, err := subject.Transfer(ctx, owner2, h2.ID, owner1, true)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h3.ID).NotTo(Ω.Equal(h2.ID))
+		g.Expect(h3.Owner).To(Ω.Equal(owner1))
+		g.Expect(h3.FencingToken).To(Ω.BeNumerically(">", h2.FencingToken))
+		g.Expect(subject.Get(ctx, h2.ID)).To(Ω.BeNil())
+
+		g.Expect(subject.Done(ctx, owner1, h3.ID, nil, nil)).To(Ω.Succeed())
+		_, err = subject.Transfer(ctx, owner1, h3.ID, owner2, false)
+		g.Expect(err).To(Ω.Equal(backend.ErrInvalidHandle))
+
+		// expired handles cannot be transferred
+		h4, err := subject.Acquire(ctx, owner1, namespace, "other", now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(subject.Renew(ctx, owner1, h4.ID, now.Add(-time.Second), nil, nil)).To(Ω.Succeed())
+		_, err = subject.Transfer(ctx, owner1, h4.ID, owner2, false)
+		g.Expect(err).To(Ω.Equal(backend.ErrInvalidHandle))
+	}},
+
+	{"should mark done in bulk", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		_, err := subject.Acquire(ctx, owner1, namespace, "held", now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		h, err := subject.Acquire(ctx, owner1, namespace, "finished", now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(subject.Done(ctx, owner1, h.ID, nil, nil)).To(Ω.Succeed())
+
+		errs, err := subject.MarkDone(ctx, []backend.DoneRecord{
+			{Owner: owner2, Namespace: namespace, Name: "new1", DoneTime: now.Add(-time.Hour), Metadata: map[string]string{"k": "v"}},
+			{Owner: owner2, Namespace: namespace, Name: "held"},
+			{Owner: owner2, Namespace: namespace, Name: "finished"},
+			{Owner: owner2, Namespace: namespace, Name: "new2"},
+			{Owner: owner2, Namespace: namespace, Name: "new1"},
+		})
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(errs).To(Ω.Equal([]error{nil, accord.ErrAcquired, accord.ErrDone, nil, accord.ErrDone}))
+
+		_, err = subject.Acquire(ctx, owner1, namespace, "new1", now.Add(minute), nil, nil)
+		g.Expect(err).To(Ω.Equal(accord.ErrDone))
+
+		var marked []*backend.HandleData
+		g.Expect(subject.List(ctx, &rpc.ListRequest{
+			Filter: &rpc.ListRequest_Filter{NamePrefix: "new", Status: rpc.ListRequest_Filter_DONE},
+			Sort:   rpc.ListRequest_NAME,
+			Order:  rpc.ListRequest_ASC,
+		}, func(h *backend.HandleData) error {
+			marked = append(marked, h)
+			return nil
+		})).To(Ω.Succeed())
+		g.Expect(marked).To(Ω.HaveLen(2))
+		g.Expect(marked[0].Name).To(Ω.Equal("new1"))
+		g.Expect(marked[0].Owner).To(Ω.Equal(owner2))
+		g.Expect(marked[0].DoneTime).To(Ω.BeTemporally("~", now.Add(-time.Hour), time.Second))
+		g.Expect(marked[0].Metadata).To(Ω.Equal(map[string]string{"k": "v"}))
+		g.Expect(marked[1].Name).To(Ω.Equal("new2"))
+		g.Expect(marked[1].DoneTime).To(Ω.BeTemporally("~", now, time.Second))
+
+		g.Expect(subject.Get(ctx, marked[0].ID)).To(Ω.Equal(marked[0]))
+	}},
+
+	{"should acquire semaphores", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		opt := &backend.AcquireOptions{Capacity: 2}
+		h1, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, opt)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h1.Slot).To(Ω.Equal(0))
+		g.Expect(h1.Capacity).To(Ω.Equal(2))
+
+		h2, err := subject.Acquire(ctx, owner2, namespace, name, now.Add(minute), nil, opt)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h2.ID).NotTo(Ω.Equal(h1.ID))
+		g.Expect(h2.Slot).To(Ω.Equal(1))
+
+		_, err = subject.Acquire(ctx, owner2, namespace, name, now.Add(minute), nil, opt)
+		g.Expect(err).To(Ω.Equal(accord.ErrAcquired))
+		_, err = subject.Acquire(ctx, owner2, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).To(Ω.Equal(accord.ErrAcquired))
+
+		var holders []string
+		g.Expect(subject.List(ctx, &rpc.ListRequest{
+			Filter: &rpc.ListRequest_Filter{Namespace: namespace, Status: rpc.ListRequest_Filter_PENDING},
+		}, func(h *backend.HandleData) error {
+			holders = append(holders, h.Owner)
+			return nil
+		})).To(Ω.Succeed())
+		g.Expect(holders).To(Ω.ConsistOf(owner1, owner2))
+
+		// done releases the slot, rather than marking the resource as done
+		g.Expect(subject.Done(ctx, owner1, h1.ID, nil, nil)).To(Ω.Succeed())
+		g.Expect(subject.Count(ctx, &rpc.ListRequest_Filter{Status: rpc.ListRequest_Filter_DONE})).To(Ω.Equal(int64(0)))
+		g.Expect(subject.Purge(ctx, &backend.PurgeFilter{Prefix: namespace, Before: now.Add(minute)})).To(Ω.Equal(int64(0)))
+
+		h3, err := subject.Acquire(ctx, owner2, namespace, name, now.Add(minute), nil, opt)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h3.Slot).To(Ω.Equal(0))
+		g.Expect(h3.PreviousOwner).To(Ω.Equal(owner1))
+		g.Expect(h3.NumAcquired).To(Ω.Equal(2))
+		g.Expect(h3.IsDone()).To(Ω.BeFalse())
+
+		// expired slots can be taken over
+		g.Expect(subject.Renew(ctx, owner2, h2.ID, now.Add(-time.Second), nil, nil)).To(Ω.Succeed())
+		h4, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, opt)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h4.Slot).To(Ω.Equal(1))
+		g.Expect(h4.FencingToken).To(Ω.BeNumerically(">", h2.FencingToken))
+
+		g.Expect(subject.Get(ctx, h4.ID)).To(Ω.Equal(h4))
+	}},
+
+	{"should acquire shared and exclusive handles", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		shared := &backend.AcquireOptions{Mode: rpc.Mode_SHARED}
+
+		// readers coexist
+		r1, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, shared)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(r1.Mode).To(Ω.Equal(rpc.Mode_SHARED))
+		g.Expect(r1.Slot).To(Ω.Equal(1))
+
+		r2, err := subject.Acquire(ctx, owner2, namespace, name, now.Add(minute), nil, shared)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(r2.Slot).To(Ω.Equal(2))
+
+		// writers are queued and block new readers
+		_, err = subject.Acquire(ctx, owner3, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).To(Ω.Equal(accord.ErrAcquired))
+		_, err = subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, shared)
+		g.Expect(err).To(Ω.Equal(accord.ErrAcquired))
+		_, err = subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).To(Ω.Equal(accord.ErrAcquired))
+
+		// queued writers are granted once all readers are done
+		g.Expect(subject.Done(ctx, owner1, r1.ID, nil, nil)).To(Ω.Succeed())
+		_, err = subject.Acquire(ctx, owner3, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).To(Ω.Equal(accord.ErrAcquired))
+		g.Expect(subject.Done(ctx, owner2, r2.ID, nil, nil)).To(Ω.Succeed())
+
+		// done shared handles are released, rather than marking the resource as done
+		g.Expect(subject.Count(ctx, &rpc.ListRequest_Filter{Status: rpc.ListRequest_Filter_DONE})).To(Ω.Equal(int64(0)))
+		g.Expect(subject.Purge(ctx, &backend.PurgeFilter{Prefix: namespace, Before: now.Add(minute)})).To(Ω.Equal(int64(0)))
+
+		w, err := subject.Acquire(ctx, owner3, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(w.Mode).To(Ω.Equal(rpc.Mode_EXCLUSIVE))
+		g.Expect(w.Slot).To(Ω.Equal(0))
+		g.Expect(w.Queued).To(Ω.BeFalse())
+
+		_, err = subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, shared)
+		g.Expect(err).To(Ω.Equal(accord.ErrAcquired))
+		_, err = subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).To(Ω.Equal(accord.ErrAcquired))
+
+		// readers may reacquire once the writer has expired
+		g.Expect(subject.Renew(ctx, owner3, w.ID, now.Add(-time.Second), nil, nil)).To(Ω.Succeed())
+		r3, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, shared)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(r3.Slot).To(Ω.Equal(1))
+		g.Expect(subject.Get(ctx, r3.ID)).To(Ω.Equal(r3))
+		g.Expect(subject.Done(ctx, owner1, r3.ID, nil, nil)).To(Ω.Succeed())
+
+		// done writers mark the resource as done
+		w, err = subject.Acquire(ctx, owner2, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(subject.Done(ctx, owner2, w.ID, nil, nil)).To(Ω.Succeed())
+		_, err = subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, shared)
+		g.Expect(err).To(Ω.Equal(accord.ErrDone))
+	}},
+
+	{"should get by ID", func(g *Ω.WithT, subject backend.Backend) {
+		now := time.Now()
+		h1, err := subject.Acquire(ctx, owner1, namespace, name, now.Add(minute), nil, nil)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+
+		h2, err := subject.Get(ctx, h1.ID)
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h2).To(Ω.Equal(h1))
+
+		h3, err := subject.Get(ctx, uuid.New())
+		g.Expect(err).NotTo(Ω.HaveOccurred())
+		g.Expect(h3).To(Ω.BeNil())
+	}},
+}
@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a list cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("accord: invalid cursor")
+
+// Cursor is a position within a list of handles. Handles are listed newest
+// first, ordered by creation time and resource. Unlike IDs, neither changes
+// when a handle is taken over.
+type Cursor struct {
+	CreatedTime time.Time
+	Namespace   string
+	Name        string
+	Slot        int
+}
+
+// ParseCursor decodes an opaque cursor string. It returns nil for an empty
+// string.
+func ParseCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) < 17 {
+		return nil, ErrInvalidCursor
+	}
+
+	c := &Cursor{
+		CreatedTime: time.Unix(int64(binary.BigEndian.Uint64(b[0:])), int64(binary.BigEndian.Uint32(b[8:]))),
+		Slot:        int(binary.BigEndian.Uint32(b[12:])),
+	}
+	n, sz := binary.Uvarint(b[16:])
+	if sz <= 0 || n > uint64(len(b)-16-sz) {
+		return nil, ErrInvalidCursor
+	}
+	b = b[16+sz:]
+	c.Namespace, c.Name = string(b[:n]), string(b[n:])
+	return c, nil
+}
+
+// String encodes the cursor as an opaque string.
+func (c *Cursor) String() string {
+	b := make([]byte, 16, 16+binary.MaxVarintLen64+len(c.Namespace)+len(c.Name))
+	binary.BigEndian.PutUint64(b[0:], uint64(c.CreatedTime.Unix()))
+	binary.BigEndian.PutUint32(b[8:], uint32(c.CreatedTime.Nanosecond()))
+	binary.BigEndian.PutUint32(b[12:], uint32(c.Slot))
+	b = binary.AppendUvarint(b, uint64(len(c.Namespace)))
+	b = append(b, c.Namespace...)
+	b = append(b, c.Name...)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Precedes returns true if the handle is listed after the cursor position.
+func (c *Cursor) Precedes(h *HandleData) bool {
+	if c == nil {
+		return true
+	}
+	if !h.CreatedTime.Equal(c.CreatedTime) {
+		return h.CreatedTime.Before(c.CreatedTime)
+	}
+	return compareResource(h, c.Namespace, c.Name, c.Slot) < 0
+}
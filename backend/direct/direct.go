@@ -37,6 +37,14 @@ func (b *bypass) Done(ctx context.Context, in *rpc.DoneRequest, _ ...grpc.CallOp
 	return b.s.Done(ctx, in)
 }
 
+func (b *bypass) Transfer(ctx context.Context, in *rpc.TransferRequest, _ ...grpc.CallOption) (*rpc.TransferResponse, error) {
+	return b.s.Transfer(ctx, in)
+}
+
+func (b *bypass) Purge(ctx context.Context, in *rpc.PurgeRequest, _ ...grpc.CallOption) (*rpc.PurgeResponse, error) {
+	return b.s.Purge(ctx, in)
+}
+
 func (b *bypass) List(ctx context.Context, in *rpc.ListRequest, _ ...grpc.CallOption) (rpc.V1_ListClient, error) {
 	ch := make(chan *rpc.Handle, 10)
 	lc := &listClient{ctx: ctx, ch: ch}
@@ -53,6 +61,22 @@ func (b *bypass) List(ctx context.Context, in *rpc.ListRequest, _ ...grpc.CallOp
 	return lc, nil
 }
 
+func (b *bypass) ListPage(ctx context.Context, in *rpc.ListRequest, _ ...grpc.CallOption) (*rpc.ListPageResponse, error) {
+	return b.s.ListPage(ctx, in)
+}
+
+func (b *bypass) MarkDone(ctx context.Context, _ ...grpc.CallOption) (rpc.V1_MarkDoneClient, error) {
+	return &markDoneClient{ctx: ctx, s: b.s}, nil
+}
+
+func (b *bypass) ValidateToken(ctx context.Context, in *rpc.ValidateTokenRequest, _ ...grpc.CallOption) (*rpc.ValidateTokenResponse, error) {
+	return b.s.ValidateToken(ctx, in)
+}
+
+func (b *bypass) Check(ctx context.Context, in *rpc.CheckRequest, _ ...grpc.CallOption) (*rpc.CheckResponse, error) {
+	return b.s.Check(ctx, in)
+}
+
 // --------------------------------------------------------------------
 
 type listClient struct {
@@ -96,3 +120,53 @@ func (s *listServer) Send(h *rpc.Handle) error {
 	}
 	return s.ctx.Err()
 }
+
+// --------------------------------------------------------------------
+
+// markDoneClient buffers requests and submits them on CloseAndRecv.
+type markDoneClient struct {
+	grpc.ClientStream
+
+	ctx  context.Context
+	s    rpc.V1Server
+	reqs []*rpc.MarkDoneRequest
+}
+
+func (c *markDoneClient) Context() context.Context { return c.ctx }
+func (c *markDoneClient) Send(req *rpc.MarkDoneRequest) error {
+	c.reqs = append(c.reqs, req)
+	return c.ctx.Err()
+}
+func (c *markDoneClient) CloseAndRecv() (*rpc.MarkDoneResponse, error) {
+	srv := &markDoneServer{ctx: c.ctx, reqs: c.reqs}
+	if err := c.s.MarkDone(srv); err != nil {
+		return nil, err
+	}
+	return srv.res, nil
+}
+
+type markDoneServer struct {
+	grpc.ServerStream
+
+	ctx  context.Context
+	reqs []*rpc.MarkDoneRequest
+	res  *rpc.MarkDoneResponse
+}
+
+func (s *markDoneServer) Context() context.Context { return s.ctx }
+func (s *markDoneServer) Recv() (*rpc.MarkDoneRequest, error) {
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(s.reqs) == 0 {
+		return nil, io.EOF
+	}
+
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return req, nil
+}
+func (s *markDoneServer) SendAndClose(res *rpc.MarkDoneResponse) error {
+	s.res = res
+	return nil
+}
@@ -39,14 +39,14 @@ var _ = Describe("ServerBypass", func() {
 	})
 
 	It("should proxy streaming RPC calls", func() {
-		_, err := backend.Acquire(ctx, owner, "", "res1", time.Now(), nil)
+		_, err := backend.Acquire(ctx, owner, "", "res1", time.Now(), nil, nil)
 		Expect(err).NotTo(HaveOccurred())
-		h2, err := backend.Acquire(ctx, owner, "", "res2", time.Now(), nil)
+		h2, err := backend.Acquire(ctx, owner, "", "res2", time.Now(), nil, nil)
 		Expect(err).NotTo(HaveOccurred())
-		Expect(backend.Done(ctx, owner, h2.ID, nil)).To(Succeed())
-		h3, err := backend.Acquire(ctx, owner, "", "res3", time.Now(), nil)
+		Expect(backend.Done(ctx, owner, h2.ID, nil, nil)).To(Succeed())
+		h3, err := backend.Acquire(ctx, owner, "", "res3", time.Now(), nil, nil)
 		Expect(err).NotTo(HaveOccurred())
-		Expect(backend.Done(ctx, owner, h3.ID, nil)).To(Succeed())
+		Expect(backend.Done(ctx, owner, h3.ID, nil, nil)).To(Succeed())
 
 		iter, err := subject.List(ctx, &rpc.ListRequest{
 			Filter: &rpc.ListRequest_Filter{
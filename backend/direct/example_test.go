@@ -5,17 +5,14 @@ import (
 
 	"github.com/bsm/accord"
 	"github.com/bsm/accord/backend/direct"
-	"github.com/bsm/accord/backend/postgres"
+	"github.com/bsm/accord/backend/mock"
 )
 
 func Example() {
 	ctx := context.Background()
 
-	// Open a backend connection.
-	backend, err := postgres.Open(ctx, "postgres", "postgres://127.0.0.1:5432/accord")
-	if err != nil {
-		panic(err)
-	}
+	// Create a backend.
+	backend := mock.New()
 	defer backend.Close()
 
 	// Bypass gRPC servers and connect a client directly to a backend (not recommended).
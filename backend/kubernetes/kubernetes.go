@@ -0,0 +1,838 @@
+// Package kubernetes implements a backend which stores handles as
+// coordination.k8s.io/v1 Lease objects, making them visible to standard
+// cluster tooling.
+//
+// Each resource is stored as a Lease with a name derived from its
+// namespace and name, semaphores use one Lease per slot. The lease holder
+// identifies the current owner, accord specific state is kept in annotations
+// and the status (pending or done) is exposed as a label. Fencing tokens are
+// issued by an additional counter Lease per namespace, which is never purged.
+//
+// Issuing a token costs two extra API calls (get and update of the counter)
+// per Acquire, Transfer and MarkDone record and serializes all writers of a
+// namespace on a single object. Under heavy contention within a namespace,
+// writes may fail with a conflict error after exhausting their retries.
+package kubernetes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/rpc"
+	"github.com/google/uuid"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	typedv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
+	"k8s.io/client-go/rest"
+)
+
+// Label and annotation keys.
+const (
+	LabelManagedBy = "app.kubernetes.io/managed-by"
+	LabelStatus    = "accord.bsm.io/status"
+	LabelHandleID  = "accord.bsm.io/handle-id"
+	LabelResource  = "accord.bsm.io/resource"
+
+	AnnotationNamespace = "accord.bsm.io/namespace"
+	AnnotationName      = "accord.bsm.io/name"
+	AnnotationCreatedAt = "accord.bsm.io/created-at"
+	AnnotationExpiresAt = "accord.bsm.io/expires-at"
+	AnnotationDoneAt    = "accord.bsm.io/done-at"
+	AnnotationMetadata  = "accord.bsm.io/metadata"
+	AnnotationPrevOwner = "accord.bsm.io/previous-owner"
+	AnnotationVersion   = "accord.bsm.io/metadata-version"
+	AnnotationToken     = "accord.bsm.io/fencing-token"
+	AnnotationSlot      = "accord.bsm.io/slot"
+	AnnotationCapacity  = "accord.bsm.io/capacity"
+	AnnotationMode      = "accord.bsm.io/mode"
+	AnnotationQueued    = "accord.bsm.io/queued"
+)
+
+// Status label values.
+const (
+	StatusPending = "pending"
+	StatusDone    = "done"
+)
+
+const managedBy = "accord"
+
+// maxAttempts limits the number of optimistic concurrency retries.
+const maxAttempts = 10
+
+// Options contains options for the kubernetes backend.
+type Options struct {
+	Namespace string // kubernetes namespace to store leases in, default: "default"
+	Prefix    string // lease name prefix, default: "accord-"
+}
+
+func (o *Options) norm() *Options {
+	var p Options
+	if o != nil {
+		p = *o
+	}
+
+	if p.Namespace == "" {
+		p.Namespace = metav1.NamespaceDefault
+	}
+	if p.Prefix == "" {
+		p.Prefix = "accord-"
+	}
+	return &p
+}
+
+type kube struct {
+	leases typedv1.LeaseInterface
+	opt    *Options
+}
+
+// Open connects to the kubernetes API using the in-cluster configuration.
+func Open(opt *Options) (backend.Backend, error) {
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return New(client, opt), nil
+}
+
+// New inits a backend using a custom client.
+func New(client kubernetes.Interface, opt *Options) backend.Backend {
+	opt = opt.norm()
+	return &kube{
+		leases: client.CoordinationV1().Leases(opt.Namespace),
+		opt:    opt,
+	}
+}
+
+// Acquire implements the backend.Backend interface.
+func (b *kube) Acquire(ctx context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string, opt *backend.AcquireOptions) (*backend.HandleData, error) {
+	if opt.GetMode() == rpc.Mode_SHARED {
+		return b.acquireShared(ctx, owner, namespace, name, exp, metadata)
+	}
+
+	capacity := opt.GetCapacity()
+	for slot := 0; slot < capacity; slot++ {
+		handle, err := b.acquireSlot(ctx, owner, namespace, name, exp, metadata, slot, capacity, rpc.Mode_EXCLUSIVE)
+		if err == nil && capacity == 1 {
+			return b.verifyExclusive(ctx, handle)
+		} else if err != accord.ErrAcquired {
+			return handle, err
+		}
+	}
+	return nil, accord.ErrAcquired
+}
+
+// verifyExclusive checks for held shared handles after an exclusive handle
+// has been claimed. If there are any, the claim is marked as queued.
+func (b *kube) verifyExclusive(ctx context.Context, handle *backend.HandleData) (*backend.HandleData, error) {
+	handles, err := b.findByResource(ctx, handle.Namespace, handle.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !backend.HasSharedHolders(handles, time.Now()) {
+		return handle, nil
+	}
+
+	if err := b.update(ctx, handle.Owner, handle.ID, func(h *backend.HandleData) error {
+		h.Queued = true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return nil, accord.ErrAcquired
+}
+
+// acquireShared acquires a shared handle. Once claimed, the handle is
+// released again if slot 0 has been acquired in the meantime.
+func (b *kube) acquireShared(ctx context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string) (*backend.HandleData, error) {
+	opt := &backend.AcquireOptions{Mode: rpc.Mode_SHARED}
+
+	for attempt := 1; ; attempt++ {
+		handles, err := b.findByResource(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var slots []*backend.HandleData
+		for _, h := range handles {
+			for len(slots) <= h.Slot {
+				slots = append(slots, nil)
+			}
+			slots[h.Slot] = h
+		}
+
+		slot, _, err := backend.SelectSlot(slots, owner, opt, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		handle, err := b.acquireSlot(ctx, owner, namespace, name, exp, metadata, slot, 1, rpc.Mode_SHARED)
+		if err == accord.ErrAcquired && attempt < maxAttempts {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		lease, err := b.leases.Get(ctx, b.leaseName(namespace, name, 0), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return handle, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		stored, err := decodeLease(lease)
+		if err != nil {
+			return nil, err
+		}
+		if stored.IsReleased(time.Now()) {
+			return handle, nil
+		}
+
+		if err := b.update(ctx, owner, handle.ID, func(h *backend.HandleData) error {
+			h.DoneTime = time.Now()
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		if stored.IsDone() {
+			return nil, accord.ErrDone
+		}
+		return nil, accord.ErrAcquired
+	}
+}
+
+// acquireSlot acquires a single slot of the resource.
+func (b *kube) acquireSlot(ctx context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string, slot, capacity int, mode rpc.Mode) (*backend.HandleData, error) {
+	leaseName := b.leaseName(namespace, name, slot)
+
+	for attempt := 1; ; attempt++ {
+		now := time.Now()
+		handle := &backend.HandleData{
+			ID:           uuid.New(),
+			Namespace:    namespace,
+			Name:         name,
+			Owner:        owner,
+			ExpTime:      exp,
+			NumAcquired:  1,
+			MetaVersion:  1,
+			FencingToken: 1,
+			Slot:         slot,
+			Capacity:     capacity,
+			Mode:         mode,
+		}
+		handle.UpdateMetadata(metadata)
+
+		lease, err := b.leases.Get(ctx, leaseName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			if handle.FencingToken, err = b.nextToken(ctx, namespace, 0); err != nil {
+				return nil, err
+			}
+
+			lease = &coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        leaseName,
+					Annotations: map[string]string{AnnotationCreatedAt: formatTime(now)},
+				},
+			}
+			if err := encodeLease(lease, handle, now); err != nil {
+				return nil, err
+			}
+
+			created, err := b.leases.Create(ctx, lease, metav1.CreateOptions{})
+			if apierrors.IsAlreadyExists(err) && attempt < maxAttempts {
+				continue
+			} else if apierrors.IsAlreadyExists(err) {
+				return nil, accord.ErrAcquired
+			} else if err != nil {
+				return nil, err
+			}
+			return decodeLease(created)
+		} else if err != nil {
+			return nil, err
+		}
+
+		stored, err := decodeLease(lease)
+		if err != nil {
+			return nil, err
+		}
+		if released := stored.IsReleased(now) || (stored.Queued && stored.Owner == owner); !released && stored.IsDone() {
+			return nil, accord.ErrDone
+		} else if !released {
+			return nil, accord.ErrAcquired
+		}
+
+		handle.NumAcquired = stored.NumAcquired + 1
+		if handle.FencingToken, err = b.nextToken(ctx, namespace, stored.FencingToken); err != nil {
+			return nil, err
+		}
+		handle.PreviousOwner = stored.Owner
+		handle.UpdateMetadata(stored.Metadata)
+		handle.MetaVersion = stored.MetaVersion
+		if !maps.Equal(handle.Metadata, stored.Metadata) {
+			handle.MetaVersion++
+		}
+		if err := encodeLease(lease, handle, now); err != nil {
+			return nil, err
+		}
+
+		updated, err := b.leases.Update(ctx, lease, metav1.UpdateOptions{})
+		if apierrors.IsConflict(err) && attempt < maxAttempts {
+			continue
+		} else if apierrors.IsConflict(err) {
+			return nil, accord.ErrAcquired
+		} else if err != nil {
+			return nil, err
+		}
+		return decodeLease(updated)
+	}
+}
+
+// Renew implements the backend.Backend interface.
+func (b *kube) Renew(ctx context.Context, owner string, handleID uuid.UUID, exp time.Time, metadata map[string]string, opt *backend.UpdateOptions) error {
+	return b.update(ctx, owner, handleID, func(handle *backend.HandleData) error {
+		if err := handle.ApplyUpdate(metadata, opt); err != nil {
+			return err
+		}
+		handle.ExpTime = exp
+		return nil
+	})
+}
+
+// Done implements the backend.Backend interface.
+func (b *kube) Done(ctx context.Context, owner string, handleID uuid.UUID, metadata map[string]string, opt *backend.UpdateOptions) error {
+	return b.update(ctx, owner, handleID, func(handle *backend.HandleData) error {
+		if err := handle.ApplyUpdate(metadata, opt); err != nil {
+			return err
+		}
+		handle.DoneTime = time.Now()
+		return nil
+	})
+}
+
+// Transfer implements the backend.Backend interface.
+func (b *kube) Transfer(ctx context.Context, owner string, handleID uuid.UUID, newOwner string, rotateID bool) (*backend.HandleData, error) {
+	newID := handleID
+	if rotateID {
+		newID = uuid.New()
+	}
+
+	var transferred *backend.HandleData
+	if err := b.update(ctx, owner, handleID, func(handle *backend.HandleData) (err error) {
+		if !handle.IsHeld(time.Now()) {
+			return backend.ErrInvalidHandle
+		}
+		if handle.FencingToken, err = b.nextToken(ctx, handle.Namespace, handle.FencingToken); err != nil {
+			return err
+		}
+		handle.ID = newID
+		handle.PreviousOwner = handle.Owner
+		handle.Owner = newOwner
+		handle.UpdatedTime = time.Now()
+		transferred = handle
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return transferred, nil
+}
+
+// MarkDone implements the backend.Backend interface.
+func (b *kube) MarkDone(ctx context.Context, records []backend.DoneRecord) ([]error, error) {
+	errs := make([]error, len(records))
+	for i, rec := range records {
+		now := time.Now()
+		handle := &backend.HandleData{
+			ID:          uuid.New(),
+			Namespace:   rec.Namespace,
+			Name:        rec.Name,
+			Owner:       rec.Owner,
+			ExpTime:     rec.DoneTime,
+			DoneTime:    rec.DoneTime,
+			NumAcquired: 1,
+			MetaVersion: 1,
+			Capacity:    1,
+		}
+		if handle.DoneTime.IsZero() {
+			handle.ExpTime, handle.DoneTime = now, now
+		}
+		handle.UpdateMetadata(rec.Metadata)
+
+		token, err := b.nextToken(ctx, rec.Namespace, 0)
+		if err != nil {
+			return nil, err
+		}
+		handle.FencingToken = token
+
+		leaseName := b.leaseName(rec.Namespace, rec.Name, 0)
+		lease := &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        leaseName,
+				Annotations: map[string]string{AnnotationCreatedAt: formatTime(now)},
+			},
+		}
+		if err := encodeLease(lease, handle, now); err != nil {
+			return nil, err
+		}
+
+		_, err = b.leases.Create(ctx, lease, metav1.CreateOptions{})
+		if err == nil {
+			continue
+		} else if !apierrors.IsAlreadyExists(err) {
+			return nil, err
+		}
+
+		existing, err := b.leases.Get(ctx, leaseName, metav1.GetOptions{})
+		if err != nil {
+			return nil, err
+		}
+		if stored, err := decodeLease(existing); err != nil {
+			return nil, err
+		} else if stored.IsDone() {
+			errs[i] = accord.ErrDone
+		} else {
+			errs[i] = accord.ErrAcquired
+		}
+	}
+	return errs, nil
+}
+
+// Get implements the backend.Backend interface.
+func (b *kube) Get(ctx context.Context, handleID uuid.UUID) (*backend.HandleData, error) {
+	lease, err := b.findByID(ctx, handleID)
+	if err != nil || lease == nil {
+		return nil, err
+	}
+	return decodeLease(lease)
+}
+
+// ValidateToken implements the backend.Backend interface.
+func (b *kube) ValidateToken(ctx context.Context, handleID uuid.UUID, token int64) error {
+	handle, err := b.Get(ctx, handleID)
+	if err != nil {
+		return err
+	}
+	return backend.ValidateFencingToken(handle, token)
+}
+
+// List implements the backend.Backend interface.
+func (b *kube) List(ctx context.Context, req *rpc.ListRequest, iter backend.Iterator) error {
+	cursor, err := backend.ParseListCursor(req)
+	if err != nil {
+		return err
+	}
+	filter, err := backend.ParseListFilter(req.GetFilter())
+	if err != nil {
+		return err
+	}
+
+	handles, err := b.collect(ctx, filter, cursor)
+	if err != nil {
+		return err
+	}
+
+	backend.SortHandles(handles, req)
+
+	if o := req.GetOffset(); o < uint64(len(handles)) {
+		handles = handles[o:]
+	} else {
+		handles = nil
+	}
+	if n := req.GetLimit(); n != 0 && int(n) < len(handles) {
+		handles = handles[:n]
+	}
+
+	for _, handle := range handles {
+		if err := iter(handle); err == backend.ErrIteratorDone {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count implements the backend.Backend interface.
+func (b *kube) Count(ctx context.Context, req *rpc.ListRequest_Filter) (int64, error) {
+	filter, err := backend.ParseListFilter(req)
+	if err != nil {
+		return 0, err
+	}
+
+	handles, err := b.collect(ctx, filter, nil)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(handles)), nil
+}
+
+// collect loads all handles after the cursor matching the filter.
+func (b *kube) collect(ctx context.Context, filter *backend.ListFilter, cursor *backend.Cursor) ([]*backend.HandleData, error) {
+	selector := labels.Set{LabelManagedBy: managedBy}
+	if filter != nil && filter.Status == rpc.ListRequest_Filter_DONE {
+		selector[LabelStatus] = StatusDone
+	} else if filter != nil && filter.Status == rpc.ListRequest_Filter_PENDING {
+		selector[LabelStatus] = StatusPending
+	}
+
+	var handles []*backend.HandleData
+	opts := metav1.ListOptions{LabelSelector: selector.String(), Limit: 500}
+	for {
+		list, err := b.leases.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range list.Items {
+			handle, err := decodeLease(&list.Items[i])
+			if err != nil {
+				return nil, err
+			}
+			if cursor.Precedes(handle) && filter.Matches(handle) {
+				handles = append(handles, handle)
+			}
+		}
+
+		if opts.Continue = list.Continue; opts.Continue == "" {
+			break
+		}
+	}
+	return handles, nil
+}
+
+// Purge implements the backend.Backend interface.
+func (b *kube) Purge(ctx context.Context, filter *backend.PurgeFilter) (int64, error) {
+	selector := labels.Set{LabelManagedBy: managedBy, LabelStatus: StatusDone}
+
+	var num int64
+	opts := metav1.ListOptions{LabelSelector: selector.String(), Limit: 500}
+	for {
+		list, err := b.leases.List(ctx, opts)
+		if err != nil {
+			return num, err
+		}
+
+		for i := range list.Items {
+			lease := &list.Items[i]
+			handle, err := decodeLease(lease)
+			if err != nil {
+				return num, err
+			}
+			if !filter.Matches(handle) {
+				continue
+			}
+
+			rv := lease.ResourceVersion
+			err = b.leases.Delete(ctx, lease.Name, metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{UID: &lease.UID, ResourceVersion: &rv},
+			})
+			if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+				continue
+			} else if err != nil {
+				return num, err
+			}
+			num++
+		}
+
+		if opts.Continue = list.Continue; opts.Continue == "" {
+			break
+		}
+	}
+	return num, nil
+}
+
+// Ping implements the backend.Backend interface.
+func (b *kube) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := b.leases.List(ctx, metav1.ListOptions{Limit: 1})
+	return err
+}
+
+// Close implements the backend.Backend interface.
+func (*kube) Close() error { return nil }
+
+func (b *kube) update(ctx context.Context, owner string, handleID uuid.UUID, fn func(*backend.HandleData) error) error {
+	for attempt := 1; ; attempt++ {
+		lease, err := b.findByID(ctx, handleID)
+		if err != nil {
+			return err
+		} else if lease == nil {
+			return backend.ErrInvalidHandle
+		}
+
+		handle, err := decodeLease(lease)
+		if err != nil {
+			return err
+		}
+		if handle.IsDone() || handle.Owner != owner {
+			return backend.ErrInvalidHandle
+		}
+
+		if err := fn(handle); err != nil {
+			return err
+		}
+		if err := encodeLease(lease, handle, time.Now()); err != nil {
+			return err
+		}
+
+		_, err = b.leases.Update(ctx, lease, metav1.UpdateOptions{})
+		if apierrors.IsConflict(err) && attempt < maxAttempts {
+			continue
+		}
+		return err
+	}
+}
+
+func (b *kube) findByID(ctx context.Context, handleID uuid.UUID) (*coordinationv1.Lease, error) {
+	list, err := b.leases.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.Set{
+			LabelManagedBy: managedBy,
+			LabelHandleID:  handleID.String(),
+		}.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	return &list.Items[0], nil
+}
+
+// findByResource returns the handles of all slots of a resource.
+func (b *kube) findByResource(ctx context.Context, namespace, name string) ([]*backend.HandleData, error) {
+	list, err := b.leases.List(ctx, metav1.ListOptions{
+		LabelSelector: labels.Set{
+			LabelManagedBy: managedBy,
+			LabelResource:  resourceKey(namespace, name, 0),
+		}.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	handles := make([]*backend.HandleData, 0, len(list.Items))
+	for i := range list.Items {
+		handle, err := decodeLease(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		if handle.Namespace == namespace && handle.Name == name {
+			handles = append(handles, handle)
+		}
+	}
+	return handles, nil
+}
+
+// nextToken increments the fencing token counter of the namespace and
+// returns the new token, which is always greater than min. Unlike resource
+// leases, counters are not purged, so tokens never restart.
+func (b *kube) nextToken(ctx context.Context, namespace string, min int64) (int64, error) {
+	name := b.opt.Prefix + "token-" + resourceKey(namespace, "", 0)
+
+	for attempt := 1; ; attempt++ {
+		lease, err := b.leases.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			lease = &coordinationv1.Lease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        name,
+					Annotations: map[string]string{AnnotationNamespace: namespace},
+				},
+			}
+		} else if err != nil {
+			return 0, err
+		}
+
+		var token int64
+		if s := lease.Annotations[AnnotationToken]; s != "" {
+			if token, err = strconv.ParseInt(s, 10, 64); err != nil {
+				return 0, err
+			}
+		}
+		token = max(token, min) + 1
+
+		if lease.Annotations == nil {
+			lease.Annotations = make(map[string]string, 2)
+		}
+		lease.Annotations[AnnotationToken] = strconv.FormatInt(token, 10)
+
+		if lease.ResourceVersion == "" {
+			_, err = b.leases.Create(ctx, lease, metav1.CreateOptions{})
+		} else {
+			_, err = b.leases.Update(ctx, lease, metav1.UpdateOptions{})
+		}
+		if (apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err)) && attempt < maxAttempts {
+			continue // someone else has issued a token
+		} else if err != nil {
+			return 0, err
+		}
+		return token, nil
+	}
+}
+
+func (b *kube) leaseName(namespace, name string, slot int) string {
+	return b.opt.Prefix + resourceKey(namespace, name, slot)
+}
+
+// --------------------------------------------------------------------
+
+func resourceKey(namespace, name string, slot int) string {
+	key := namespace + "\x00" + name
+	if slot != 0 {
+		key += "\x00" + strconv.Itoa(slot)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:20])
+}
+
+func encodeLease(lease *coordinationv1.Lease, handle *backend.HandleData, now time.Time) error {
+	meta, err := json.Marshal(handle.Metadata)
+	if err != nil {
+		return err
+	}
+
+	if lease.Labels == nil {
+		lease.Labels = make(map[string]string, 4)
+	}
+	lease.Labels[LabelManagedBy] = managedBy
+	lease.Labels[LabelHandleID] = handle.ID.String()
+	lease.Labels[LabelStatus] = StatusPending
+	lease.Labels[LabelResource] = resourceKey(handle.Namespace, handle.Name, 0)
+
+	if lease.Annotations == nil {
+		lease.Annotations = make(map[string]string, 6)
+	}
+	lease.Annotations[AnnotationNamespace] = handle.Namespace
+	lease.Annotations[AnnotationName] = handle.Name
+	lease.Annotations[AnnotationExpiresAt] = formatTime(handle.ExpTime)
+	lease.Annotations[AnnotationMetadata] = string(meta)
+	lease.Annotations[AnnotationVersion] = strconv.FormatInt(handle.MetaVersion, 10)
+	lease.Annotations[AnnotationToken] = strconv.FormatInt(handle.FencingToken, 10)
+	lease.Annotations[AnnotationSlot] = strconv.Itoa(handle.Slot)
+	lease.Annotations[AnnotationCapacity] = strconv.Itoa(handle.Capacity)
+	delete(lease.Annotations, AnnotationMode)
+	if handle.Mode != rpc.Mode_EXCLUSIVE {
+		lease.Annotations[AnnotationMode] = handle.Mode.String()
+	}
+	delete(lease.Annotations, AnnotationQueued)
+	if handle.Queued {
+		lease.Annotations[AnnotationQueued] = "true"
+	}
+	delete(lease.Annotations, AnnotationPrevOwner)
+	if handle.PreviousOwner != "" {
+		lease.Annotations[AnnotationPrevOwner] = handle.PreviousOwner
+	}
+	delete(lease.Annotations, AnnotationDoneAt)
+	if handle.IsDone() {
+		lease.Labels[LabelStatus] = StatusDone
+		lease.Annotations[AnnotationDoneAt] = formatTime(handle.DoneTime)
+	}
+
+	renewTime := metav1.NewMicroTime(now)
+	duration := int32(math.Max(0, math.Ceil(handle.ExpTime.Sub(now).Seconds())))
+	transitions := int32(handle.NumAcquired - 1)
+	holder := handle.Owner
+
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != holder || lease.Spec.LeaseTransitions == nil || *lease.Spec.LeaseTransitions != transitions {
+		lease.Spec.AcquireTime = &renewTime
+	}
+	lease.Spec.HolderIdentity = &holder
+	lease.Spec.LeaseDurationSeconds = &duration
+	lease.Spec.LeaseTransitions = &transitions
+	lease.Spec.RenewTime = &renewTime
+	return nil
+}
+
+func decodeLease(lease *coordinationv1.Lease) (*backend.HandleData, error) {
+	handleID, err := uuid.Parse(lease.Labels[LabelHandleID])
+	if err != nil {
+		return nil, err
+	}
+
+	handle := &backend.HandleData{
+		ID:            handleID,
+		Namespace:     lease.Annotations[AnnotationNamespace],
+		Name:          lease.Annotations[AnnotationName],
+		PreviousOwner: lease.Annotations[AnnotationPrevOwner],
+		NumAcquired:   1,
+		MetaVersion:   1,
+		Capacity:      1,
+	}
+	if lease.Spec.HolderIdentity != nil {
+		handle.Owner = *lease.Spec.HolderIdentity
+	}
+	if lease.Spec.LeaseTransitions != nil {
+		handle.NumAcquired = int(*lease.Spec.LeaseTransitions) + 1
+	}
+	if lease.Spec.RenewTime != nil {
+		handle.UpdatedTime = lease.Spec.RenewTime.Time
+	}
+	if handle.CreatedTime, err = parseTime(lease.Annotations[AnnotationCreatedAt]); err != nil {
+		return nil, err
+	}
+	if handle.ExpTime, err = parseTime(lease.Annotations[AnnotationExpiresAt]); err != nil {
+		return nil, err
+	}
+	if handle.DoneTime, err = parseTime(lease.Annotations[AnnotationDoneAt]); err != nil {
+		return nil, err
+	}
+	if s := lease.Annotations[AnnotationVersion]; s != "" {
+		if handle.MetaVersion, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	// leases created before fencing tokens were introduced
+	handle.FencingToken = int64(handle.NumAcquired)
+	if s := lease.Annotations[AnnotationToken]; s != "" {
+		if handle.FencingToken, err = strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if s := lease.Annotations[AnnotationSlot]; s != "" {
+		if handle.Slot, err = strconv.Atoi(s); err != nil {
+			return nil, err
+		}
+	}
+	if s := lease.Annotations[AnnotationCapacity]; s != "" {
+		if handle.Capacity, err = strconv.Atoi(s); err != nil {
+			return nil, err
+		}
+	}
+	if s := lease.Annotations[AnnotationMode]; s != "" {
+		handle.Mode = rpc.Mode(rpc.Mode_value[s])
+	}
+	handle.Queued = lease.Annotations[AnnotationQueued] == "true"
+	if s := lease.Annotations[AnnotationMetadata]; s != "" {
+		if err := json.Unmarshal([]byte(s), &handle.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	return handle, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
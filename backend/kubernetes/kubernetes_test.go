@@ -0,0 +1,181 @@
+package kubernetes_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend/backendtest"
+	"github.com/bsm/accord/backend/kubernetes"
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Backend", func() {
+	var data backendtest.BehavesLikeBackendData
+
+	BeforeEach(func() {
+		data.Subject = kubernetes.New(newFakeClient(), &kubernetes.Options{Namespace: "accord"})
+	})
+
+	Context("defaults", backendtest.BehavesLikeBackend(&data))
+})
+
+var _ = Describe("Leases", func() {
+	var client *fake.Clientset
+	var ctx = context.Background()
+
+	BeforeEach(func() {
+		client = newFakeClient()
+	})
+
+	It("should store handles as leases", func() {
+		subject := kubernetes.New(client, &kubernetes.Options{Namespace: "accord"})
+		h, err := subject.Acquire(ctx, "THEOWNER", "ns", "resource", time.Now().Add(time.Minute), map[string]string{"k": "v"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		list, err := client.CoordinationV1().Leases("accord").List(ctx, metav1.ListOptions{LabelSelector: kubernetes.LabelManagedBy + "=accord"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Items).To(HaveLen(1))
+
+		lease := list.Items[0]
+		Expect(lease.Name).To(HavePrefix("accord-"))
+		Expect(lease.Labels).To(Equal(map[string]string{
+			kubernetes.LabelManagedBy: "accord",
+			kubernetes.LabelHandleID:  h.ID.String(),
+			kubernetes.LabelStatus:    kubernetes.StatusPending,
+			kubernetes.LabelResource:  strings.TrimPrefix(lease.Name, "accord-"),
+		}))
+		Expect(lease.Annotations).To(HaveKeyWithValue(kubernetes.AnnotationNamespace, "ns"))
+		Expect(lease.Annotations).To(HaveKeyWithValue(kubernetes.AnnotationName, "resource"))
+		Expect(lease.Annotations).To(HaveKeyWithValue(kubernetes.AnnotationMetadata, `{"k":"v"}`))
+		Expect(*lease.Spec.HolderIdentity).To(Equal("THEOWNER"))
+		Expect(*lease.Spec.LeaseDurationSeconds).To(Equal(int32(60)))
+		Expect(*lease.Spec.LeaseTransitions).To(Equal(int32(0)))
+
+		Expect(subject.Done(ctx, "THEOWNER", h.ID, nil, nil)).To(Succeed())
+		updated, err := client.CoordinationV1().Leases("accord").Get(ctx, lease.Name, metav1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.Labels).To(HaveKeyWithValue(kubernetes.LabelStatus, kubernetes.StatusDone))
+		Expect(updated.Annotations).To(HaveKey(kubernetes.AnnotationDoneAt))
+	})
+
+	It("should resolve concurrent acquires", func() {
+		subject := kubernetes.New(client, nil)
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var acquired, held int
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				_, err := subject.Acquire(ctx, "owner"+strconv.Itoa(i), "ns", "resource", time.Now().Add(time.Minute), nil, nil)
+				mu.Lock()
+				defer mu.Unlock()
+
+				if err == accord.ErrAcquired {
+					held++
+				} else {
+					Expect(err).NotTo(HaveOccurred())
+					acquired++
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		Expect(acquired).To(Equal(1))
+		Expect(held).To(Equal(9))
+	})
+
+	It("should report lost create races as acquired", func() {
+		gr := coordinationv1.SchemeGroupVersion.WithResource("leases").GroupResource()
+		isCounter := func(name string) bool { return strings.HasPrefix(name, "accord-token-") }
+		client.PrependReactor("get", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			name := action.(k8stesting.GetAction).GetName()
+			return !isCounter(name), nil, apierrors.NewNotFound(gr, name)
+		})
+		client.PrependReactor("create", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			lease := action.(k8stesting.CreateAction).GetObject().(*coordinationv1.Lease)
+			return !isCounter(lease.Name), nil, apierrors.NewAlreadyExists(gr, lease.Name)
+		})
+
+		subject := kubernetes.New(client, nil)
+		_, err := subject.Acquire(ctx, "THEOWNER", "ns", "resource", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).To(Equal(accord.ErrAcquired))
+	})
+
+	It("should give up on contended token counters", func() {
+		gr := coordinationv1.SchemeGroupVersion.WithResource("leases").GroupResource()
+		attempts := 0
+		client.PrependReactor("create", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			lease := action.(k8stesting.CreateAction).GetObject().(*coordinationv1.Lease)
+			if !strings.HasPrefix(lease.Name, "accord-token-") {
+				return false, nil, nil
+			}
+			attempts++
+			return true, nil, apierrors.NewAlreadyExists(gr, lease.Name)
+		})
+
+		subject := kubernetes.New(client, nil)
+		_, err := subject.Acquire(ctx, "THEOWNER", "ns", "resource", time.Now().Add(time.Minute), nil, nil)
+		Expect(apierrors.IsAlreadyExists(err)).To(BeTrue())
+		Expect(attempts).To(Equal(10))
+	})
+})
+
+// ------------------------------------------------------------------------
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "accord/backend/kubernetes")
+}
+
+// newFakeClient returns a fake clientset which enforces resourceVersion
+// based optimistic concurrency for leases, similar to the API server.
+func newFakeClient() *fake.Clientset {
+	client := fake.NewClientset()
+	tracker := client.Tracker()
+	gvr := coordinationv1.SchemeGroupVersion.WithResource("leases")
+
+	var version int
+	client.PrependReactor("create", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lease := action.(k8stesting.CreateAction).GetObject().(*coordinationv1.Lease).DeepCopy()
+		version++
+		lease.ResourceVersion = strconv.Itoa(version)
+		if err := tracker.Create(gvr, lease, action.GetNamespace()); err != nil {
+			return true, nil, err
+		}
+		return true, lease, nil
+	})
+	client.PrependReactor("update", "leases", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		lease := action.(k8stesting.UpdateAction).GetObject().(*coordinationv1.Lease).DeepCopy()
+		stored, err := tracker.Get(gvr, action.GetNamespace(), lease.Name)
+		if err != nil {
+			return true, nil, err
+		}
+		if stored.(*coordinationv1.Lease).ResourceVersion != lease.ResourceVersion {
+			return true, nil, apierrors.NewConflict(gvr.GroupResource(), lease.Name, nil)
+		}
+
+		version++
+		lease.ResourceVersion = strconv.Itoa(version)
+		if err := tracker.Update(gvr, lease, action.GetNamespace()); err != nil {
+			return true, nil, err
+		}
+		return true, lease, nil
+	})
+	return client
+}
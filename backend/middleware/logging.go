@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/bsm/accord/backend"
+)
+
+// Logging logs every backend call with its duration and outcome.
+// Uses log.Default() if logger is nil.
+func Logging(logger *log.Logger) backend.Middleware {
+	if logger == nil {
+		logger = log.Default()
+	}
+
+	return Intercept(func(ctx context.Context, method string, call func(context.Context) error) error {
+		start := time.Now()
+		err := call(ctx)
+		if isFailure(err) {
+			logger.Printf("backend: %s failed after %s: %v\n", method, time.Since(start), err)
+		} else {
+			logger.Printf("backend: %s %s in %s\n", method, outcome(err), time.Since(start))
+		}
+		return err
+	})
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/bsm/accord/backend"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics records Prometheus metrics for every backend call:
+//
+//	accord_backend_calls_total{method,outcome}
+//	accord_backend_call_duration_seconds{method}
+//
+// Uses prometheus.DefaultRegisterer if reg is nil. It panics if the
+// collectors cannot be registered.
+func Metrics(reg prometheus.Registerer) backend.Middleware {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+
+	calls := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "accord",
+		Subsystem: "backend",
+		Name:      "calls_total",
+		Help:      "Total number of backend calls.",
+	}, []string{"method", "outcome"})
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "accord",
+		Subsystem: "backend",
+		Name:      "call_duration_seconds",
+		Help:      "Duration of backend calls.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+	reg.MustRegister(calls, duration)
+
+	return Intercept(func(ctx context.Context, method string, call func(context.Context) error) error {
+		start := time.Now()
+		err := call(ctx)
+		duration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+		calls.WithLabelValues(method, outcome(err)).Inc()
+		return err
+	})
+}
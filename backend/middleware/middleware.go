@@ -0,0 +1,173 @@
+// Package middleware contains reusable backend.Middleware implementations
+// for logging, tracing, metrics, timeouts and retries.
+//
+//	b = backend.Chain(b,
+//		middleware.Logging(log.Default()),
+//		middleware.Metrics(prometheus.DefaultRegisterer),
+//		middleware.Retry(nil),
+//		middleware.Timeout(5*time.Second),
+//	)
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/rpc"
+	"github.com/google/uuid"
+)
+
+// Method names, as passed to interceptors.
+const (
+	MethodAcquire       = "Acquire"
+	MethodRenew         = "Renew"
+	MethodDone          = "Done"
+	MethodTransfer      = "Transfer"
+	MethodGet           = "Get"
+	MethodValidateToken = "ValidateToken"
+	MethodList          = "List"
+	MethodCount         = "Count"
+	MethodMarkDone      = "MarkDone"
+	MethodPurge         = "Purge"
+	MethodPing          = "Ping"
+)
+
+// Interceptor intercepts a backend call. It must invoke call exactly once
+// and return its error (or a wrapped version of it).
+type Interceptor func(ctx context.Context, method string, call func(context.Context) error) error
+
+// Intercept creates a middleware which passes every backend call (except
+// Close) through fn. Ping calls are intercepted with a background context.
+func Intercept(fn Interceptor) backend.Middleware {
+	return func(b backend.Backend) backend.Backend {
+		return &interceptor{Backend: b, fn: fn}
+	}
+}
+
+type interceptor struct {
+	backend.Backend
+	fn Interceptor
+}
+
+func (w *interceptor) Acquire(ctx context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string, opt *backend.AcquireOptions) (*backend.HandleData, error) {
+	var handle *backend.HandleData
+	err := w.fn(ctx, MethodAcquire, func(ctx context.Context) (err error) {
+		handle, err = w.Backend.Acquire(ctx, owner, namespace, name, exp, metadata, opt)
+		return
+	})
+	return handle, err
+}
+
+func (w *interceptor) Renew(ctx context.Context, owner string, handleID uuid.UUID, exp time.Time, metadata map[string]string, opt *backend.UpdateOptions) error {
+	return w.fn(ctx, MethodRenew, func(ctx context.Context) error {
+		return w.Backend.Renew(ctx, owner, handleID, exp, metadata, opt)
+	})
+}
+
+func (w *interceptor) Done(ctx context.Context, owner string, handleID uuid.UUID, metadata map[string]string, opt *backend.UpdateOptions) error {
+	return w.fn(ctx, MethodDone, func(ctx context.Context) error {
+		return w.Backend.Done(ctx, owner, handleID, metadata, opt)
+	})
+}
+
+func (w *interceptor) Transfer(ctx context.Context, owner string, handleID uuid.UUID, newOwner string, rotateID bool) (*backend.HandleData, error) {
+	var handle *backend.HandleData
+	err := w.fn(ctx, MethodTransfer, func(ctx context.Context) (err error) {
+		handle, err = w.Backend.Transfer(ctx, owner, handleID, newOwner, rotateID)
+		return
+	})
+	return handle, err
+}
+
+func (w *interceptor) Get(ctx context.Context, handleID uuid.UUID) (*backend.HandleData, error) {
+	var handle *backend.HandleData
+	err := w.fn(ctx, MethodGet, func(ctx context.Context) (err error) {
+		handle, err = w.Backend.Get(ctx, handleID)
+		return
+	})
+	return handle, err
+}
+
+func (w *interceptor) ValidateToken(ctx context.Context, handleID uuid.UUID, token int64) error {
+	return w.fn(ctx, MethodValidateToken, func(ctx context.Context) error {
+		return w.Backend.ValidateToken(ctx, handleID, token)
+	})
+}
+
+func (w *interceptor) List(ctx context.Context, req *rpc.ListRequest, iter backend.Iterator) error {
+	return w.fn(ctx, MethodList, func(ctx context.Context) error {
+		return w.Backend.List(ctx, req, iter)
+	})
+}
+
+func (w *interceptor) Count(ctx context.Context, filter *rpc.ListRequest_Filter) (int64, error) {
+	var num int64
+	err := w.fn(ctx, MethodCount, func(ctx context.Context) (err error) {
+		num, err = w.Backend.Count(ctx, filter)
+		return
+	})
+	return num, err
+}
+
+func (w *interceptor) MarkDone(ctx context.Context, records []backend.DoneRecord) ([]error, error) {
+	var errs []error
+	err := w.fn(ctx, MethodMarkDone, func(ctx context.Context) (err error) {
+		errs, err = w.Backend.MarkDone(ctx, records)
+		return
+	})
+	return errs, err
+}
+
+func (w *interceptor) Purge(ctx context.Context, filter *backend.PurgeFilter) (int64, error) {
+	var num int64
+	err := w.fn(ctx, MethodPurge, func(ctx context.Context) (err error) {
+		num, err = w.Backend.Purge(ctx, filter)
+		return
+	})
+	return num, err
+}
+
+func (w *interceptor) Ping() error {
+	return w.fn(context.Background(), MethodPing, func(context.Context) error {
+		return w.Backend.Ping()
+	})
+}
+
+// --------------------------------------------------------------------
+
+// outcome classifies errors into short, low-cardinality labels.
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, accord.ErrAcquired):
+		return "acquired"
+	case errors.Is(err, accord.ErrDone):
+		return "done"
+	case errors.Is(err, backend.ErrInvalidHandle):
+		return "invalid_handle"
+	case errors.Is(err, backend.ErrVersionMismatch):
+		return "version_mismatch"
+	case errors.Is(err, backend.ErrStaleToken):
+		return "stale_token"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	default:
+		return "error"
+	}
+}
+
+// isFailure returns true for unexpected errors, i.e. not the regular
+// outcomes of an operation, such as a resource being held.
+func isFailure(err error) bool {
+	switch outcome(err) {
+	case "ok", "acquired", "done", "invalid_handle", "version_mismatch", "stale_token":
+		return false
+	}
+	return true
+}
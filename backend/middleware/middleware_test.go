@@ -0,0 +1,249 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/backend/backendtest"
+	"github.com/bsm/accord/backend/middleware"
+	"github.com/bsm/accord/backend/mock"
+	"github.com/bsm/accord/rpc"
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var _ = Describe("Chain", func() {
+	var data backendtest.BehavesLikeBackendData
+
+	BeforeEach(func() {
+		data.Subject = backend.Chain(mock.New(),
+			middleware.Logging(log.New(io.Discard, "", 0)),
+			middleware.Tracing(nil),
+			middleware.Metrics(prometheus.NewRegistry()),
+			middleware.Retry(nil),
+			middleware.Timeout(time.Second),
+		)
+	})
+
+	Context("defaults", backendtest.BehavesLikeBackend(&data))
+
+	It("should apply middlewares in order", func() {
+		var calls []string
+		trace := func(name string) backend.Middleware {
+			return middleware.Intercept(func(ctx context.Context, method string, call func(context.Context) error) error {
+				calls = append(calls, name+">"+method)
+				err := call(ctx)
+				calls = append(calls, name+"<"+method)
+				return err
+			})
+		}
+
+		subject := backend.Chain(mock.New(), trace("a"), trace("b"))
+		Expect(subject.Ping()).To(Succeed())
+		Expect(calls).To(Equal([]string{"a>Ping", "b>Ping", "b<Ping", "a<Ping"}))
+	})
+})
+
+var _ = Describe("Logging", func() {
+	It("should log calls", func() {
+		buf := new(bytes.Buffer)
+		subject := middleware.Logging(log.New(buf, "", 0))(mock.New())
+
+		_, err := subject.Acquire(context.Background(), "owner", "ns", "name", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = subject.Acquire(context.Background(), "owner", "ns", "name", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).To(Equal(accord.ErrAcquired))
+		Expect(subject.Ping()).To(Succeed())
+
+		Expect(buf.String()).To(MatchRegexp(`^backend: Acquire ok in \S+\nbackend: Acquire acquired in \S+\nbackend: Ping ok in \S+\n$`))
+	})
+})
+
+var _ = Describe("Tracing", func() {
+	It("should trace calls", func() {
+		recorder := tracetest.NewSpanRecorder()
+		subject := middleware.Tracing(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))(&flakyBackend{Backend: mock.New(), failures: 1})
+
+		Expect(subject.Get(context.Background(), uuid.New())).Error().To(MatchError(errTransient))
+		Expect(subject.Get(context.Background(), uuid.New())).To(BeNil())
+
+		spans := recorder.Ended()
+		Expect(spans).To(HaveLen(2))
+		Expect(spans[0].Name()).To(Equal("accord.backend/Get"))
+		Expect(spans[0].Status().Description).To(Equal(errTransient.Error()))
+		Expect(spans[1].Name()).To(Equal("accord.backend/Get"))
+		Expect(spans[1].Status().Description).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Metrics", func() {
+	It("should record calls", func() {
+		reg := prometheus.NewRegistry()
+		subject := middleware.Metrics(reg)(mock.New())
+
+		_, err := subject.Acquire(context.Background(), "owner", "ns", "name", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = subject.Acquire(context.Background(), "owner", "ns", "name", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).To(Equal(accord.ErrAcquired))
+
+		Expect(testutil.GatherAndCount(reg, "accord_backend_calls_total")).To(Equal(2))
+		Expect(testutil.GatherAndCount(reg, "accord_backend_call_duration_seconds")).To(Equal(1))
+	})
+})
+
+var _ = Describe("Timeout", func() {
+	It("should apply deadlines", func() {
+		var deadline time.Time
+		subject := backend.Chain(mock.New(),
+			middleware.Timeout(time.Minute),
+			middleware.Intercept(func(ctx context.Context, _ string, call func(context.Context) error) error {
+				deadline, _ = ctx.Deadline()
+				return call(ctx)
+			}),
+		)
+
+		Expect(subject.Get(context.Background(), uuid.New())).To(BeNil())
+		Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+	})
+})
+
+var _ = Describe("Retry", func() {
+	var flaky *flakyBackend
+	var subject backend.Backend
+	var ctx = context.Background()
+
+	BeforeEach(func() {
+		flaky = &flakyBackend{Backend: mock.New()}
+		subject = middleware.Retry(&middleware.RetryOptions{
+			Attempts:    3,
+			Backoff:     time.Millisecond,
+			IsTransient: func(err error) bool { return err == errTransient },
+		})(flaky)
+	})
+
+	It("should retry idempotent calls", func() {
+		flaky.failures = 2
+		Expect(subject.Get(ctx, uuid.New())).To(BeNil())
+		Expect(flaky.calls).To(Equal(3))
+
+		flaky.calls, flaky.failures = 0, 3
+		Expect(subject.Get(ctx, uuid.New())).Error().To(MatchError(errTransient))
+		Expect(flaky.calls).To(Equal(3))
+
+		flaky.calls, flaky.failures = 0, 2
+		Expect(subject.List(ctx, &rpc.ListRequest{}, func(*backend.HandleData) error { return nil })).To(Succeed())
+		Expect(flaky.calls).To(Equal(3))
+
+		flaky.calls, flaky.failures = 0, 1
+		Expect(subject.Ping()).To(Succeed())
+		Expect(flaky.calls).To(Equal(2))
+	})
+
+	It("should not retry non-idempotent calls", func() {
+		flaky.failures = 1
+		_, err := subject.Acquire(ctx, "owner", "ns", "name", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).To(MatchError(errTransient))
+		Expect(flaky.calls).To(Equal(1))
+	})
+
+	It("should not retry lists once started", func() {
+		_, err := flaky.Backend.Acquire(ctx, "owner", "ns", "name", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		flaky.failAfterIter = true
+		flaky.failures = 1
+
+		var seen int
+		Expect(subject.List(ctx, &rpc.ListRequest{}, func(*backend.HandleData) error {
+			seen++
+			return nil
+		})).To(MatchError(errTransient))
+		Expect(flaky.calls).To(Equal(1))
+		Expect(seen).To(Equal(1))
+	})
+
+	It("should detect transient errors", func() {
+		Expect(middleware.IsTransient(nil)).To(BeFalse())
+		Expect(middleware.IsTransient(io.ErrUnexpectedEOF)).To(BeTrue())
+		Expect(middleware.IsTransient(context.Canceled)).To(BeFalse())
+		Expect(middleware.IsTransient(accord.ErrAcquired)).To(BeFalse())
+		Expect(middleware.IsTransient(status.Error(codes.Unavailable, "unavailable"))).To(BeTrue())
+		Expect(middleware.IsTransient(status.Error(codes.Aborted, backend.ErrVersionMismatch.Error()))).To(BeFalse())
+	})
+})
+
+// ------------------------------------------------------------------------
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "accord/backend/middleware")
+}
+
+type transientError struct{}
+
+func (transientError) Error() string { return "transient" }
+
+var errTransient error = transientError{}
+
+// flakyBackend fails the first N calls of every kind.
+type flakyBackend struct {
+	backend.Backend
+	calls, failures int
+	failAfterIter   bool
+}
+
+func (b *flakyBackend) fail() error {
+	b.calls++
+	if b.failures > 0 {
+		b.failures--
+		return errTransient
+	}
+	return nil
+}
+
+func (b *flakyBackend) Acquire(ctx context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string, opt *backend.AcquireOptions) (*backend.HandleData, error) {
+	if err := b.fail(); err != nil {
+		return nil, err
+	}
+	return b.Backend.Acquire(ctx, owner, namespace, name, exp, metadata, opt)
+}
+
+func (b *flakyBackend) Get(ctx context.Context, handleID uuid.UUID) (*backend.HandleData, error) {
+	if err := b.fail(); err != nil {
+		return nil, err
+	}
+	return b.Backend.Get(ctx, handleID)
+}
+
+func (b *flakyBackend) List(ctx context.Context, req *rpc.ListRequest, iter backend.Iterator) error {
+	if b.failAfterIter {
+		if err := b.Backend.List(ctx, req, iter); err != nil {
+			return err
+		}
+		return b.fail()
+	}
+	if err := b.fail(); err != nil {
+		return err
+	}
+	return b.Backend.List(ctx, req, iter)
+}
+
+func (b *flakyBackend) Ping() error {
+	if err := b.fail(); err != nil {
+		return err
+	}
+	return b.Backend.Ping()
+}
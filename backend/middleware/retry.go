@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/rpc"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryOptions contains options for the Retry middleware.
+type RetryOptions struct {
+	// Attempts is the maximum number of attempts, default: 3.
+	Attempts int
+	// Backoff is the initial delay between attempts, it doubles after every
+	// attempt. Default: 50ms.
+	Backoff time.Duration
+	// IsTransient reports if an error is transient. Default: IsTransient.
+	IsTransient func(error) bool
+}
+
+func (o *RetryOptions) norm() *RetryOptions {
+	var p RetryOptions
+	if o != nil {
+		p = *o
+	}
+
+	if p.Attempts < 1 {
+		p.Attempts = 3
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = 50 * time.Millisecond
+	}
+	if p.IsTransient == nil {
+		p.IsTransient = IsTransient
+	}
+	return &p
+}
+
+// Retry retries idempotent calls (Get, ValidateToken, List, Count and Ping) on
+// transient errors.
+// List calls are only retried if the iterator has not been invoked yet.
+func Retry(opt *RetryOptions) backend.Middleware {
+	opt = opt.norm()
+	return func(b backend.Backend) backend.Backend {
+		return &retry{Backend: b, opt: opt}
+	}
+}
+
+type retry struct {
+	backend.Backend
+	opt *RetryOptions
+}
+
+func (r *retry) Get(ctx context.Context, handleID uuid.UUID) (*backend.HandleData, error) {
+	var handle *backend.HandleData
+	err := r.do(ctx, func() (err error) {
+		handle, err = r.Backend.Get(ctx, handleID)
+		return
+	}, nil)
+	return handle, err
+}
+
+func (r *retry) ValidateToken(ctx context.Context, handleID uuid.UUID, token int64) error {
+	return r.do(ctx, func() error {
+		return r.Backend.ValidateToken(ctx, handleID, token)
+	}, nil)
+}
+
+func (r *retry) List(ctx context.Context, req *rpc.ListRequest, iter backend.Iterator) error {
+	var started bool
+	return r.do(ctx, func() error {
+		return r.Backend.List(ctx, req, func(h *backend.HandleData) error {
+			started = true
+			return iter(h)
+		})
+	}, func() bool { return !started })
+}
+
+func (r *retry) Count(ctx context.Context, filter *rpc.ListRequest_Filter) (int64, error) {
+	var num int64
+	err := r.do(ctx, func() (err error) {
+		num, err = r.Backend.Count(ctx, filter)
+		return
+	}, nil)
+	return num, err
+}
+
+func (r *retry) Ping() error {
+	return r.do(context.Background(), r.Backend.Ping, nil)
+}
+
+func (r *retry) do(ctx context.Context, fn func() error, canRetry func() bool) error {
+	backoff := r.opt.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= r.opt.Attempts || !r.opt.IsTransient(err) {
+			return err
+		} else if canRetry != nil && !canRetry() {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+			backoff *= 2
+		}
+	}
+}
+
+// IsTransient returns true for errors which are usually temporary, such as
+// dropped connections, network timeouts or unavailable gRPC services.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.ResourceExhausted:
+			return true
+		}
+	}
+	return false
+}
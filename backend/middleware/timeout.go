@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/bsm/accord/backend"
+)
+
+// Timeout enforces a maximum duration for every backend call by applying
+// a context deadline. Ping calls are not context-aware and can therefore
+// only be limited by the backend itself.
+func Timeout(d time.Duration) backend.Middleware {
+	return Intercept(func(ctx context.Context, _ string, call func(context.Context) error) error {
+		ctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		return call(ctx)
+	})
+}
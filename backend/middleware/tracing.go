@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/bsm/accord/backend"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/bsm/accord/backend"
+
+// Tracing creates an OpenTelemetry span for every backend call.
+// Uses the global otel.GetTracerProvider() if provider is nil.
+func Tracing(provider trace.TracerProvider) backend.Middleware {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	tracer := provider.Tracer(tracerName)
+
+	return Intercept(func(ctx context.Context, method string, call func(context.Context) error) error {
+		ctx, span := tracer.Start(ctx, "accord.backend/"+method, trace.WithSpanKind(trace.SpanKindClient))
+		defer span.End()
+
+		err := call(ctx)
+		span.SetAttributes(attribute.String("accord.outcome", outcome(err)))
+		if isFailure(err) {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	})
+}
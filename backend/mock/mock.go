@@ -3,7 +3,7 @@ package mock
 
 import (
 	"context"
-	"strings"
+	"maps"
 	"sync"
 	"time"
 
@@ -21,16 +21,17 @@ type fullName struct {
 
 // Backend implements a mock backend.
 type Backend struct {
-	byName map[fullName]*backend.HandleData
+	byName map[fullName][]*backend.HandleData // indexed by slot
 	byID   map[uuid.UUID]*backend.HandleData
 	asList []*backend.HandleData
+	tokens int64
 	mu     sync.RWMutex
 }
 
 // New opens a mock backend
 func New() *Backend {
 	return &Backend{
-		byName: make(map[fullName]*backend.HandleData),
+		byName: make(map[fullName][]*backend.HandleData),
 		byID:   make(map[uuid.UUID]*backend.HandleData),
 	}
 }
@@ -43,21 +44,16 @@ func (b *Backend) Get(_ context.Context, handleID uuid.UUID) (*backend.HandleDat
 	return stored, nil
 }
 
+// ValidateToken implements the backend.Backend interface.
+func (b *Backend) ValidateToken(ctx context.Context, handleID uuid.UUID, token int64) error {
+	handle, _ := b.Get(ctx, handleID)
+	return backend.ValidateFencingToken(handle, token)
+}
+
 // Acquire implements the backend.Backend interface.
-func (b *Backend) Acquire(_ context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string) (*backend.HandleData, error) {
+func (b *Backend) Acquire(_ context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string, opt *backend.AcquireOptions) (*backend.HandleData, error) {
 	key := fullName{Namespace: namespace, Name: name}
 	now := time.Now()
-
-	b.mu.RLock()
-	stored, ok := b.byName[key]
-	b.mu.RUnlock()
-
-	if ok && stored.IsDone() {
-		return nil, accord.ErrDone
-	} else if ok && !stored.ExpTime.Before(now) {
-		return nil, accord.ErrAcquired
-	}
-
 	handle := &backend.HandleData{
 		ID:          uuid.New(),
 		Namespace:   namespace,
@@ -65,101 +61,289 @@ func (b *Backend) Acquire(_ context.Context, owner, namespace, name string, exp
 		ExpTime:     exp,
 		NumAcquired: 1,
 		Owner:       owner,
-		Metadata:    metadata,
+		CreatedTime: now,
+		UpdatedTime: now,
+		Metadata:    maps.Clone(metadata),
+		MetaVersion: 1,
+		Capacity:    opt.GetCapacity(),
+		Mode:        opt.GetMode(),
 	}
 
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if stored, ok := b.byName[key]; ok && stored.IsDone() {
-		return nil, accord.ErrDone
-	} else if ok && !stored.ExpTime.Before(now) {
-		return nil, accord.ErrAcquired
-	} else if ok {
+	slot, queued, err := backend.SelectSlot(b.byName[key], owner, opt, now)
+	if err != nil {
+		return nil, err
+	}
+
+	handle.Slot = slot
+	handle.Queued = queued
+	if stored := b.slot(key, slot); stored != nil {
 		handle.NumAcquired = stored.NumAcquired + 1
+		handle.PreviousOwner = stored.Owner
+		handle.CreatedTime = stored.CreatedTime
 		handle.UpdateMetadata(stored.Metadata)
+		handle.MetaVersion = stored.MetaVersion
+		if !maps.Equal(handle.Metadata, stored.Metadata) {
+			handle.MetaVersion++
+		}
+		b.replace(stored, handle)
+	} else {
+		b.asList = append(b.asList, handle)
 	}
 
+	b.tokens++
+	handle.FencingToken = b.tokens
 	b.byID[handle.ID] = handle
-	b.byName[key] = handle
-	b.asList = append(b.asList, handle)
+	b.store(key, handle)
 
+	if handle.Queued {
+		return nil, accord.ErrAcquired
+	}
 	return handle, nil
 }
 
 // Renew implements the backend.Backend interface.
-func (b *Backend) Renew(_ context.Context, owner string, handleID uuid.UUID, exp time.Time, metadata map[string]string) error {
+func (b *Backend) Renew(_ context.Context, owner string, handleID uuid.UUID, exp time.Time, metadata map[string]string, opt *backend.UpdateOptions) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	if stored, ok := b.byID[handleID]; !ok || stored.IsDone() || stored.Owner != owner {
 		return backend.ErrInvalidHandle
+	} else if err := stored.ApplyUpdate(metadata, opt); err != nil {
+		return err
 	} else {
-		stored.UpdateMetadata(metadata)
 		stored.ExpTime = exp
+		stored.UpdatedTime = time.Now()
 	}
 	return nil
 }
 
 // Done implements the backend.Backend interface.
-func (b *Backend) Done(_ context.Context, owner string, handleID uuid.UUID, metadata map[string]string) error {
+func (b *Backend) Done(_ context.Context, owner string, handleID uuid.UUID, metadata map[string]string, opt *backend.UpdateOptions) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	if stored, ok := b.byID[handleID]; !ok || stored.IsDone() || stored.Owner != owner {
 		return backend.ErrInvalidHandle
+	} else if err := stored.ApplyUpdate(metadata, opt); err != nil {
+		return err
 	} else {
-		stored.UpdateMetadata(metadata)
 		stored.DoneTime = time.Now()
+		stored.UpdatedTime = stored.DoneTime
 	}
 	return nil
 }
 
+// Transfer implements the backend.Backend interface.
+func (b *Backend) Transfer(_ context.Context, owner string, handleID uuid.UUID, newOwner string, rotateID bool) (*backend.HandleData, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	stored, ok := b.byID[handleID]
+	if !ok || !stored.IsHeld(now) || stored.Owner != owner {
+		return nil, backend.ErrInvalidHandle
+	}
+
+	handle := *stored
+	handle.Metadata = maps.Clone(stored.Metadata)
+	if rotateID {
+		handle.ID = uuid.New()
+	}
+	b.tokens++
+	handle.PreviousOwner = stored.Owner
+	handle.Owner = newOwner
+	handle.UpdatedTime = now
+	handle.FencingToken = b.tokens
+
+	b.replace(stored, &handle)
+	b.byID[handle.ID] = &handle
+	b.store(fullName{Namespace: handle.Namespace, Name: handle.Name}, &handle)
+	return &handle, nil
+}
+
 // List implements the backend.Backend interface.
 func (b *Backend) List(_ context.Context, req *rpc.ListRequest, iter backend.Iterator) error {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	filter := req.GetFilter()
-	for i := len(b.asList) - 1 - int(req.GetOffset()); i >= 0; i-- {
-		if handle := b.asList[i]; filter == nil || isSelected(filter, handle) {
-			if err := iter(handle); err == backend.ErrIteratorDone {
+	cursor, err := backend.ParseListCursor(req)
+	if err != nil {
+		return err
+	}
+
+	filter, err := backend.ParseListFilter(req.GetFilter())
+	if err != nil {
+		return err
+	}
+
+	var handles []*backend.HandleData
+	for i := len(b.asList) - 1; i >= 0; i-- {
+		if handle := b.asList[i]; cursor.Precedes(handle) && filter.Matches(handle) {
+			handles = append(handles, handle)
+		}
+	}
+	backend.SortHandles(handles, req)
+
+	offset := req.GetOffset()
+	limit := req.GetLimit()
+	for _, handle := range handles {
+		if offset != 0 {
+			offset--
+			continue
+		}
+		if err := iter(handle); err == backend.ErrIteratorDone {
+			break
+		} else if err != nil {
+			return err
+		}
+		if limit != 0 {
+			if limit--; limit == 0 {
 				break
-			} else if err != nil {
-				return err
 			}
 		}
 	}
 	return nil
 }
 
-// Ping implements the backend.Backend interface.
-func (*Backend) Ping() error { return nil }
+// Count implements the backend.Backend interface.
+func (b *Backend) Count(_ context.Context, req *rpc.ListRequest_Filter) (int64, error) {
+	filter, err := backend.ParseListFilter(req)
+	if err != nil {
+		return 0, err
+	}
 
-// Close implements the backend.Backend interface.
-func (*Backend) Close() error { return nil }
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 
-func isSelected(filter *rpc.ListRequest_Filter, handle *backend.HandleData) bool {
-	if filter.Status == rpc.ListRequest_Filter_DONE && !handle.IsDone() {
-		return false
-	} else if filter.Status == rpc.ListRequest_Filter_PENDING && handle.IsDone() {
-		return false
+	var num int64
+	for _, handle := range b.asList {
+		if filter.Matches(handle) {
+			num++
+		}
 	}
+	return num, nil
+}
+
+// MarkDone implements the backend.Backend interface.
+func (b *Backend) MarkDone(_ context.Context, records []backend.DoneRecord) ([]error, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	errs := make([]error, len(records))
+	for i, rec := range records {
+		key := fullName{Namespace: rec.Namespace, Name: rec.Name}
+		if stored := b.slot(key, 0); stored != nil && stored.IsDone() {
+			errs[i] = accord.ErrDone
+			continue
+		} else if stored != nil {
+			errs[i] = accord.ErrAcquired
+			continue
+		}
+
+		doneTime := rec.DoneTime
+		if doneTime.IsZero() {
+			doneTime = now
+		}
 
-	if filter.Prefix != "" && !strings.HasPrefix(handle.Namespace, filter.Prefix) {
-		return false
+		handle := &backend.HandleData{
+			ID:          uuid.New(),
+			Namespace:   rec.Namespace,
+			Name:        rec.Name,
+			Owner:       rec.Owner,
+			CreatedTime: now,
+			UpdatedTime: now,
+			ExpTime:     doneTime,
+			DoneTime:    doneTime,
+			NumAcquired: 1,
+			MetaVersion: 1,
+			Capacity:    1,
+		}
+		handle.UpdateMetadata(rec.Metadata)
+		b.tokens++
+		handle.FencingToken = b.tokens
+
+		b.asList = append(b.asList, handle)
+		b.byID[handle.ID] = handle
+		b.store(key, handle)
 	}
+	return errs, nil
+}
+
+// Purge implements the backend.Backend interface.
+func (b *Backend) Purge(_ context.Context, filter *backend.PurgeFilter) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 
-	if len(filter.Metadata) != 0 {
-		if len(handle.Metadata) == 0 {
-			return false
+	var num int64
+	retained := b.asList[:0]
+	for _, handle := range b.asList {
+		if !filter.Matches(handle) {
+			retained = append(retained, handle)
+			continue
 		}
-		for k, v := range filter.Metadata {
-			if handle.Metadata[k] != v {
-				return false
-			}
+
+		delete(b.byID, handle.ID)
+		b.unstore(fullName{Namespace: handle.Namespace, Name: handle.Name}, handle.Slot)
+		num++
+	}
+	for i := len(retained); i < len(b.asList); i++ {
+		b.asList[i] = nil
+	}
+	b.asList = retained
+	return num, nil
+}
+
+// replace replaces a stored handle, retaining its position.
+func (b *Backend) replace(stored, handle *backend.HandleData) {
+	delete(b.byID, stored.ID)
+	for i, h := range b.asList {
+		if h == stored {
+			b.asList[i] = handle
+			break
 		}
 	}
+}
+
+// slot returns the handle stored in a slot of the resource, if any.
+func (b *Backend) slot(key fullName, slot int) *backend.HandleData {
+	if slots := b.byName[key]; slot < len(slots) {
+		return slots[slot]
+	}
+	return nil
+}
+
+// store stores the handle in its slot.
+func (b *Backend) store(key fullName, handle *backend.HandleData) {
+	slots := b.byName[key]
+	for len(slots) <= handle.Slot {
+		slots = append(slots, nil)
+	}
+	slots[handle.Slot] = handle
+	b.byName[key] = slots
+}
 
-	return true
+// unstore clears a slot of the resource.
+func (b *Backend) unstore(key fullName, slot int) {
+	slots := b.byName[key]
+	if slot < len(slots) {
+		slots[slot] = nil
+	}
+	for len(slots) != 0 && slots[len(slots)-1] == nil {
+		slots = slots[:len(slots)-1]
+	}
+	if len(slots) == 0 {
+		delete(b.byName, key)
+	} else {
+		b.byName[key] = slots
+	}
 }
+
+// Ping implements the backend.Backend interface.
+func (*Backend) Ping() error { return nil }
+
+// Close implements the backend.Backend interface.
+func (*Backend) Close() error { return nil }
@@ -0,0 +1,230 @@
+// Package ndjson exports and imports backend state as newline-delimited
+// JSON, one versioned Record per line. It works with any
+// backend.Backend, e.g. to back up state or to move it between backends:
+//
+//	n, err := ndjson.Export(ctx, src, w, &rpc.ListRequest_Filter{Prefix: "reports/"})
+//	stats, err := ndjson.Import(ctx, dst, r, nil)
+//
+// Imports use the regular backend API, done records are marked as done in
+// bulk and pending records are acquired by their original owner until their
+// original expiration time. Handle IDs, creation times, previous owners,
+// acquisition counts and fencing tokens are not preserved. Released
+// semaphore slots and shared handles as well as queued claims are skipped.
+// Records of unknown format versions are rejected.
+package ndjson
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/rpc"
+	"github.com/google/uuid"
+)
+
+// importBatchSize limits the number of done records per MarkDone call.
+const importBatchSize = 500
+
+// Version is the current record format version.
+const Version = 1
+
+// ErrUnsupportedVersion is returned when importing records of an unknown
+// format version.
+var ErrUnsupportedVersion = errors.New("ndjson: unsupported record version")
+
+// Record is the serialized form of a handle.
+type Record struct {
+	Version       int               `json:"v"`
+	ID            uuid.UUID         `json:"id"`
+	Namespace     string            `json:"namespace,omitempty"`
+	Name          string            `json:"name"`
+	Owner         string            `json:"owner"`
+	PreviousOwner string            `json:"previous_owner,omitempty"`
+	CreatedTime   time.Time         `json:"created_at"`
+	UpdatedTime   time.Time         `json:"updated_at"`
+	ExpTime       time.Time         `json:"expires_at"`
+	DoneTime      *time.Time        `json:"done_at,omitempty"`
+	NumAcquired   int               `json:"num_acquired"`
+	Metadata      map[string]string `json:"metadata,omitempty"`
+	MetaVersion   int64             `json:"metadata_version"`
+	FencingToken  int64             `json:"fencing_token"`
+	Slot          int               `json:"slot,omitempty"`
+	Capacity      int               `json:"capacity"`
+	Mode          string            `json:"mode"`
+	Queued        bool              `json:"queued,omitempty"`
+}
+
+func newRecord(h *backend.HandleData) *Record {
+	rec := &Record{
+		Version:       Version,
+		ID:            h.ID,
+		Namespace:     h.Namespace,
+		Name:          h.Name,
+		Owner:         h.Owner,
+		PreviousOwner: h.PreviousOwner,
+		CreatedTime:   h.CreatedTime,
+		UpdatedTime:   h.UpdatedTime,
+		ExpTime:       h.ExpTime,
+		NumAcquired:   h.NumAcquired,
+		Metadata:      h.Metadata,
+		MetaVersion:   h.MetaVersion,
+		FencingToken:  h.FencingToken,
+		Slot:          h.Slot,
+		Capacity:      h.Capacity,
+		Mode:          strings.ToLower(h.Mode.String()),
+		Queued:        h.Queued,
+	}
+	if h.IsDone() {
+		doneTime := h.DoneTime
+		rec.DoneTime = &doneTime
+	}
+	return rec
+}
+
+func (r *Record) handleData() (*backend.HandleData, error) {
+	if r.Version != Version {
+		return nil, fmt.Errorf("%w %d", ErrUnsupportedVersion, r.Version)
+	}
+
+	mode, ok := rpc.Mode_value[strings.ToUpper(r.Mode)]
+	if !ok {
+		return nil, fmt.Errorf("ndjson: invalid mode %q", r.Mode)
+	}
+
+	h := &backend.HandleData{
+		ID:            r.ID,
+		Namespace:     r.Namespace,
+		Name:          r.Name,
+		Owner:         r.Owner,
+		PreviousOwner: r.PreviousOwner,
+		CreatedTime:   r.CreatedTime,
+		UpdatedTime:   r.UpdatedTime,
+		ExpTime:       r.ExpTime,
+		NumAcquired:   r.NumAcquired,
+		Metadata:      r.Metadata,
+		MetaVersion:   r.MetaVersion,
+		FencingToken:  r.FencingToken,
+		Slot:          r.Slot,
+		Capacity:      r.Capacity,
+		Mode:          rpc.Mode(mode),
+		Queued:        r.Queued,
+	}
+	if r.DoneTime != nil {
+		h.DoneTime = *r.DoneTime
+	}
+	return h, nil
+}
+
+// Export writes all handles matching the filter to w, oldest first, and
+// returns the number of exported handles.
+func Export(ctx context.Context, b backend.Backend, w io.Writer, filter *rpc.ListRequest_Filter) (int64, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	var num int64
+	if err := b.List(ctx, &rpc.ListRequest{
+		Filter: filter,
+		Sort:   rpc.ListRequest_CREATED,
+		Order:  rpc.ListRequest_ASC,
+	}, func(h *backend.HandleData) error {
+		if err := enc.Encode(newRecord(h)); err != nil {
+			return err
+		}
+		num++
+		return nil
+	}); err != nil {
+		return num, err
+	}
+	return num, bw.Flush()
+}
+
+// ImportStats contains import statistics.
+type ImportStats struct {
+	Imported  int64 // number of imported handles
+	Conflicts int64 // number of handles which already existed
+}
+
+// Import reads handles from r and imports the ones matching the filter into
+// the backend. Handles which already exist are counted as conflicts.
+func Import(ctx context.Context, b backend.Backend, r io.Reader, filter *rpc.ListRequest_Filter) (*ImportStats, error) {
+	f, err := backend.ParseListFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := new(ImportStats)
+	batch := make([]backend.DoneRecord, 0, importBatchSize)
+	flush := func() error {
+		errs, err := b.MarkDone(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for _, err := range errs {
+			if err == nil {
+				stats.Imported++
+			} else {
+				stats.Conflicts++
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for {
+		var rec Record
+		if err := dec.Decode(&rec); err == io.EOF {
+			break
+		} else if err != nil {
+			return stats, err
+		}
+
+		h, err := rec.handleData()
+		if err != nil {
+			return stats, err
+		}
+
+		if !f.Matches(h) || h.Queued || (h.IsDone() && !h.IsResourceDone()) {
+			continue
+		}
+
+		if h.IsDone() {
+			batch = append(batch, backend.DoneRecord{
+				Owner:     h.Owner,
+				Namespace: h.Namespace,
+				Name:      h.Name,
+				DoneTime:  h.DoneTime,
+				Metadata:  h.Metadata,
+			})
+			if len(batch) == importBatchSize {
+				if err := flush(); err != nil {
+					return stats, err
+				}
+			}
+			continue
+		}
+
+		_, err = b.Acquire(ctx, h.Owner, h.Namespace, h.Name, h.ExpTime, h.Metadata, &backend.AcquireOptions{Capacity: h.Capacity, Mode: h.Mode})
+		if err == accord.ErrAcquired || err == accord.ErrDone {
+			stats.Conflicts++
+		} else if err != nil {
+			return stats, err
+		} else {
+			stats.Imported++
+		}
+	}
+
+	if len(batch) != 0 {
+		if err := flush(); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,115 @@
+package ndjson_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/backend/mock"
+	"github.com/bsm/accord/backend/ndjson"
+	"github.com/bsm/accord/rpc"
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+)
+
+var _ = Describe("Export/Import", func() {
+	var src, dst *mock.Backend
+	var ctx = context.Background()
+
+	BeforeEach(func() {
+		src = mock.New()
+		dst = mock.New()
+
+		for _, name := range []string{"r1", "r2", "r3"} {
+			h, err := src.Acquire(ctx, "THEOWNER", "ns", name, time.Now().Add(time.Minute), map[string]string{"k": name}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			if name != "r2" {
+				Expect(src.Done(ctx, "THEOWNER", h.ID, nil, nil)).To(Succeed())
+			}
+		}
+		_, err := src.Acquire(ctx, "THEOWNER", "other", "r4", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	listAll := func(b backend.Backend) []*backend.HandleData {
+		var handles []*backend.HandleData
+		Expect(b.List(ctx, &rpc.ListRequest{Sort: rpc.ListRequest_NAME, Order: rpc.ListRequest_ASC}, func(h *backend.HandleData) error {
+			handles = append(handles, h)
+			return nil
+		})).To(Succeed())
+		return handles
+	}
+
+	It("should export", func() {
+		buf := new(bytes.Buffer)
+		Expect(ndjson.Export(ctx, src, buf, &rpc.ListRequest_Filter{Namespace: "ns"})).To(Equal(int64(3)))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		Expect(lines).To(HaveLen(3))
+		Expect(lines[0]).To(HavePrefix(`{"v":1,`))
+		Expect(lines[0]).To(ContainSubstring(`"name":"r1"`))
+		Expect(lines[0]).To(ContainSubstring(`"mode":"exclusive"`))
+		Expect(lines[0]).To(ContainSubstring(`"done_at":`))
+		Expect(lines[1]).NotTo(ContainSubstring(`"done_at":`))
+		Expect(lines[2]).To(ContainSubstring(`"name":"r3"`))
+	})
+
+	It("should round-trip", func() {
+		buf := new(bytes.Buffer)
+		Expect(ndjson.Export(ctx, src, buf, nil)).To(Equal(int64(4)))
+		Expect(ndjson.Import(ctx, dst, buf, nil)).To(Equal(&ndjson.ImportStats{Imported: 4}))
+
+		expected, actual := listAll(src), listAll(dst)
+		Expect(actual).To(HaveLen(len(expected)))
+		for i, h := range actual {
+			Expect(h.Namespace).To(Equal(expected[i].Namespace))
+			Expect(h.Name).To(Equal(expected[i].Name))
+			Expect(h.Owner).To(Equal(expected[i].Owner))
+			Expect(h.IsDone()).To(Equal(expected[i].IsDone()))
+			Expect(h.DoneTime).To(BeTemporally("==", expected[i].DoneTime))
+			if !h.IsDone() {
+				Expect(h.ExpTime).To(BeTemporally("==", expected[i].ExpTime))
+			}
+			Expect(h.Metadata).To(Equal(expected[i].Metadata))
+		}
+
+		_, err := dst.Acquire(ctx, "OTHERONE", "ns", "r1", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).To(Equal(accord.ErrDone))
+		_, err = dst.Acquire(ctx, "OTHERONE", "ns", "r2", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).To(Equal(accord.ErrAcquired))
+	})
+
+	It("should import with filters and report conflicts", func() {
+		buf := new(bytes.Buffer)
+		Expect(ndjson.Export(ctx, src, buf, nil)).To(Equal(int64(4)))
+
+		Expect(ndjson.Import(ctx, src, bytes.NewReader(buf.Bytes()), nil)).To(Equal(&ndjson.ImportStats{Conflicts: 4}))
+		Expect(ndjson.Import(ctx, dst, bytes.NewReader(buf.Bytes()), &rpc.ListRequest_Filter{
+			Namespace: "ns",
+			Status:    rpc.ListRequest_Filter_DONE,
+		})).To(Equal(&ndjson.ImportStats{Imported: 2}))
+		Expect(listAll(dst)).To(HaveLen(2))
+	})
+
+	It("should reject invalid input", func() {
+		_, err := ndjson.Import(ctx, dst, strings.NewReader("{\"v\":1,\"name\":\"r1\",\"mode\":\"exclusive\"}\nnot json\n"), nil)
+		Expect(err).To(MatchError(ContainSubstring("invalid character")))
+
+		_, err = ndjson.Import(ctx, dst, strings.NewReader("{\"v\":2,\"name\":\"r1\"}\n"), nil)
+		Expect(err).To(MatchError(ndjson.ErrUnsupportedVersion))
+
+		_, err = ndjson.Import(ctx, dst, strings.NewReader("{\"v\":1,\"name\":\"r1\",\"mode\":\"bogus\"}\n"), nil)
+		Expect(err).To(MatchError(`ndjson: invalid mode "bogus"`))
+	})
+})
+
+// ------------------------------------------------------------------------
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "accord/backend/ndjson")
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/rpc"
+	"github.com/google/uuid"
+)
+
+// acquireUnlessArchived runs the acquire query in a transaction and rejects
+// newly inserted handles if the name has already been archived.
+func (b *postgres) acquireUnlessArchived(ctx context.Context, query string, args []interface{}, owner string, handleID uuid.UUID) (*backend.HandleData, error) {
+	tx, err := b.conn.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.rollback(ctx) }()
+
+	handle, err := scanHandle(tx.queryRow(ctx, query, args...))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAcquired(handle, owner, handleID); err != nil {
+		return nil, err
+	}
+
+	// only freshly inserted and shared handles may clash with the archive
+	if handle.NumAcquired == 1 || handle.Mode == rpc.Mode_SHARED {
+		var archived bool
+		if err := tx.queryRow(ctx, b.tables.expand(`
+			SELECT EXISTS (SELECT 1 FROM {resource_handles_done} WHERE namespace = $1 AND name = $2)
+		`), handle.Namespace, handle.Name).Scan(&archived); err != nil {
+			return nil, err
+		}
+		if archived {
+			return nil, accord.ErrDone
+		}
+	}
+
+	if err := tx.commit(ctx); err != nil {
+		return nil, err
+	}
+	return handle, nil
+}
+
+// doneAndArchive marks a handle as done and moves it to the archive table.
+// Semaphore slots and shared handles are released in place using the done
+// statement instead.
+func (b *postgres) doneAndArchive(ctx context.Context, done sq.UpdateBuilder, owner string, handleID uuid.UUID, metadata map[string]string, opt *backend.UpdateOptions) error {
+	now := time.Now().UTC()
+	updated := metaExpr(metadata, opt)
+	query, args, err := sq.Expr(b.tables.expand(`
+		WITH moved AS (
+			DELETE FROM {resource_handles}
+			WHERE id = ? AND owner = ? AND done_at IS NULL AND capacity = 1 AND mode = ?
+				AND (?::bigint = 0 OR metadata_version = ?)
+				AND (?::bigint = 0 OR fencing_token = ?)
+			RETURNING id, namespace, name, owner, previous_owner, created_at, expires_at, num_acquired, metadata, metadata_version, fencing_token
+		)
+		INSERT INTO {resource_handles_done} (id, namespace, name, owner, previous_owner, created_at, expires_at, done_at, num_acquired, metadata, metadata_version, fencing_token, updated_at)
+		SELECT id, namespace, name, owner, previous_owner, created_at, expires_at, ?, num_acquired, ?, ?, fencing_token, ?
+		FROM moved
+	`), handleID, owner, int32(rpc.Mode_EXCLUSIVE),
+		opt.GetExpectedVersion(), opt.GetExpectedVersion(),
+		opt.GetFencingToken(), opt.GetFencingToken(),
+		now, updated, metaVersionExpr(updated), now,
+	).ToSql()
+	if err != nil {
+		return err
+	}
+	if query, err = sq.Dollar.ReplacePlaceholders(query); err != nil {
+		return err
+	}
+
+	num, err := b.conn.execPrepared(ctx, query, args...)
+	if err != nil {
+		return err
+	} else if num == 0 {
+		return b.performUpdate(ctx, done.Where(sq.Or{sq.Gt{"capacity": 1}, sq.Eq{"mode": int32(rpc.Mode_SHARED)}}), owner, handleID, opt)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// conn abstracts database/sql and pgx connection pools.
+type conn interface {
+	// exec executes a query and returns the number of affected rows.
+	exec(ctx context.Context, query string, args ...interface{}) (int64, error)
+	// execPrepared is like exec but uses a cached prepared statement.
+	execPrepared(ctx context.Context, query string, args ...interface{}) (int64, error)
+	// queryRowPrepared queries a single row using a cached prepared statement.
+	queryRowPrepared(ctx context.Context, query string, args ...interface{}) sq.RowScanner
+	queryRow(ctx context.Context, query string, args ...interface{}) sq.RowScanner
+	query(ctx context.Context, query string, args ...interface{}) (rows, error)
+	begin(ctx context.Context) (tx, error)
+	ping(ctx context.Context) error
+	close() error
+}
+
+type tx interface {
+	exec(ctx context.Context, query string, args ...interface{}) (int64, error)
+	queryRow(ctx context.Context, query string, args ...interface{}) sq.RowScanner
+	query(ctx context.Context, query string, args ...interface{}) (rows, error)
+	commit(ctx context.Context) error
+	rollback(ctx context.Context) error
+}
+
+type rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+	Close()
+}
+
+// --------------------------------------------------------------------
+
+type sqlConn struct {
+	db    *sql.DB
+	stmts sync.Map // map[string]*sql.Stmt
+}
+
+func (c *sqlConn) exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	res, err := c.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (c *sqlConn) execPrepared(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	stmt, err := c.prepare(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := stmt.ExecContext(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (c *sqlConn) queryRowPrepared(ctx context.Context, query string, args ...interface{}) sq.RowScanner {
+	stmt, err := c.prepare(ctx, query)
+	if err != nil {
+		return errRow{err: err}
+	}
+	return stmt.QueryRowContext(ctx, args...)
+}
+
+func (c *sqlConn) queryRow(ctx context.Context, query string, args ...interface{}) sq.RowScanner {
+	return c.db.QueryRowContext(ctx, query, args...)
+}
+
+func (c *sqlConn) query(ctx context.Context, query string, args ...interface{}) (rows, error) {
+	rs, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return sqlRows{Rows: rs}, nil
+}
+
+func (c *sqlConn) begin(ctx context.Context) (tx, error) {
+	t, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return sqlTx{Tx: t}, nil
+}
+
+func (c *sqlConn) ping(ctx context.Context) error { return c.db.PingContext(ctx) }
+
+func (c *sqlConn) close() error {
+	var err error
+	c.stmts.Range(func(key, value interface{}) bool {
+		if e2 := value.(*sql.Stmt).Close(); e2 != nil {
+			err = e2
+		}
+		c.stmts.Delete(key)
+		return true
+	})
+	return err
+}
+
+func (c *sqlConn) prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	if v, ok := c.stmts.Load(query); ok {
+		return v.(*sql.Stmt), nil
+	}
+
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if v, loaded := c.stmts.LoadOrStore(query, stmt); loaded {
+		_ = stmt.Close()
+		return v.(*sql.Stmt), nil
+	}
+	return stmt, nil
+}
+
+type sqlRows struct{ *sql.Rows }
+
+func (r sqlRows) Close() { _ = r.Rows.Close() }
+
+type sqlTx struct{ *sql.Tx }
+
+func (t sqlTx) exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	res, err := t.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (t sqlTx) queryRow(ctx context.Context, query string, args ...interface{}) sq.RowScanner {
+	return t.QueryRowContext(ctx, query, args...)
+}
+
+func (t sqlTx) query(ctx context.Context, query string, args ...interface{}) (rows, error) {
+	rs, err := t.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return sqlRows{Rows: rs}, nil
+}
+
+func (t sqlTx) commit(_ context.Context) error   { return t.Commit() }
+func (t sqlTx) rollback(_ context.Context) error { return t.Rollback() }
+
+type errRow struct{ err error }
+
+func (r errRow) Scan(...interface{}) error { return r.err }
+
+// --------------------------------------------------------------------
+
+type pgxConn struct {
+	pool *pgxpool.Pool
+}
+
+func (c *pgxConn) exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	tag, err := c.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (c *pgxConn) execPrepared(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return c.exec(ctx, query, prependMode(args)...)
+}
+
+func (c *pgxConn) queryRowPrepared(ctx context.Context, query string, args ...interface{}) sq.RowScanner {
+	return c.queryRow(ctx, query, prependMode(args)...)
+}
+
+func (c *pgxConn) queryRow(ctx context.Context, query string, args ...interface{}) sq.RowScanner {
+	return pgxRow{Row: c.pool.QueryRow(ctx, query, args...)}
+}
+
+func (c *pgxConn) query(ctx context.Context, query string, args ...interface{}) (rows, error) {
+	rs, err := c.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func (c *pgxConn) begin(ctx context.Context) (tx, error) {
+	t, err := c.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pgxTx{Tx: t}, nil
+}
+
+func (c *pgxConn) ping(ctx context.Context) error { return c.pool.Ping(ctx) }
+func (c *pgxConn) close() error                   { return nil }
+
+// prependMode instructs pgx to prepare and cache the statement on each
+// connection, regardless of the pool's default query execution mode.
+func prependMode(args []interface{}) []interface{} {
+	return append([]interface{}{pgx.QueryExecModeCacheStatement}, args...)
+}
+
+// pgxRow translates pgx.ErrNoRows to sql.ErrNoRows.
+type pgxRow struct{ pgx.Row }
+
+func (r pgxRow) Scan(dest ...interface{}) error {
+	if err := r.Row.Scan(dest...); errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+type pgxTx struct{ pgx.Tx }
+
+func (t pgxTx) exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	tag, err := t.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (t pgxTx) queryRow(ctx context.Context, query string, args ...interface{}) sq.RowScanner {
+	return pgxRow{Row: t.QueryRow(ctx, query, args...)}
+}
+
+func (t pgxTx) query(ctx context.Context, query string, args ...interface{}) (rows, error) {
+	rs, err := t.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func (t pgxTx) commit(ctx context.Context) error   { return t.Commit(ctx) }
+func (t pgxTx) rollback(ctx context.Context) error { return t.Rollback(ctx) }
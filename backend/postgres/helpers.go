@@ -1,34 +1,235 @@
 package postgres
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/bsm/accord"
 	"github.com/bsm/accord/backend"
-	"github.com/lib/pq"
+	"github.com/bsm/accord/rpc"
+	"github.com/google/uuid"
 )
 
-func performUpdate(ctx context.Context, stmt sq.UpdateBuilder) error {
-	res, err := stmt.ExecContext(ctx)
+var handleColumns = []string{
+	"id",
+	"namespace",
+	"name",
+	"owner",
+	"previous_owner",
+	"created_at",
+	"updated_at",
+	"expires_at",
+	"num_acquired",
+	"done_at",
+	"metadata",
+	"metadata_version",
+	"fencing_token",
+	"slot",
+	"capacity",
+	"mode",
+	"queued",
+}
+
+func (b *postgres) performUpdate(ctx context.Context, stmt sq.UpdateBuilder, owner string, handleID uuid.UUID, opt *backend.UpdateOptions) error {
+	if v := opt.GetExpectedVersion(); v != 0 {
+		stmt = stmt.Where(sq.Eq{"metadata_version": v})
+	}
+	if t := opt.GetFencingToken(); t != 0 {
+		stmt = stmt.Where(sq.Eq{"fencing_token": t})
+	}
+
+	query, args, err := stmt.ToSql()
 	if err != nil {
 		return err
 	}
 
-	num, err := res.RowsAffected()
+	num, err := b.conn.execPrepared(ctx, query, args...)
 	if err != nil {
 		return err
 	} else if num == 0 {
+		return b.updateError(ctx, owner, handleID, opt)
+	}
+	return nil
+}
+
+// updateError determines why an update did not affect any rows.
+func (b *postgres) updateError(ctx context.Context, owner string, handleID uuid.UUID, opt *backend.UpdateOptions) error {
+	if opt.GetExpectedVersion() == 0 && opt.GetFencingToken() == 0 {
 		return backend.ErrInvalidHandle
 	}
+
+	var version, token int64
+	if err := b.conn.queryRow(ctx, b.tables.expand(`
+		SELECT metadata_version, fencing_token FROM {resource_handles} WHERE id = $1 AND owner = $2 AND done_at IS NULL
+	`), handleID, owner).Scan(&version, &token); err == sql.ErrNoRows {
+		return backend.ErrInvalidHandle
+	} else if err != nil {
+		return err
+	}
+
+	if t := opt.GetFencingToken(); t != 0 && t != token {
+		return backend.ErrStaleToken
+	}
+	if v := opt.GetExpectedVersion(); v != 0 && v != version {
+		return backend.ErrVersionMismatch
+	}
+	return backend.ErrInvalidHandle
+}
+
+// metaExpr returns the updated metadata, deleting keys via the JSONB `-`
+// operator before merging, or replacing the stored metadata entirely.
+func metaExpr(metadata map[string]string, opt *backend.UpdateOptions) sq.Sqlizer {
+	if opt.GetReplaceMeta() {
+		return sq.Expr(`?::jsonb`, metaJSONb(metadata))
+	}
+
+	keys := opt.GetDeleteMeta()
+	expr := "metadata"
+	args := make([]interface{}, 0, len(keys)+1)
+	for _, key := range keys {
+		expr += " - ?::text"
+		args = append(args, key)
+	}
+	return sq.Expr(`((`+expr+`) || ?)`, append(args, metaJSONb(metadata))...)
+}
+
+// metaVersionExpr increments the metadata version if the updated metadata
+// differs from the stored value.
+func metaVersionExpr(updated sq.Sqlizer) sq.Sqlizer {
+	return sq.Expr(`CASE WHEN ? = metadata THEN metadata_version ELSE metadata_version + 1 END`, updated)
+}
+
+// listSelect selects handles from table, using ? placeholders.
+func listSelect(table string, f *backend.ListFilter, cursor *backend.Cursor) sq.SelectBuilder {
+	stmt := sq.Select(handleColumns...).From(table)
+	if cursor != nil {
+		stmt = stmt.Where(`(created_at, namespace COLLATE "C", name COLLATE "C", slot) < (?, ?, ?, ?)`,
+			cursor.CreatedTime.UTC(), cursor.Namespace, cursor.Name, cursor.Slot)
+	}
+	if f == nil {
+		return stmt
+	}
+
+	if f.Status == rpc.ListRequest_Filter_DONE {
+		stmt = stmt.Where(sq.NotEq{"done_at": nil}).Where(sq.Eq{"capacity": 1}).Where(sq.NotEq{"mode": int32(rpc.Mode_SHARED)})
+	} else if f.Status == rpc.ListRequest_Filter_PENDING {
+		stmt = stmt.Where(sq.Eq{"done_at": nil})
+	}
+	if f.Prefix != "" {
+		stmt = stmt.Where(sq.Like{"namespace": likeEscaper.Replace(f.Prefix) + "%"})
+	}
+	if f.Namespace != "" {
+		stmt = stmt.Where(sq.Eq{"namespace": f.Namespace})
+	}
+	if f.NamePrefix != "" {
+		stmt = stmt.Where(sq.Like{"name": likeEscaper.Replace(f.NamePrefix) + "%"})
+	}
+	if f.Owner != "" {
+		stmt = stmt.Where(sq.Eq{"owner": f.Owner})
+	}
+	stmt = whereBetween(stmt, "created_at", f.CreatedAfter, f.CreatedBefore)
+	stmt = whereBetween(stmt, "updated_at", f.UpdatedAfter, f.UpdatedBefore)
+	stmt = whereBetween(stmt, "done_at", f.DoneAfter, f.DoneBefore)
+	if len(f.Metadata) != 0 {
+		metadata, _ := json.Marshal(f.Metadata)
+		stmt = stmt.Where("metadata @> ?", metadata)
+	}
+	for _, req := range f.Selector {
+		stmt = stmt.Where(selectorExpr(req))
+	}
+	return stmt
+}
+
+// whereBetween restricts col to the exclusive time range, zero bounds are
+// ignored.
+func whereBetween(stmt sq.SelectBuilder, col string, after, before time.Time) sq.SelectBuilder {
+	if !after.IsZero() {
+		stmt = stmt.Where(sq.Gt{col: after.UTC()})
+	}
+	if !before.IsZero() {
+		stmt = stmt.Where(sq.Lt{col: before.UTC()})
+	}
+	return stmt
+}
+
+// orderBy returns the ORDER BY clauses for the request's sort key and order.
+func orderBy(req *rpc.ListRequest) []string {
+	dir := " DESC"
+	if req.GetOrder() == rpc.ListRequest_ASC {
+		dir = " ASC"
+	}
+
+	// ties are broken by resource, matching the cursor
+	ties := []string{`namespace COLLATE "C"` + dir, `name COLLATE "C"` + dir, "slot" + dir}
+	switch req.GetSort() {
+	case rpc.ListRequest_UPDATED:
+		return append([]string{"updated_at" + dir}, ties...)
+	case rpc.ListRequest_DONE:
+		return append([]string{"done_at" + dir + " NULLS LAST"}, ties...)
+	case rpc.ListRequest_EXPIRES:
+		return append([]string{"expires_at" + dir}, ties...)
+	case rpc.ListRequest_NAME:
+		return append([]string{`name COLLATE "C"` + dir}, ties...)
+	}
+	return append([]string{"created_at" + dir}, ties...)
+}
+
+// selectorExpr compiles a metadata selector requirement to SQL.
+func selectorExpr(req backend.Requirement) sq.Sqlizer {
+	contains := func(value string) sq.Sqlizer {
+		metadata, _ := json.Marshal(map[string]string{req.Key: value})
+		return sq.Expr("metadata @> ?", metadata)
+	}
+
+	switch req.Operator {
+	case backend.OpExists:
+		return sq.Expr("(metadata->>?::text) IS NOT NULL", req.Key)
+	case backend.OpDoesNotExist:
+		return sq.Expr("(metadata->>?::text) IS NULL", req.Key)
+	case backend.OpEquals:
+		return contains(req.Values[0])
+	case backend.OpNotEquals:
+		return sq.Expr("NOT (?)", contains(req.Values[0]))
+	case backend.OpIn:
+		or := make(sq.Or, 0, len(req.Values))
+		for _, v := range req.Values {
+			or = append(or, contains(v))
+		}
+		return or
+	case backend.OpNotIn:
+		and := make(sq.And, 0, len(req.Values))
+		for _, v := range req.Values {
+			and = append(and, sq.Expr("NOT (?)", contains(v)))
+		}
+		return and
+	case backend.OpHasPrefix:
+		return sq.Expr("(metadata->>?::text) LIKE ?", req.Key, likeEscaper.Replace(req.Values[0])+"%")
+	}
+	return sq.Expr("FALSE")
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func checkAcquired(handle *backend.HandleData, owner string, handleID uuid.UUID) error {
+	if handle.IsDone() {
+		return accord.ErrDone
+	}
+	if handle.Owner != owner || !bytes.Equal(handle.ID[:], handleID[:]) {
+		return accord.ErrAcquired
+	}
 	return nil
 }
 
 func scanHandle(row sq.RowScanner) (*backend.HandleData, error) {
 	var (
-		maybeDone pq.NullTime
+		maybeDone sql.NullTime
 		handle    backend.HandleData
 	)
 	if err := row.Scan(
@@ -36,10 +237,19 @@ func scanHandle(row sq.RowScanner) (*backend.HandleData, error) {
 		&handle.Namespace,
 		&handle.Name,
 		&handle.Owner,
+		&handle.PreviousOwner,
+		&handle.CreatedTime,
+		&handle.UpdatedTime,
 		&handle.ExpTime,
 		&handle.NumAcquired,
 		&maybeDone,
 		(*metaJSONb)(&handle.Metadata),
+		&handle.MetaVersion,
+		&handle.FencingToken,
+		&handle.Slot,
+		&handle.Capacity,
+		(*int32)(&handle.Mode),
+		&handle.Queued,
 	); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,224 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	"github.com/google/uuid"
+)
+
+// markDoneBatchSize limits the number of rows per multi-row INSERT.
+const markDoneBatchSize = 500
+
+var markDoneColumns = []string{
+	"id",
+	"namespace",
+	"name",
+	"owner",
+	"created_at",
+	"updated_at",
+	"expires_at",
+	"done_at",
+	"num_acquired",
+	"metadata",
+}
+
+// MarkDone implements the backend.Backend interface.
+func (b *postgres) MarkDone(ctx context.Context, records []backend.DoneRecord) ([]error, error) {
+	errs := make([]error, len(records))
+	for start := 0; start < len(records); start += markDoneBatchSize {
+		end := start + markDoneBatchSize
+		if end > len(records) {
+			end = len(records)
+		}
+		if err := b.markDone(ctx, records[start:end], errs[start:end]); err != nil {
+			return nil, err
+		}
+	}
+	return errs, nil
+}
+
+// markDone inserts a batch of records in a single transaction and stores
+// conflicts in errs. In archive mode, records are inserted into the live
+// table first, to serialize with concurrent acquires, and then moved to the
+// archive table.
+func (b *postgres) markDone(ctx context.Context, records []backend.DoneRecord, errs []error) error {
+	now := time.Now().UTC()
+	stmt := b.stmt.Insert(b.tables.handles).Columns(markDoneColumns...)
+	for _, rec := range records {
+		doneTime := now
+		if !rec.DoneTime.IsZero() {
+			doneTime = rec.DoneTime.UTC()
+		}
+		stmt = stmt.Values(uuid.New(), rec.Namespace, rec.Name, rec.Owner, now, now, doneTime, doneTime, 1, metaJSONb(rec.Metadata))
+	}
+	stmt = stmt.Suffix(`ON CONFLICT (namespace, name, slot) DO NOTHING RETURNING id, namespace, name`)
+
+	query, args, err := stmt.ToSql()
+	if err != nil {
+		return err
+	}
+
+	tx, err := b.conn.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.rollback(ctx) }()
+
+	inserted := make(map[fullName]uuid.UUID, len(records))
+	if err := scanRows(ctx, tx, query, args, func(rs rows) error {
+		var id uuid.UUID
+		var key fullName
+		if err := rs.Scan(&id, &key.Namespace, &key.Name); err != nil {
+			return err
+		}
+		inserted[key] = id
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// claim inserted names in order, duplicates within the batch conflict
+	var conflicts []fullName
+	claimed := make([]bool, len(records))
+	seen := make(map[fullName]bool, len(records))
+	for i, rec := range records {
+		key := fullName{Namespace: rec.Namespace, Name: rec.Name}
+		if _, ok := inserted[key]; ok && !seen[key] {
+			claimed[i] = true
+		} else {
+			conflicts = append(conflicts, key)
+		}
+		seen[key] = true
+	}
+
+	if len(conflicts) != 0 {
+		status, err := b.conflictStatus(ctx, tx, conflicts)
+		if err != nil {
+			return err
+		}
+		for i, rec := range records {
+			if !claimed[i] {
+				errs[i] = status[fullName{Namespace: rec.Namespace, Name: rec.Name}]
+			}
+		}
+	}
+
+	if b.archive && len(inserted) != 0 {
+		archived, err := b.archiveInserted(ctx, tx, inserted)
+		if err != nil {
+			return err
+		}
+		for i, rec := range records {
+			if claimed[i] && !archived[fullName{Namespace: rec.Namespace, Name: rec.Name}] {
+				errs[i] = accord.ErrDone
+			}
+		}
+	}
+
+	return tx.commit(ctx)
+}
+
+// conflictStatus looks up existing handles and returns accord.ErrDone or
+// accord.ErrAcquired for each name.
+func (b *postgres) conflictStatus(ctx context.Context, tx tx, names []fullName) (map[fullName]error, error) {
+	query, args, err := b.stmt.
+		Select("namespace", "name", "done_at IS NOT NULL").
+		From(b.tables.handles).
+		Where(nameTuples(names)).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	// handles can only disappear once done, i.e. when purged or archived
+	status := make(map[fullName]error, len(names))
+	for _, key := range names {
+		status[key] = accord.ErrDone
+	}
+	if err := scanRows(ctx, tx, query, args, func(rs rows) error {
+		var key fullName
+		var done bool
+		if err := rs.Scan(&key.Namespace, &key.Name, &done); err != nil {
+			return err
+		}
+		if !done {
+			status[key] = accord.ErrAcquired
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// archiveInserted moves inserted handles to the archive table and returns
+// the archived names. Names which have been archived before are skipped.
+func (b *postgres) archiveInserted(ctx context.Context, tx tx, inserted map[fullName]uuid.UUID) (map[fullName]bool, error) {
+	ids := make([]uuid.UUID, 0, len(inserted))
+	for _, id := range inserted {
+		ids = append(ids, id)
+	}
+
+	cols := strings.Join(markDoneColumns, ", ") + ", fencing_token"
+	query, args, err := b.stmt.Delete(b.tables.handles).
+		Prefix(`WITH moved AS (`).
+		Where(sq.Eq{"id": ids}).
+		Suffix(`RETURNING ` + cols + `)
+			INSERT INTO ` + b.tables.done + ` (` + cols + `)
+			SELECT ` + cols + ` FROM moved
+			ON CONFLICT (namespace, name) DO NOTHING
+			RETURNING namespace, name`).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	archived := make(map[fullName]bool, len(inserted))
+	if err := scanRows(ctx, tx, query, args, func(rs rows) error {
+		var key fullName
+		if err := rs.Scan(&key.Namespace, &key.Name); err != nil {
+			return err
+		}
+		archived[key] = true
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return archived, nil
+}
+
+// --------------------------------------------------------------------
+
+type fullName struct {
+	Namespace, Name string
+}
+
+// nameTuples matches rows by (namespace, name), using ? placeholders.
+func nameTuples(names []fullName) sq.Sqlizer {
+	args := make([]interface{}, 0, 2*len(names))
+	for _, key := range names {
+		args = append(args, key.Namespace, key.Name)
+	}
+	return sq.Expr(`(namespace, name) IN (`+strings.TrimSuffix(strings.Repeat("(?, ?), ", len(names)), ", ")+`)`, args...)
+}
+
+// scanRows runs a query within tx and calls fn for each row.
+func scanRows(ctx context.Context, tx tx, query string, args []interface{}, fn func(rows) error) error {
+	rs, err := tx.query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rs.Close()
+
+	for rs.Next() {
+		if err := fn(rs); err != nil {
+			return err
+		}
+	}
+	return rs.Err()
+}
@@ -4,14 +4,17 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var migrateV1 = []string{
-	`CREATE TABLE meta_info (
+	`CREATE TABLE {meta_info} (
 			version INT NOT NULL DEFAULT 0
 		)`,
-	`INSERT INTO meta_info (version) VALUES (0)`,
-	`CREATE TABLE resource_handles (
+	`INSERT INTO {meta_info} (version) VALUES (0)`,
+	`CREATE TABLE {resource_handles} (
 			id UUID PRIMARY KEY,
 			namespace VARCHAR(100) NOT NULL,
 			name VARCHAR(255) NOT NULL,
@@ -24,31 +27,313 @@ var migrateV1 = []string{
 
 			UNIQUE (namespace, name)
 		)`,
-	`CREATE INDEX resource_handles_owner ON resource_handles USING btree (owner)`,
-	`CREATE INDEX resource_handles_expires_at ON resource_handles USING btree (expires_at)`,
-	`CREATE INDEX resource_handles_done_at ON resource_handles USING btree (done_at)`,
-	`CREATE INDEX resource_handles_metadata ON resource_handles USING gin (metadata)`,
+	`CREATE INDEX {prefix}resource_handles_owner ON {resource_handles} USING btree (owner)`,
+	`CREATE INDEX {prefix}resource_handles_expires_at ON {resource_handles} USING btree (expires_at)`,
+	`CREATE INDEX {prefix}resource_handles_done_at ON {resource_handles} USING btree (done_at)`,
+	`CREATE INDEX {prefix}resource_handles_metadata ON {resource_handles} USING gin (metadata)`,
 }
 
 var migrateV2 = []string{
-	`ALTER TABLE resource_handles ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()`,
-	`CREATE INDEX resource_handles_updated_at ON resource_handles USING btree (updated_at)`,
+	`ALTER TABLE {resource_handles} ADD COLUMN updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()`,
+	`CREATE INDEX {prefix}resource_handles_updated_at ON {resource_handles} USING btree (updated_at)`,
+}
+
+var migrateV3 = []string{
+	`CREATE INDEX {prefix}resource_handles_created_at_id ON {resource_handles} USING btree (created_at DESC, id DESC)`,
+}
+
+var migrateV4 = []string{
+	`CREATE TABLE {resource_handles_done} (
+			id UUID PRIMARY KEY,
+			namespace VARCHAR(100) NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			owner VARCHAR(255) NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			done_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			num_acquired INT NOT NULL,
+			metadata JSONB NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+			UNIQUE (namespace, name)
+		)`,
+	`CREATE INDEX {prefix}resource_handles_done_done_at ON {resource_handles_done} USING btree (done_at)`,
+	`CREATE INDEX {prefix}resource_handles_done_created_at_id ON {resource_handles_done} USING btree (created_at DESC, id DESC)`,
+	`CREATE INDEX {prefix}resource_handles_done_metadata ON {resource_handles_done} USING gin (metadata)`,
+}
+
+var migrateV5 = []string{
+	`CREATE INDEX {prefix}resource_handles_namespace_name_pattern ON {resource_handles} USING btree (namespace varchar_pattern_ops, name varchar_pattern_ops)`,
+	`CREATE INDEX {prefix}resource_handles_done_namespace_name_pattern ON {resource_handles_done} USING btree (namespace varchar_pattern_ops, name varchar_pattern_ops)`,
+}
+
+var migrateV6 = []string{
+	`ALTER TABLE {resource_handles} ADD COLUMN previous_owner VARCHAR(255) NOT NULL DEFAULT ''`,
+	`ALTER TABLE {resource_handles_done} ADD COLUMN previous_owner VARCHAR(255) NOT NULL DEFAULT ''`,
+}
+
+var migrateV7 = []string{
+	`ALTER TABLE {resource_handles} ADD COLUMN metadata_version BIGINT NOT NULL DEFAULT 1`,
+	`ALTER TABLE {resource_handles_done} ADD COLUMN metadata_version BIGINT NOT NULL DEFAULT 1`,
+}
+
+var migrateV8 = []string{
+	`CREATE SEQUENCE {qualify}resource_handles_fencing_token_seq`,
+	`ALTER TABLE {resource_handles} ADD COLUMN fencing_token BIGINT NOT NULL DEFAULT nextval('{qualify}resource_handles_fencing_token_seq')`,
+	`ALTER TABLE {resource_handles_done} ADD COLUMN fencing_token BIGINT NOT NULL DEFAULT 0`,
+}
+
+var migrateV9 = []string{
+	`ALTER TABLE {resource_handles} ADD COLUMN slot INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE {resource_handles} ADD COLUMN capacity INT NOT NULL DEFAULT 1`,
+	`ALTER TABLE {resource_handles} DROP CONSTRAINT {prefix}resource_handles_namespace_name_key`,
+	`ALTER TABLE {resource_handles} ADD CONSTRAINT {prefix}resource_handles_namespace_name_slot_key UNIQUE (namespace, name, slot)`,
+	`ALTER TABLE {resource_handles_done} ADD COLUMN slot INT NOT NULL DEFAULT 0`,
+	`ALTER TABLE {resource_handles_done} ADD COLUMN capacity INT NOT NULL DEFAULT 1`,
 }
 
-func migrateUp(ctx context.Context, db *sql.DB, version int, queries []string) error {
-	tx, err := db.BeginTx(ctx, nil)
+var migrateV10 = []string{
+	`ALTER TABLE {resource_handles} ADD COLUMN mode SMALLINT NOT NULL DEFAULT 0`,
+	`ALTER TABLE {resource_handles} ADD COLUMN queued BOOLEAN NOT NULL DEFAULT FALSE`,
+	`ALTER TABLE {resource_handles_done} ADD COLUMN mode SMALLINT NOT NULL DEFAULT 0`,
+	`ALTER TABLE {resource_handles_done} ADD COLUMN queued BOOLEAN NOT NULL DEFAULT FALSE`,
+}
+
+var migrateV11 = []string{
+	`CREATE INDEX {prefix}resource_handles_created_at_resource ON {resource_handles} USING btree (created_at DESC, namespace COLLATE "C" DESC, name COLLATE "C" DESC, slot DESC)`,
+	`CREATE INDEX {prefix}resource_handles_done_created_at_resource ON {resource_handles_done} USING btree (created_at DESC, namespace COLLATE "C" DESC, name COLLATE "C" DESC, slot DESC)`,
+	`DROP INDEX {qualify}resource_handles_created_at_id`,
+	`DROP INDEX {qualify}resource_handles_done_created_at_id`,
+}
+
+type migration struct {
+	up, down []string
+}
+
+var migrations = []migration{
+	{up: migrateV1, down: []string{
+		`DROP TABLE {resource_handles}`,
+		`DROP TABLE {meta_info}`,
+	}},
+	{up: migrateV2, down: []string{
+		`DROP INDEX {qualify}resource_handles_updated_at`,
+		`ALTER TABLE {resource_handles} DROP COLUMN updated_at`,
+	}},
+	{up: migrateV3, down: []string{
+		`DROP INDEX {qualify}resource_handles_created_at_id`,
+	}},
+	{up: migrateV4, down: []string{
+		`DROP TABLE {resource_handles_done}`,
+	}},
+	{up: migrateV5, down: []string{
+		`DROP INDEX {qualify}resource_handles_done_namespace_name_pattern`,
+		`DROP INDEX {qualify}resource_handles_namespace_name_pattern`,
+	}},
+	{up: migrateV6, down: []string{
+		`ALTER TABLE {resource_handles_done} DROP COLUMN previous_owner`,
+		`ALTER TABLE {resource_handles} DROP COLUMN previous_owner`,
+	}},
+	{up: migrateV7, down: []string{
+		`ALTER TABLE {resource_handles_done} DROP COLUMN metadata_version`,
+		`ALTER TABLE {resource_handles} DROP COLUMN metadata_version`,
+	}},
+	{up: migrateV8, down: []string{
+		`ALTER TABLE {resource_handles_done} DROP COLUMN fencing_token`,
+		`ALTER TABLE {resource_handles} DROP COLUMN fencing_token`,
+		`DROP SEQUENCE {qualify}resource_handles_fencing_token_seq`,
+	}},
+	{up: migrateV9, down: []string{
+		`ALTER TABLE {resource_handles_done} DROP COLUMN capacity`,
+		`ALTER TABLE {resource_handles_done} DROP COLUMN slot`,
+		`DELETE FROM {resource_handles} WHERE slot <> 0`,
+		`ALTER TABLE {resource_handles} DROP CONSTRAINT {prefix}resource_handles_namespace_name_slot_key`,
+		`ALTER TABLE {resource_handles} ADD CONSTRAINT {prefix}resource_handles_namespace_name_key UNIQUE (namespace, name)`,
+		`ALTER TABLE {resource_handles} DROP COLUMN capacity`,
+		`ALTER TABLE {resource_handles} DROP COLUMN slot`,
+	}},
+	{up: migrateV10, down: []string{
+		`ALTER TABLE {resource_handles_done} DROP COLUMN queued`,
+		`ALTER TABLE {resource_handles_done} DROP COLUMN mode`,
+		`DELETE FROM {resource_handles} WHERE mode <> 0`,
+		`ALTER TABLE {resource_handles} DROP COLUMN queued`,
+		`ALTER TABLE {resource_handles} DROP COLUMN mode`,
+	}},
+	{up: migrateV11, down: []string{
+		`CREATE INDEX {prefix}resource_handles_done_created_at_id ON {resource_handles_done} USING btree (created_at DESC, id DESC)`,
+		`CREATE INDEX {prefix}resource_handles_created_at_id ON {resource_handles} USING btree (created_at DESC, id DESC)`,
+		`DROP INDEX {qualify}resource_handles_done_created_at_resource`,
+		`DROP INDEX {qualify}resource_handles_created_at_resource`,
+	}},
+}
+
+// Migrate applies all pending schema migrations. It is called
+// automatically on open, unless Options.SkipMigrate is set.
+func Migrate(ctx context.Context, db *sql.DB, opt *Options) error {
+	m, err := NewMigrator(db, opt)
+	if err != nil {
+		return err
+	}
+	return m.Up(ctx)
+}
+
+// MigratePgx is like Migrate, but uses a native pgx connection pool.
+func MigratePgx(ctx context.Context, pool *pgxpool.Pool, opt *Options) error {
+	m, err := NewPgxMigrator(pool, opt)
 	if err != nil {
 		return err
 	}
-	defer tx.Rollback()
+	return m.Up(ctx)
+}
+
+// Migrator manages schema migrations. Migrations are applied within a
+// single transaction, concurrent migrations are serialized via an advisory
+// lock.
+type Migrator struct {
+	conn   conn
+	tables *tables
+}
+
+// NewMigrator inits a new migrator.
+func NewMigrator(db *sql.DB, opt *Options) (*Migrator, error) {
+	return newMigrator(&sqlConn{db: db}, opt)
+}
+
+// NewPgxMigrator inits a new migrator using a native pgx connection pool.
+func NewPgxMigrator(pool *pgxpool.Pool, opt *Options) (*Migrator, error) {
+	return newMigrator(&pgxConn{pool: pool}, opt)
+}
+
+func newMigrator(conn conn, opt *Options) (*Migrator, error) {
+	opt, err := opt.norm()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{conn: conn, tables: newTables(opt)}, nil
+}
 
-	for _, sql := range queries {
-		if _, err := tx.ExecContext(ctx, sql); err != nil {
-			return fmt.Errorf("migration (v%d) query %q failed: %v", version, sql, err)
+// Version returns the current and the latest schema version.
+func (m *Migrator) Version(ctx context.Context) (current, latest int, err error) {
+	current, err = currentVersion(ctx, m.conn, m.tables)
+	return current, len(migrations), err
+}
+
+// Up applies all pending migrations.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.MigrateTo(ctx, len(migrations))
+}
+
+// Down reverts the most recent migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	current, err := currentVersion(ctx, m.conn, m.tables)
+	if err != nil {
+		return err
+	} else if current == 0 {
+		return nil
+	}
+	return m.MigrateTo(ctx, current-1)
+}
+
+// MigrateTo migrates the schema up or down to the target version.
+func (m *Migrator) MigrateTo(ctx context.Context, target int) error {
+	if target < 0 || target > len(migrations) {
+		return fmt.Errorf("accord: invalid schema version %d", target)
+	}
+
+	tx, err := m.conn.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.rollback(ctx) }()
+
+	if _, err := tx.exec(ctx, `SELECT pg_advisory_xact_lock($1)`, m.tables.lockKey()); err != nil {
+		return err
+	}
+	if m.tables.schema != "" && target != 0 {
+		if _, err := tx.exec(ctx, `CREATE SCHEMA IF NOT EXISTS `+m.tables.schema); err != nil {
+			return err
+		}
+	}
+
+	current, err := currentVersion(ctx, tx, m.tables)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range plan(current, target) {
+		for _, sql := range step.queries {
+			sql = m.tables.expand(sql)
+			if _, err := tx.exec(ctx, sql); err != nil {
+				return fmt.Errorf("migration (v%d) query %q failed: %v", step.version, sql, err)
+			}
 		}
 	}
-	if _, err := tx.ExecContext(ctx, `UPDATE meta_info SET version = $1`, version); err != nil {
-		return fmt.Errorf("migration (v%d) failed: %v", version, err)
+	return tx.commit(ctx)
+}
+
+// Plan returns the SQL statements required to migrate from the current to
+// the target version, e.g. for review before applying them.
+func (m *Migrator) Plan(ctx context.Context, target int) ([]string, error) {
+	if target < 0 || target > len(migrations) {
+		return nil, fmt.Errorf("accord: invalid schema version %d", target)
+	}
+
+	current, err := currentVersion(ctx, m.conn, m.tables)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []string
+	if m.tables.schema != "" && current == 0 && target != 0 {
+		queries = append(queries, `CREATE SCHEMA IF NOT EXISTS `+m.tables.schema)
+	}
+	for _, step := range plan(current, target) {
+		for _, sql := range step.queries {
+			queries = append(queries, m.tables.expand(sql))
+		}
+	}
+	return queries, nil
+}
+
+type migrationStep struct {
+	version int
+	queries []string
+}
+
+// plan returns the migration steps between two versions, including the
+// version updates.
+func plan(current, target int) []migrationStep {
+	var steps []migrationStep
+	for v := current + 1; v <= target; v++ {
+		queries := append(append([]string(nil), migrations[v-1].up...), fmt.Sprintf(`UPDATE {meta_info} SET version = %d`, v))
+		steps = append(steps, migrationStep{version: v, queries: queries})
+	}
+	for v := current; v > target; v-- {
+		queries := append([]string(nil), migrations[v-1].down...)
+		if v > 1 {
+			queries = append(queries, fmt.Sprintf(`UPDATE {meta_info} SET version = %d`, v-1))
+		}
+		steps = append(steps, migrationStep{version: v, queries: queries})
+	}
+	return steps
+}
+
+type queryRower interface {
+	queryRow(ctx context.Context, query string, args ...interface{}) sq.RowScanner
+}
+
+func currentVersion(ctx context.Context, conn queryRower, t *tables) (int, error) {
+	var exists bool
+	if err := conn.queryRow(ctx, `SELECT EXISTS (
+			SELECT 1 FROM pg_catalog.pg_tables WHERE schemaname = COALESCE(NULLIF($1, ''), current_schema()) AND tablename = $2
+		)`, t.schema, t.prefix+"meta_info").Scan(&exists); err != nil {
+		return 0, err
+	} else if !exists {
+		return 0, nil
+	}
+
+	var version int
+	if err := conn.queryRow(ctx, t.expand(`SELECT version FROM {meta_info}`)).Scan(&version); err != nil {
+		return 0, err
 	}
-	return tx.Commit()
+	return version, nil
 }
@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Options contains options for the postgres backend.
+type Options struct {
+	// Schema is the schema to store the tables in. It is created
+	// automatically if missing. Default: the connection's default schema.
+	Schema string
+
+	// TablePrefix is prepended to all table and index names, e.g. "accord_".
+	TablePrefix string
+
+	// SkipMigrate disables the automatic migration of the schema on open.
+	// Migrations must then be applied separately via Migrate.
+	SkipMigrate bool
+
+	// Archive moves handles into a separate resource_handles_done table
+	// when they are marked as done. This keeps the table and indexes used
+	// by Acquire small. Archived handles are still returned by Get and List.
+	Archive bool
+
+	// Replica is an optional read replica. When healthy, Get and List
+	// queries are routed to the replica instead of the primary. The replica
+	// must run PostgreSQL >= 10 and is not closed when the backend is closed.
+	Replica *sql.DB
+
+	// ReplicaPool is an alternative to Replica, using a native pgx pool.
+	ReplicaPool *pgxpool.Pool
+
+	// MaxReplicaLag is the maximum tolerated replication lag. Reads fall
+	// back to the primary while the replica lags further behind or is
+	// unreachable. Default: 10s.
+	MaxReplicaLag time.Duration
+}
+
+func (o *Options) norm() (*Options, error) {
+	var p Options
+	if o != nil {
+		p = *o
+	}
+
+	if p.Schema != "" && !validIdent.MatchString(p.Schema) {
+		return nil, fmt.Errorf("accord: invalid schema name %q", p.Schema)
+	}
+	if p.TablePrefix != "" && !validIdent.MatchString(p.TablePrefix) {
+		return nil, fmt.Errorf("accord: invalid table prefix %q", p.TablePrefix)
+	}
+	if p.Replica != nil && p.ReplicaPool != nil {
+		return nil, errors.New("accord: only one of Replica and ReplicaPool may be set")
+	}
+	if p.MaxReplicaLag <= 0 {
+		p.MaxReplicaLag = 10 * time.Second
+	}
+	return &p, nil
+}
+
+var validIdent = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// tables contains the qualified table names.
+type tables struct {
+	schema   string
+	prefix   string
+	qualify  string
+	metaInfo string
+	handles  string
+	done     string
+	replacer *strings.Replacer
+}
+
+func newTables(opt *Options) *tables {
+	qualify := opt.TablePrefix
+	if opt.Schema != "" {
+		qualify = opt.Schema + "." + opt.TablePrefix
+	}
+
+	t := &tables{
+		schema:   opt.Schema,
+		prefix:   opt.TablePrefix,
+		qualify:  qualify,
+		metaInfo: qualify + "meta_info",
+		handles:  qualify + "resource_handles",
+		done:     qualify + "resource_handles_done",
+	}
+	t.replacer = strings.NewReplacer(
+		"{meta_info}", t.metaInfo,
+		"{resource_handles}", t.handles,
+		"{resource_handles_done}", t.done,
+		"{prefix}", t.prefix,
+		"{qualify}", t.qualify,
+	)
+	return t
+}
+
+// expand expands table and index name placeholders in a query.
+func (t *tables) expand(query string) string {
+	return t.replacer.Replace(query)
+}
+
+// lockKey returns the advisory lock key for migrations, deployments with
+// different schemas or table prefixes use different keys.
+func (t *tables) lockKey() int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("accord:" + t.qualify))
+	return int64(h.Sum64())
+}
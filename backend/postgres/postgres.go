@@ -2,10 +2,9 @@
 package postgres
 
 import (
-	"bytes"
 	"context"
 	"database/sql"
-	"encoding/json"
+	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -13,48 +12,212 @@ import (
 	"github.com/bsm/accord/backend"
 	"github.com/bsm/accord/rpc"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/lib/pq" // register the postgres database/sql driver
 )
 
 type postgres struct {
-	*sql.DB
-	stmt  sq.StatementBuilderType
-	ownDB bool
+	conn    conn
+	replica *replica
+	stmt    sq.StatementBuilderType
+	tables  *tables
+	archive bool
+	ownDB   *sql.DB
 }
 
 // Open connects to the backend
-func Open(ctx context.Context, driver, dsn string) (backend.Backend, error) {
+func Open(ctx context.Context, driver, dsn string, opt *Options) (backend.Backend, error) {
 	db, err := sql.Open(driver, dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	b, err := OpenDB(ctx, db)
+	b, err := OpenDB(ctx, db, opt)
 	if err != nil {
 		_ = db.Close()
 		return nil, err
 	}
 
-	b.(*postgres).ownDB = true
+	b.(*postgres).ownDB = db
 	return b, nil
 }
 
 // OpenDB connects to the backend
-func OpenDB(ctx context.Context, db *sql.DB) (backend.Backend, error) {
+func OpenDB(ctx context.Context, db *sql.DB, opt *Options) (backend.Backend, error) {
+	return open(ctx, &sqlConn{db: db}, opt)
+}
+
+// OpenPgx connects to the backend using a native pgx connection pool.
+// The pool is not closed when the backend is closed.
+func OpenPgx(ctx context.Context, pool *pgxpool.Pool, opt *Options) (backend.Backend, error) {
+	return open(ctx, &pgxConn{pool: pool}, opt)
+}
+
+func open(ctx context.Context, conn conn, opt *Options) (*postgres, error) {
+	opt, err := opt.norm()
+	if err != nil {
+		return nil, err
+	}
+
 	b := &postgres{
-		DB:   db,
-		stmt: sq.StatementBuilder.RunWith(db).PlaceholderFormat(sq.Dollar),
+		conn:    conn,
+		stmt:    sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+		tables:  newTables(opt),
+		archive: opt.Archive,
 	}
-	if err := b.migrate(ctx); err != nil {
-		return nil, err
+	if opt.Replica != nil {
+		b.replica = &replica{conn: &sqlConn{db: opt.Replica}, maxLag: opt.MaxReplicaLag}
+	} else if opt.ReplicaPool != nil {
+		b.replica = &replica{conn: &pgxConn{pool: opt.ReplicaPool}, maxLag: opt.MaxReplicaLag}
+	}
+	if !opt.SkipMigrate {
+		m := &Migrator{conn: conn, tables: b.tables}
+		if err := m.Up(ctx); err != nil {
+			return nil, err
+		}
 	}
 	return b, nil
 }
 
+// maxAttempts limits the number of retries when racing for shared slots.
+const maxAttempts = 10
+
 // Acquire implements the backend.Backend interface.
-func (b *postgres) Acquire(ctx context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string) (*backend.HandleData, error) {
+func (b *postgres) Acquire(ctx context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string, opt *backend.AcquireOptions) (*backend.HandleData, error) {
+	if opt.GetMode() == rpc.Mode_SHARED {
+		return b.acquireShared(ctx, owner, namespace, name, exp, metadata)
+	}
+
+	capacity := opt.GetCapacity()
+	if capacity == 1 {
+		handle, err := b.acquireSlot(ctx, owner, namespace, name, exp, metadata, 0, 1, rpc.Mode_EXCLUSIVE)
+		if err != nil {
+			return nil, err
+		}
+		return b.verifyExclusive(ctx, handle)
+	}
+
+	slots, err := b.vacantSlots(ctx, namespace, name, capacity)
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range slots {
+		handle, err := b.acquireSlot(ctx, owner, namespace, name, exp, metadata, slot, capacity, rpc.Mode_EXCLUSIVE)
+		if err != accord.ErrAcquired {
+			return handle, err
+		}
+	}
+	return nil, accord.ErrAcquired
+}
+
+// verifyExclusive checks for held shared handles after an exclusive handle
+// has been claimed. If there are any, the claim is marked as queued.
+func (b *postgres) verifyExclusive(ctx context.Context, handle *backend.HandleData) (*backend.HandleData, error) {
+	var shared bool
+	if err := b.conn.queryRow(ctx, b.tables.expand(`
+		SELECT EXISTS (
+			SELECT 1 FROM {resource_handles}
+			WHERE namespace = $1 AND name = $2 AND mode = $3 AND done_at IS NULL AND expires_at >= $4
+		)
+	`), handle.Namespace, handle.Name, int32(rpc.Mode_SHARED), time.Now().UTC()).Scan(&shared); err != nil {
+		return nil, err
+	} else if !shared {
+		return handle, nil
+	}
+
+	if _, err := b.conn.exec(ctx, b.tables.expand(`
+		UPDATE {resource_handles} SET queued = TRUE WHERE id = $1
+	`), handle.ID); err != nil {
+		return nil, err
+	}
+	return nil, accord.ErrAcquired
+}
+
+// acquireShared acquires a shared handle. Once claimed, the handle is
+// released again if slot 0 has been acquired in the meantime.
+func (b *postgres) acquireShared(ctx context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string) (*backend.HandleData, error) {
+	opt := &backend.AcquireOptions{Mode: rpc.Mode_SHARED}
+
+	for attempt := 1; ; attempt++ {
+		slots, err := b.resourceSlots(ctx, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+
+		slot, _, err := backend.SelectSlot(slots, owner, opt, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		handle, err := b.acquireSlot(ctx, owner, namespace, name, exp, metadata, slot, 1, rpc.Mode_SHARED)
+		if err == accord.ErrAcquired && attempt < maxAttempts {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		var done bool
+		err = b.conn.queryRow(ctx, b.tables.expand(`
+			SELECT done_at IS NOT NULL FROM {resource_handles}
+			WHERE namespace = $1 AND name = $2 AND slot = 0
+				AND ((done_at IS NULL AND expires_at >= $3) OR (done_at IS NOT NULL AND capacity = 1))
+		`), namespace, name, time.Now().UTC()).Scan(&done)
+		if err == sql.ErrNoRows {
+			return handle, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		now := time.Now().UTC()
+		if _, err := b.conn.exec(ctx, b.tables.expand(`
+			UPDATE {resource_handles} SET done_at = $2, updated_at = $2 WHERE id = $1
+		`), handle.ID, now); err != nil {
+			return nil, err
+		}
+		if done {
+			return nil, accord.ErrDone
+		}
+		return nil, accord.ErrAcquired
+	}
+}
+
+// resourceSlots returns the stored handles of a resource, indexed by slot.
+func (b *postgres) resourceSlots(ctx context.Context, namespace, name string) ([]*backend.HandleData, error) {
+	query, args, err := b.stmt.
+		Select(handleColumns...).
+		From(b.tables.handles).
+		Where(sq.Eq{"namespace": namespace, "name": name}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := b.conn.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slots []*backend.HandleData
+	for rows.Next() {
+		handle, err := scanHandle(rows)
+		if err != nil {
+			return nil, err
+		}
+		for len(slots) <= handle.Slot {
+			slots = append(slots, nil)
+		}
+		slots[handle.Slot] = handle
+	}
+	return slots, rows.Err()
+}
+
+// acquireSlot acquires a single slot of the resource.
+func (b *postgres) acquireSlot(ctx context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string, slot, capacity int, mode rpc.Mode) (*backend.HandleData, error) {
 	handleID := uuid.New()
 	now := time.Now().UTC()
-	stmt := b.stmt.Insert("resource_handles").
+	takeover := sq.Expr(`((resource_handles.done_at IS NULL AND resource_handles.expires_at < ?) OR (resource_handles.done_at IS NOT NULL AND (resource_handles.capacity > 1 OR resource_handles.mode = ?)) OR (resource_handles.queued AND resource_handles.owner = EXCLUDED.owner))`, now, int32(rpc.Mode_SHARED))
+	stmt := b.stmt.Insert(b.tables.handles+" AS resource_handles").
 		Columns(
 			"id",
 			"namespace",
@@ -65,66 +228,125 @@ func (b *postgres) Acquire(ctx context.Context, owner, namespace, name string, e
 			"created_at",
 			"updated_at",
 			"metadata",
+			"slot",
+			"capacity",
+			"mode",
 		).
 		Values(
 			handleID,
 			namespace,
 			name,
 			owner,
-			exp,
+			exp.UTC(),
 			1,
 			now,
 			now,
 			metaJSONb(metadata),
+			slot,
+			capacity,
+			int32(mode),
 		).
-		Suffix(`
-			ON CONFLICT (namespace, name) DO UPDATE SET
-				id           = CASE WHEN resource_handles.expires_at < ? AND resource_handles.done_at IS NULL THEN ? ELSE resource_handles.id END,
-				owner        = CASE WHEN resource_handles.expires_at < ? AND resource_handles.done_at IS NULL THEN ? ELSE resource_handles.owner END,
-				expires_at   = CASE WHEN resource_handles.expires_at < ? AND resource_handles.done_at IS NULL THEN ? ELSE resource_handles.expires_at END,
-				num_acquired = CASE WHEN resource_handles.expires_at < ? AND resource_handles.done_at IS NULL THEN resource_handles.num_acquired + 1 ELSE resource_handles.num_acquired END,
-				updated_at   = ?
-			RETURNING
-				id,
-				namespace,
-				name,
-				owner,
-				expires_at,
-				num_acquired,
-				done_at,
-				metadata
-		`, now, handleID, now, owner, now, exp.UTC(), now, now)
-
-	handle, err := scanHandle(stmt.QueryRowContext(ctx))
-	if err != nil {
-		return nil, err
-	}
-	if handle.IsDone() {
-		return nil, accord.ErrDone
-	}
-	if handle.Owner != owner || !bytes.Equal(handle.ID[:], handleID[:]) {
-		return nil, accord.ErrAcquired
+		SuffixExpr(sq.Expr(`
+			ON CONFLICT (namespace, name, slot) DO UPDATE SET
+				id             = CASE WHEN ? THEN EXCLUDED.id ELSE resource_handles.id END,
+				owner          = CASE WHEN ? THEN EXCLUDED.owner ELSE resource_handles.owner END,
+				previous_owner = CASE WHEN ? THEN resource_handles.owner ELSE resource_handles.previous_owner END,
+				expires_at     = CASE WHEN ? THEN EXCLUDED.expires_at ELSE resource_handles.expires_at END,
+				num_acquired   = CASE WHEN ? THEN resource_handles.num_acquired + 1 ELSE resource_handles.num_acquired END,
+				updated_at     = CASE WHEN ? THEN EXCLUDED.updated_at ELSE resource_handles.updated_at END,
+				fencing_token  = CASE WHEN ? THEN EXCLUDED.fencing_token ELSE resource_handles.fencing_token END,
+				capacity       = CASE WHEN ? THEN EXCLUDED.capacity ELSE resource_handles.capacity END,
+				mode           = CASE WHEN ? THEN EXCLUDED.mode ELSE resource_handles.mode END,
+				queued         = CASE WHEN ? THEN FALSE ELSE resource_handles.queued END,
+				done_at        = CASE WHEN ? THEN NULL ELSE resource_handles.done_at END
+			RETURNING `+strings.Join(handleColumns, ", "),
+			takeover, takeover, takeover, takeover, takeover, takeover, takeover, takeover, takeover, takeover, takeover,
+		))
+
+	query, args, err := stmt.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	if b.archive {
+		return b.acquireUnlessArchived(ctx, query, args, owner, handleID)
+	}
+
+	handle, err := scanHandle(b.conn.queryRowPrepared(ctx, query, args...))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkAcquired(handle, owner, handleID); err != nil {
+		return nil, err
 	}
 	return handle, nil
 }
 
+// vacantSlots returns the semaphore slots which are currently vacant. It
+// returns accord.ErrDone if the resource has been marked as done.
+func (b *postgres) vacantSlots(ctx context.Context, namespace, name string, capacity int) ([]int, error) {
+	rows, err := b.conn.query(ctx, b.tables.expand(`
+		SELECT slot, done_at IS NOT NULL FROM {resource_handles}
+		WHERE namespace = $1 AND name = $2 AND slot < $3
+			AND ((done_at IS NULL AND expires_at >= $4) OR (done_at IS NOT NULL AND capacity = 1))
+	`), namespace, name, capacity, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	occupied := make(map[int]bool, capacity)
+	for rows.Next() {
+		var slot int
+		var done bool
+		if err := rows.Scan(&slot, &done); err != nil {
+			return nil, err
+		} else if done {
+			return nil, accord.ErrDone
+		}
+		occupied[slot] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	slots := make([]int, 0, capacity-len(occupied))
+	for slot := 0; slot < capacity; slot++ {
+		if !occupied[slot] {
+			slots = append(slots, slot)
+		}
+	}
+	return slots, nil
+}
+
 // Get implements the backend.Backend interface.
 func (b *postgres) Get(ctx context.Context, handleID uuid.UUID) (*backend.HandleData, error) {
-	stmt := b.stmt.
-		Select(
-			"id",
-			"namespace",
-			"name",
-			"owner",
-			"expires_at",
-			"num_acquired",
-			"done_at",
-			"metadata",
-		).
-		From("resource_handles").
-		Where(sq.Eq{"id": handleID})
+	if c := b.reader(); c != b.conn {
+		// handles missing on the replica may not have been replicated yet
+		handle, err := b.get(ctx, c, handleID)
+		if err == nil && handle != nil {
+			return handle, nil
+		} else if err != nil {
+			b.replica.markUnhealthy()
+		}
+	}
+	return b.get(ctx, b.conn, handleID)
+}
+
+// ValidateToken implements the backend.Backend interface. Tokens are always
+// validated on the primary.
+func (b *postgres) ValidateToken(ctx context.Context, handleID uuid.UUID, token int64) error {
+	handle, err := b.get(ctx, b.conn, handleID)
+	if err != nil {
+		return err
+	}
+	return backend.ValidateFencingToken(handle, token)
+}
 
-	handle, err := scanHandle(stmt.QueryRowContext(ctx))
+func (b *postgres) get(ctx context.Context, c conn, handleID uuid.UUID) (*backend.HandleData, error) {
+	handle, err := b.getFrom(ctx, c, b.tables.handles, handleID)
+	if err == sql.ErrNoRows && b.archive {
+		handle, err = b.getFrom(ctx, c, b.tables.done, handleID)
+	}
 	if err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
@@ -133,45 +355,51 @@ func (b *postgres) Get(ctx context.Context, handleID uuid.UUID) (*backend.Handle
 	return handle, nil
 }
 
+func (b *postgres) getFrom(ctx context.Context, c conn, table string, handleID uuid.UUID) (*backend.HandleData, error) {
+	stmt := b.stmt.
+		Select(handleColumns...).
+		From(table).
+		Where(sq.Eq{"id": handleID})
+
+	query, args, err := stmt.ToSql()
+	if err != nil {
+		return nil, err
+	}
+	return scanHandle(c.queryRow(ctx, query, args...))
+}
+
 // List implements the backend.Backend interface.
 func (b *postgres) List(ctx context.Context, req *rpc.ListRequest, iter backend.Iterator) error {
-	stmt := b.stmt.
-		Select(
-			"id",
-			"namespace",
-			"name",
-			"owner",
-			"expires_at",
-			"num_acquired",
-			"done_at",
-			"metadata",
-		).
-		From("resource_handles").
-		OrderBy("created_at DESC")
+	cursor, err := backend.ParseListCursor(req)
+	if err != nil {
+		return err
+	}
+
+	filter, err := backend.ParseListFilter(req.GetFilter())
+	if err != nil {
+		return err
+	}
 
+	stmt := b.listSource(filter, cursor).OrderBy(orderBy(req)...)
 	if o := req.GetOffset(); o != 0 {
 		stmt = stmt.Offset(o)
 	}
+	if n := req.GetLimit(); n != 0 {
+		stmt = stmt.Limit(uint64(n))
+	}
 
-	if f := req.GetFilter(); f != nil {
-		if f.Status == rpc.ListRequest_Filter_DONE {
-			stmt = stmt.Where(sq.NotEq{"done_at": nil})
-		} else if f.Status == rpc.ListRequest_Filter_PENDING {
-			stmt = stmt.Where(sq.Eq{"done_at": nil})
-		}
-		if f.Prefix != "" {
-			stmt = stmt.Where(sq.Like{"namespace": f.Prefix + "%"})
-		}
-		if len(f.Metadata) != 0 {
-			metadata, _ := json.Marshal(f.Metadata)
-			stmt = stmt.Where("metadata @> ?", metadata)
-		}
+	query, args, err := stmt.ToSql()
+	if err != nil {
+		return err
 	}
 
-	rows, err := stmt.QueryContext(ctx)
-	if err == sql.ErrNoRows {
-		return nil
-	} else if err != nil {
+	c := b.reader()
+	rows, err := c.query(ctx, query, args...)
+	if err != nil && c != b.conn {
+		b.replica.markUnhealthy()
+		rows, err = b.conn.query(ctx, query, args...)
+	}
+	if err != nil {
 		return err
 	}
 	defer rows.Close()
@@ -191,60 +419,165 @@ func (b *postgres) List(ctx context.Context, req *rpc.ListRequest, iter backend.
 	return rows.Err()
 }
 
+// Count implements the backend.Backend interface.
+func (b *postgres) Count(ctx context.Context, req *rpc.ListRequest_Filter) (int64, error) {
+	filter, err := backend.ParseListFilter(req)
+	if err != nil {
+		return 0, err
+	}
+
+	query, args, err := b.stmt.Select("COUNT(*)").FromSelect(b.listSource(filter, nil), "c").ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var num int64
+	c := b.reader()
+	err = c.queryRow(ctx, query, args...).Scan(&num)
+	if err != nil && c != b.conn {
+		b.replica.markUnhealthy()
+		err = b.conn.queryRow(ctx, query, args...).Scan(&num)
+	}
+	return num, err
+}
+
+// listSource selects the handles matching filter and cursor. In archive
+// mode, it reads from the live and the archive table transparently.
+func (b *postgres) listSource(filter *backend.ListFilter, cursor *backend.Cursor) sq.SelectBuilder {
+	if !b.archive || (filter != nil && filter.Status == rpc.ListRequest_Filter_PENDING) {
+		return listSelect(b.tables.handles, filter, cursor).PlaceholderFormat(sq.Dollar)
+	}
+
+	union := listSelect(b.tables.handles, filter, cursor).
+		SuffixExpr(sq.ConcatExpr("UNION ALL ", listSelect(b.tables.done, filter, cursor)))
+	return b.stmt.Select(handleColumns...).FromSelect(union, "h")
+}
+
 // Renew implements the backend.Backend interface.
-func (b *postgres) Renew(ctx context.Context, owner string, handleID uuid.UUID, exp time.Time, metadata map[string]string) error {
+func (b *postgres) Renew(ctx context.Context, owner string, handleID uuid.UUID, exp time.Time, metadata map[string]string, opt *backend.UpdateOptions) error {
 	now := time.Now().UTC()
-	stmt := b.stmt.Update("resource_handles").
+	stmt := b.stmt.Update(b.tables.handles).
 		Set("expires_at", exp.UTC()).
 		Set("updated_at", now).
-		Set("metadata", sq.Expr(`(metadata || ?)`, metaJSONb(metadata))).
+		Set("metadata", metaExpr(metadata, opt)).
+		Set("metadata_version", metaVersionExpr(metaExpr(metadata, opt))).
 		Where(sq.Eq{
 			"id":      handleID,
 			"owner":   owner,
 			"done_at": nil,
 		})
-	return performUpdate(ctx, stmt)
+	return b.performUpdate(ctx, stmt, owner, handleID, opt)
 }
 
 // Done implements the backend.Backend interface.
-func (b *postgres) Done(ctx context.Context, owner string, handleID uuid.UUID, metadata map[string]string) error {
+func (b *postgres) Done(ctx context.Context, owner string, handleID uuid.UUID, metadata map[string]string, opt *backend.UpdateOptions) error {
 	now := time.Now().UTC()
-	stmt := b.stmt.Update("resource_handles").
+	stmt := b.stmt.Update(b.tables.handles).
 		Set("done_at", now).
 		Set("updated_at", now).
-		Set("metadata", sq.Expr(`(metadata || ?)`, metaJSONb(metadata))).
+		Set("metadata", metaExpr(metadata, opt)).
+		Set("metadata_version", metaVersionExpr(metaExpr(metadata, opt))).
 		Where(sq.Eq{
 			"id":      handleID,
 			"owner":   owner,
 			"done_at": nil,
 		})
-	return performUpdate(ctx, stmt)
+	if b.archive {
+		return b.doneAndArchive(ctx, stmt, owner, handleID, metadata, opt)
+	}
+	return b.performUpdate(ctx, stmt, owner, handleID, opt)
 }
 
-// Ping implements the backend.Backend interface.
-func (b *postgres) Ping() error { return b.DB.Ping() }
+// Transfer implements the backend.Backend interface.
+func (b *postgres) Transfer(ctx context.Context, owner string, handleID uuid.UUID, newOwner string, rotateID bool) (*backend.HandleData, error) {
+	newID := handleID
+	if rotateID {
+		newID = uuid.New()
+	}
 
-// Close implements the backend.Backend interface.
-func (b *postgres) Close() error {
-	if b.ownDB {
-		return b.DB.Close()
+	now := time.Now().UTC()
+	query, args, err := b.stmt.Update(b.tables.handles).
+		Set("id", newID).
+		Set("owner", newOwner).
+		Set("previous_owner", sq.Expr("owner")).
+		Set("updated_at", now).
+		Set("fencing_token", sq.Expr(b.tables.expand(`nextval('{qualify}resource_handles_fencing_token_seq')`))).
+		Where(sq.Eq{
+			"id":      handleID,
+			"owner":   owner,
+			"done_at": nil,
+		}).
+		Where(sq.GtOrEq{"expires_at": now}).
+		Suffix("RETURNING " + strings.Join(handleColumns, ", ")).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	handle, err := scanHandle(b.conn.queryRowPrepared(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, backend.ErrInvalidHandle
+	} else if err != nil {
+		return nil, err
 	}
-	return nil
+	return handle, nil
 }
 
-func (b *postgres) migrate(ctx context.Context) error {
-	var version int64
-	_ = b.stmt.Select("version").From("meta_info").ScanContext(ctx, &version)
+// Purge implements the backend.Backend interface.
+func (b *postgres) Purge(ctx context.Context, filter *backend.PurgeFilter) (int64, error) {
+	num, err := b.purge(ctx, b.tables.handles, filter)
+	if err != nil || !b.archive {
+		return num, err
+	}
 
-	if version < 1 {
-		if err := migrateUp(ctx, b.DB, 1, migrateV1); err != nil {
-			return err
+	archived, err := b.purge(ctx, b.tables.done, filter)
+	return num + archived, err
+}
+
+func (b *postgres) purge(ctx context.Context, table string, filter *backend.PurgeFilter) (int64, error) {
+	stmt := b.stmt.Delete(table).
+		Where(sq.NotEq{"done_at": nil}).
+		Where(sq.Lt{"done_at": filter.Before.UTC()}).
+		Where(sq.Eq{"capacity": 1}).
+		Where(sq.NotEq{"mode": int32(rpc.Mode_SHARED)})
+
+	if filter.Prefix != "" {
+		stmt = stmt.Where(sq.Like{"namespace": likeEscaper.Replace(filter.Prefix) + "%"})
+	}
+	for _, prefix := range filter.Exclude {
+		stmt = stmt.Where(sq.NotLike{"namespace": likeEscaper.Replace(prefix) + "%"})
+	}
+
+	query, args, err := stmt.ToSql()
+	if err != nil {
+		return 0, err
+	}
+	return b.conn.exec(ctx, query, args...)
+}
+
+// reader returns the connection for read-only queries.
+func (b *postgres) reader() conn {
+	if b.replica != nil && b.replica.isHealthy() {
+		return b.replica.conn
+	}
+	return b.conn
+}
+
+// Ping implements the backend.Backend interface.
+func (b *postgres) Ping() error { return b.conn.ping(context.Background()) }
+
+// Close implements the backend.Backend interface.
+func (b *postgres) Close() error {
+	err := b.conn.close()
+	if b.replica != nil {
+		if e2 := b.replica.conn.close(); e2 != nil {
+			err = e2
 		}
 	}
-	if version < 2 {
-		if err := migrateUp(ctx, b.DB, 2, migrateV2); err != nil {
-			return err
+	if b.ownDB != nil {
+		if e2 := b.ownDB.Close(); e2 != nil {
+			err = e2
 		}
 	}
-	return nil
+	return err
 }
@@ -4,18 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
-	"github.com/bsm/accord/backend/internal/testdata"
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/backend/backendtest"
 	"github.com/bsm/accord/backend/postgres"
+	"github.com/bsm/accord/rpc"
 	. "github.com/bsm/ginkgo/v2"
 	. "github.com/bsm/gomega"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 )
 
 var _ = Describe("Backend", func() {
-	var data testdata.BehavesLikeBackendData
+	var data backendtest.BehavesLikeBackendData
 	var db *sql.DB
+	var ctx = context.Background()
 
 	BeforeEach(func() {
 		dsn := os.Getenv("DATABASE_DSN")
@@ -36,9 +43,7 @@ var _ = Describe("Backend", func() {
 			Expect(rows.Scan(&name)).To(Succeed())
 			Expect(db.Exec("DROP TABLE " + name)).NotTo(BeNil())
 		}
-
-		data.Subject, err = postgres.OpenDB(context.Background(), db)
-		Expect(err).NotTo(HaveOccurred())
+		Expect(db.Exec("DROP SCHEMA IF EXISTS accord_test CASCADE")).NotTo(BeNil())
 	})
 
 	AfterEach(func() {
@@ -47,7 +52,215 @@ var _ = Describe("Backend", func() {
 		}
 	})
 
-	Context("defaults", testdata.BehavesLikeBackend(&data))
+	Context("defaults", func() {
+		BeforeEach(func() {
+			var err error
+			data.Subject, err = postgres.OpenDB(ctx, db, nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("conformance", backendtest.BehavesLikeBackend(&data))
+	})
+
+	Context("pgx", func() {
+		var pool *pgxpool.Pool
+
+		BeforeEach(func() {
+			var err error
+			pool, err = pgxpool.New(ctx, os.Getenv("DATABASE_DSN"))
+			Expect(err).NotTo(HaveOccurred())
+
+			data.Subject, err = postgres.OpenPgx(ctx, pool, nil)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			pool.Close()
+		})
+
+		Context("conformance", backendtest.BehavesLikeBackend(&data))
+	})
+
+	Context("with schema and table prefix", func() {
+		opt := &postgres.Options{Schema: "accord_test", TablePrefix: "x_"}
+
+		BeforeEach(func() {
+			var err error
+			data.Subject, err = postgres.OpenDB(ctx, db, opt)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("conformance", backendtest.BehavesLikeBackend(&data))
+
+		It("should create tables in schema", func() {
+			var num int
+			Expect(db.QueryRow("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = 'accord_test' AND table_name IN ('x_meta_info', 'x_resource_handles')").Scan(&num)).To(Succeed())
+			Expect(num).To(Equal(2))
+
+			Expect(db.QueryRow("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = 'public'").Scan(&num)).To(Succeed())
+			Expect(num).To(Equal(0))
+			Expect(data.Subject.Close()).To(Succeed())
+		})
+	})
+
+	Context("with archive", func() {
+		BeforeEach(func() {
+			var err error
+			data.Subject, err = postgres.OpenDB(ctx, db, &postgres.Options{Archive: true})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("conformance", backendtest.BehavesLikeBackend(&data))
+
+		It("should move done handles to the archive", func() {
+			subject := data.Subject
+			defer subject.Close()
+
+			h, err := subject.Acquire(ctx, "THEOWNER", "ns", "name", time.Now().Add(time.Minute), nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subject.Done(ctx, "THEOWNER", h.ID, nil, nil)).To(Succeed())
+
+			var live, archived int
+			Expect(db.QueryRow("SELECT COUNT(*) FROM resource_handles").Scan(&live)).To(Succeed())
+			Expect(db.QueryRow("SELECT COUNT(*) FROM resource_handles_done").Scan(&archived)).To(Succeed())
+			Expect(live).To(Equal(0))
+			Expect(archived).To(Equal(1))
+
+			_, err = subject.Acquire(ctx, "OTHERONE", "ns", "name", time.Now().Add(time.Minute), nil, nil)
+			Expect(err).To(Equal(accord.ErrDone))
+			Expect(db.QueryRow("SELECT COUNT(*) FROM resource_handles").Scan(&live)).To(Succeed())
+			Expect(live).To(Equal(0))
+
+			var num int
+			Expect(subject.List(ctx, &rpc.ListRequest{
+				Filter: &rpc.ListRequest_Filter{Status: rpc.ListRequest_Filter_DONE},
+			}, func(*backend.HandleData) error {
+				num++
+				return nil
+			})).To(Succeed())
+			Expect(num).To(Equal(1))
+		})
+	})
+
+	Context("with replica", func() {
+		var replica *sql.DB
+
+		BeforeEach(func() {
+			var err error
+			replica, err = sql.Open("postgres", os.Getenv("DATABASE_DSN"))
+			Expect(err).NotTo(HaveOccurred())
+
+			data.Subject, err = postgres.OpenDB(ctx, db, &postgres.Options{Replica: replica})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			_ = replica.Close()
+		})
+
+		Context("conformance", backendtest.BehavesLikeBackend(&data))
+
+		It("should fall back to the primary when the replica is unhealthy", func() {
+			subject := data.Subject
+			defer subject.Close()
+
+			h, err := subject.Acquire(ctx, "THEOWNER", "ns", "name", time.Now().Add(time.Minute), nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(replica.Close()).To(Succeed())
+
+			Expect(subject.Get(ctx, h.ID)).To(Equal(h))
+
+			var num int
+			Expect(subject.List(ctx, &rpc.ListRequest{}, func(*backend.HandleData) error {
+				num++
+				return nil
+			})).To(Succeed())
+			Expect(num).To(Equal(1))
+		})
+
+		It("should validate tokens on the primary", func() {
+			subject := data.Subject
+			defer subject.Close()
+
+			h, err := subject.Acquire(ctx, "THEOWNER", "ns", "name", time.Now().Add(time.Minute), nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(replica.Close()).To(Succeed())
+
+			Expect(subject.ValidateToken(ctx, h.ID, h.FencingToken)).To(Succeed())
+			Expect(subject.ValidateToken(ctx, h.ID, h.FencingToken+1)).To(Equal(backend.ErrStaleToken))
+		})
+	})
+
+	It("should skip migrations", func() {
+		_, err := postgres.OpenDB(ctx, db, &postgres.Options{Schema: "accord_test", SkipMigrate: true})
+		Expect(err).NotTo(HaveOccurred())
+
+		var num int
+		Expect(db.QueryRow("SELECT COUNT(*) FROM information_schema.schemata WHERE schema_name = 'accord_test'").Scan(&num)).To(Succeed())
+		Expect(num).To(Equal(0))
+
+		Expect(postgres.Migrate(ctx, db, &postgres.Options{Schema: "accord_test"})).To(Succeed())
+		Expect(db.QueryRow("SELECT COUNT(*) FROM information_schema.schemata WHERE schema_name = 'accord_test'").Scan(&num)).To(Succeed())
+		Expect(num).To(Equal(1))
+	})
+
+	It("should migrate up and down", func() {
+		opt := &postgres.Options{Schema: "accord_test"}
+		m, err := postgres.NewMigrator(db, opt)
+		Expect(err).NotTo(HaveOccurred())
+
+		version := func() int {
+			current, _, err := m.Version(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			return current
+		}
+
+		Expect(version()).To(Equal(0))
+		Expect(m.Plan(ctx, 3)).To(ContainElements(
+			"CREATE SCHEMA IF NOT EXISTS accord_test",
+			"UPDATE accord_test.meta_info SET version = 3",
+		))
+
+		Expect(m.Up(ctx)).To(Succeed())
+		current, latest, err := m.Version(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(current).To(Equal(latest))
+		Expect(m.Plan(ctx, latest)).To(BeEmpty())
+
+		Expect(m.Down(ctx)).To(Succeed())
+		Expect(version()).To(Equal(latest - 1))
+		Expect(m.MigrateTo(ctx, 0)).To(Succeed())
+		Expect(version()).To(Equal(0))
+		Expect(m.Up(ctx)).To(Succeed())
+		Expect(version()).To(Equal(latest))
+	})
+
+	It("should migrate concurrently", func() {
+		var wg sync.WaitGroup
+		errs := make(chan error, 5)
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- postgres.Migrate(ctx, db, &postgres.Options{Schema: "accord_test"})
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("should validate options", func() {
+		_, err := postgres.OpenDB(ctx, db, &postgres.Options{Schema: "bad schema"})
+		Expect(err).To(MatchError(`accord: invalid schema name "bad schema"`))
+		_, err = postgres.OpenDB(ctx, db, &postgres.Options{TablePrefix: "Bad-"})
+		Expect(err).To(MatchError(`accord: invalid table prefix "Bad-"`))
+		_, err = postgres.OpenDB(ctx, db, &postgres.Options{Replica: db, ReplicaPool: &pgxpool.Pool{}})
+		Expect(err).To(MatchError(`accord: only one of Replica and ReplicaPool may be set`))
+	})
 })
 
 // ------------------------------------------------------------------------
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const replicaCheckInterval = time.Second
+
+var errReplicaLag = errors.New("accord: replica lag exceeded")
+
+// replica routes read-only queries to a read replica while it is healthy.
+type replica struct {
+	conn   conn
+	maxLag time.Duration
+
+	mu        sync.Mutex
+	healthy   bool
+	checking  bool
+	checkedAt time.Time
+}
+
+// isHealthy returns the cached health status, re-checking it at most once
+// per replicaCheckInterval. The check runs outside the lock, concurrent
+// callers keep using the cached status in the meantime.
+func (r *replica) isHealthy() bool {
+	r.mu.Lock()
+	healthy := r.healthy
+	due := !r.checking && time.Since(r.checkedAt) >= replicaCheckInterval
+	if due {
+		r.checking = true
+	}
+	started := r.checkedAt
+	r.mu.Unlock()
+
+	if !due {
+		return healthy
+	}
+
+	err := r.check()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checking = false
+	if r.checkedAt.Equal(started) { // not marked unhealthy in the meantime
+		r.healthy = err == nil
+		r.checkedAt = time.Now()
+	}
+	return r.healthy
+}
+
+// markUnhealthy routes reads to the primary until the next check.
+func (r *replica) markUnhealthy() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.healthy = false
+	r.checkedAt = time.Now()
+}
+
+// check measures the replication lag as the age of the last replayed
+// transaction. It is 0 when the replica is not in recovery mode or has
+// replayed all received WAL, so an idle primary does not count as lag.
+func (r *replica) check() error {
+	ctx, cancel := context.WithTimeout(context.Background(), replicaCheckInterval)
+	defer cancel()
+
+	var lag float64
+	if err := r.conn.queryRow(ctx, `
+		SELECT CASE
+			WHEN NOT pg_is_in_recovery() THEN 0
+			WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+		END::float8
+	`).Scan(&lag); err != nil {
+		return err
+	}
+	if time.Duration(lag*float64(time.Second)) > r.maxLag {
+		return errReplicaLag
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package raft
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/rpc"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/status"
+)
+
+// forward forwards a command to the leader via its accord gRPC API.
+func (b *Backend) forward(ctx context.Context, cmd *command) (*applyResult, error) {
+	client, err := b.leaderClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch cmd.Op {
+	case opAcquire:
+		res, err := client.Acquire(ctx, &rpc.AcquireRequest{
+			Owner:     cmd.Owner,
+			Name:      cmd.Name,
+			Namespace: cmd.Namespace,
+			Ttl:       ttlSeconds(cmd.ExpTime),
+			Metadata:  cmd.Metadata,
+			Capacity:  uint32(cmd.Capacity),
+			Mode:      cmd.Mode,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		switch res.Status {
+		case rpc.Status_DONE:
+			return nil, accord.ErrDone
+		case rpc.Status_HELD:
+			return nil, accord.ErrAcquired
+		}
+
+		handle, err := convertHandle(cmd.Owner, res.Handle)
+		if err != nil {
+			return nil, err
+		}
+		return &applyResult{handle: handle}, nil
+	case opRenew:
+		_, err := client.Renew(ctx, &rpc.RenewRequest{
+			Owner:           cmd.Owner,
+			HandleId:        cmd.ID[:],
+			Ttl:             ttlSeconds(cmd.ExpTime),
+			Metadata:        cmd.Metadata,
+			ExpectedVersion: uint64(cmd.Version),
+			DeleteMetadata:  cmd.Delete,
+			ReplaceMetadata: cmd.Replace,
+			FencingToken:    uint64(cmd.Token),
+		})
+		return &applyResult{}, normError(err)
+	case opDone:
+		_, err := client.Done(ctx, &rpc.DoneRequest{
+			Owner:           cmd.Owner,
+			HandleId:        cmd.ID[:],
+			Metadata:        cmd.Metadata,
+			ExpectedVersion: uint64(cmd.Version),
+			DeleteMetadata:  cmd.Delete,
+			ReplaceMetadata: cmd.Replace,
+			FencingToken:    uint64(cmd.Token),
+		})
+		return &applyResult{}, normError(err)
+	case opTransfer:
+		res, err := client.Transfer(ctx, &rpc.TransferRequest{
+			Owner:    cmd.Owner,
+			HandleId: cmd.ID[:],
+			NewOwner: cmd.NewOwner,
+			RotateId: cmd.NewID != uuid.Nil,
+		})
+		if err != nil {
+			return nil, normError(err)
+		}
+
+		handle, err := convertHandle(cmd.NewOwner, res.Handle)
+		if err != nil {
+			return nil, err
+		}
+		return &applyResult{handle: handle}, nil
+	case opPurge:
+		res, err := client.Purge(ctx, &rpc.PurgeRequest{
+			Prefix:        cmd.Namespace,
+			Exclude:       cmd.Exclude,
+			DoneBeforeTms: timeToMillis(cmd.Time),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &applyResult{num: int64(res.NumPurged)}, nil
+	case opMarkDone:
+		return forwardMarkDone(ctx, client, cmd.Records)
+	}
+	return nil, errUnknownCommand
+}
+
+// forwardValidateToken validates a fencing token on the leader.
+func (b *Backend) forwardValidateToken(ctx context.Context, handleID uuid.UUID, token int64) error {
+	client, err := b.leaderClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.ValidateToken(ctx, &rpc.ValidateTokenRequest{
+		HandleId:     handleID[:],
+		FencingToken: uint64(token),
+	})
+	if err != nil {
+		return err
+	} else if !res.Valid {
+		return backend.ErrStaleToken
+	}
+	return nil
+}
+
+// forwardPing checks that the leader is able to reach a quorum of nodes.
+func (b *Backend) forwardPing(ctx context.Context) error {
+	client, err := b.leaderClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.Check(ctx, &rpc.CheckRequest{})
+	return err
+}
+
+func forwardMarkDone(ctx context.Context, client rpc.V1Client, records []doneRecord) (*applyResult, error) {
+	stream, err := client.MarkDone(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range records {
+		if err := stream.Send(&rpc.MarkDoneRequest{
+			Owner:     rec.Owner,
+			Name:      rec.Name,
+			Namespace: rec.Namespace,
+			DoneTms:   timeToMillis(rec.DoneTime),
+			Metadata:  rec.Metadata,
+		}); err != nil {
+			break // the error is returned by CloseAndRecv
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(records))
+	for _, c := range res.Conflicts {
+		if c.Index >= uint64(len(errs)) {
+			continue
+		}
+		switch c.Status {
+		case rpc.Status_DONE:
+			errs[c.Index] = accord.ErrDone
+		case rpc.Status_HELD:
+			errs[c.Index] = accord.ErrAcquired
+		}
+	}
+	return &applyResult{errs: errs}, nil
+}
+
+func convertHandle(owner string, h *rpc.Handle) (*backend.HandleData, error) {
+	handleID, err := uuid.FromBytes(h.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	// older nodes do not transmit the owner
+	if h.Owner != "" {
+		owner = h.Owner
+	}
+
+	return &backend.HandleData{
+		ID:            handleID,
+		Namespace:     h.Namespace,
+		Name:          h.Name,
+		Owner:         owner,
+		PreviousOwner: h.PreviousOwner,
+		CreatedTime:   h.CreatedTime(),
+		UpdatedTime:   h.UpdatedTime(),
+		ExpTime:       h.ExpTime(),
+		DoneTime:      h.DoneTime(),
+		NumAcquired:   int(h.NumAcquired),
+		Metadata:      h.Metadata,
+		MetaVersion:   int64(h.MetadataVersion),
+		FencingToken:  int64(h.FencingToken),
+		Slot:          int(h.Slot),
+		Capacity:      int(h.Capacity),
+		Mode:          h.Mode,
+		Queued:        h.Queued,
+	}, nil
+}
+
+// normError restores backend errors which were transmitted over gRPC.
+func normError(err error) error {
+	if err == nil || errors.Is(err, backend.ErrInvalidHandle) || errors.Is(err, backend.ErrVersionMismatch) || errors.Is(err, backend.ErrStaleToken) {
+		return err
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Message() {
+		case backend.ErrInvalidHandle.Error():
+			return backend.ErrInvalidHandle
+		case backend.ErrVersionMismatch.Error():
+			return backend.ErrVersionMismatch
+		case backend.ErrStaleToken.Error():
+			return backend.ErrStaleToken
+		}
+	}
+	return err
+}
+
+func timeToMillis(t time.Time) int64 {
+	return t.UnixNano() / 1e6
+}
+
+func ttlSeconds(exp time.Time) uint32 {
+	if secs := math.Round(time.Until(exp).Seconds()); secs > 0 {
+		return uint32(secs)
+	}
+	return 0
+}
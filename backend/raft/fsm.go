@@ -0,0 +1,457 @@
+package raft
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/rpc"
+	"github.com/google/uuid"
+	hraft "github.com/hashicorp/raft"
+)
+
+type opType uint8
+
+const (
+	opAcquire opType = iota + 1
+	opRenew
+	opDone
+	opPurge
+	opMarkDone
+	opTransfer
+)
+
+// command is a replicated state transition. All non-deterministic
+// values (IDs, timestamps) are determined by the leader before the
+// command is appended to the log.
+type command struct {
+	Op        opType            `json:"op"`
+	ID        uuid.UUID         `json:"id"`
+	Owner     string            `json:"owner"`
+	Namespace string            `json:"namespace,omitempty"`
+	Name      string            `json:"name,omitempty"`
+	Time      time.Time         `json:"time"`
+	ExpTime   time.Time         `json:"exp_time"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Exclude   []string          `json:"exclude,omitempty"`
+	Records   []doneRecord      `json:"records,omitempty"`
+	Version   int64             `json:"version,omitempty"`
+	Delete    []string          `json:"delete,omitempty"`
+	Replace   bool              `json:"replace,omitempty"`
+	Token     int64             `json:"token,omitempty"`
+	NewOwner  string            `json:"new_owner,omitempty"`
+	NewID     uuid.UUID         `json:"new_id"`
+	Capacity  int               `json:"capacity,omitempty"`
+	Mode      rpc.Mode          `json:"mode,omitempty"`
+}
+
+func (c *command) updateOptions() *backend.UpdateOptions {
+	return &backend.UpdateOptions{
+		ExpectedVersion: c.Version,
+		DeleteMeta:      c.Delete,
+		ReplaceMeta:     c.Replace,
+		FencingToken:    c.Token,
+	}
+}
+
+// doneRecord is a resource to be inserted as done by opMarkDone.
+type doneRecord struct {
+	ID        uuid.UUID         `json:"id"`
+	Owner     string            `json:"owner"`
+	Namespace string            `json:"namespace,omitempty"`
+	Name      string            `json:"name"`
+	DoneTime  time.Time         `json:"done_time"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+type applyResult struct {
+	handle *backend.HandleData
+	num    int64
+	errs   []error
+	err    error
+}
+
+type fullName struct {
+	Namespace, Name string
+}
+
+// fsm implements the hraft.FSM interface.
+type fsm struct {
+	byName map[fullName][]*backend.HandleData // indexed by slot
+	byID   map[uuid.UUID]*backend.HandleData
+	asList []*backend.HandleData
+	mu     sync.RWMutex
+}
+
+func newFSM() *fsm {
+	return &fsm{
+		byName: make(map[fullName][]*backend.HandleData),
+		byID:   make(map[uuid.UUID]*backend.HandleData),
+	}
+}
+
+// Apply implements the hraft.FSM interface.
+func (f *fsm) Apply(log *hraft.Log) interface{} {
+	var cmd command
+	if err := json.Unmarshal(log.Data, &cmd); err != nil {
+		return &applyResult{err: err}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch cmd.Op {
+	case opAcquire:
+		return f.acquire(&cmd, int64(log.Index))
+	case opRenew:
+		return f.renew(&cmd)
+	case opDone:
+		return f.done(&cmd)
+	case opPurge:
+		return f.purge(&cmd)
+	case opMarkDone:
+		return f.markDone(&cmd, int64(log.Index))
+	case opTransfer:
+		return f.transfer(&cmd, int64(log.Index))
+	}
+	return &applyResult{err: errUnknownCommand}
+}
+
+// acquire acquires a resource, the log index is used as the fencing token.
+func (f *fsm) acquire(cmd *command, index int64) *applyResult {
+	handle := &backend.HandleData{
+		ID:           cmd.ID,
+		Namespace:    cmd.Namespace,
+		Name:         cmd.Name,
+		ExpTime:      cmd.ExpTime,
+		NumAcquired:  1,
+		Owner:        cmd.Owner,
+		CreatedTime:  cmd.Time,
+		UpdatedTime:  cmd.Time,
+		Metadata:     cmd.Metadata,
+		MetaVersion:  1,
+		FencingToken: index,
+		Capacity:     max(cmd.Capacity, 1), // commands logged before semaphores
+		Mode:         cmd.Mode,
+	}
+
+	key := fullName{Namespace: cmd.Namespace, Name: cmd.Name}
+	opt := &backend.AcquireOptions{Capacity: handle.Capacity, Mode: cmd.Mode}
+	slot, queued, err := backend.SelectSlot(f.byName[key], cmd.Owner, opt, cmd.Time)
+	if err != nil {
+		return &applyResult{err: err}
+	}
+
+	handle.Slot = slot
+	handle.Queued = queued
+	if stored := f.slot(key, slot); stored != nil {
+		handle.NumAcquired = stored.NumAcquired + 1
+		handle.PreviousOwner = stored.Owner
+		handle.CreatedTime = stored.CreatedTime
+		handle.UpdateMetadata(stored.Metadata)
+		handle.MetaVersion = stored.MetaVersion
+		if !maps.Equal(handle.Metadata, stored.Metadata) {
+			handle.MetaVersion++
+		}
+		f.replace(stored, handle)
+	} else {
+		f.asList = append(f.asList, handle)
+	}
+
+	f.byID[handle.ID] = handle
+	f.store(key, handle)
+
+	if handle.Queued {
+		return &applyResult{err: accord.ErrAcquired}
+	}
+	return &applyResult{handle: copyHandle(handle)}
+}
+
+// slot returns the handle stored in a slot of the resource, if any.
+func (f *fsm) slot(key fullName, slot int) *backend.HandleData {
+	if slots := f.byName[key]; slot < len(slots) {
+		return slots[slot]
+	}
+	return nil
+}
+
+// store stores the handle in its slot.
+func (f *fsm) store(key fullName, handle *backend.HandleData) {
+	slots := f.byName[key]
+	for len(slots) <= handle.Slot {
+		slots = append(slots, nil)
+	}
+	slots[handle.Slot] = handle
+	f.byName[key] = slots
+}
+
+// unstore clears a slot of the resource.
+func (f *fsm) unstore(key fullName, slot int) {
+	slots := f.byName[key]
+	if slot < len(slots) {
+		slots[slot] = nil
+	}
+	for len(slots) != 0 && slots[len(slots)-1] == nil {
+		slots = slots[:len(slots)-1]
+	}
+	if len(slots) == 0 {
+		delete(f.byName, key)
+	} else {
+		f.byName[key] = slots
+	}
+}
+
+// replace replaces a stored handle, retaining its position.
+func (f *fsm) replace(stored, handle *backend.HandleData) {
+	delete(f.byID, stored.ID)
+	for i, h := range f.asList {
+		if h == stored {
+			f.asList[i] = handle
+			break
+		}
+	}
+}
+
+func (f *fsm) renew(cmd *command) *applyResult {
+	stored, ok := f.byID[cmd.ID]
+	if !ok || stored.IsDone() || stored.Owner != cmd.Owner {
+		return &applyResult{err: backend.ErrInvalidHandle}
+	}
+
+	if err := stored.ApplyUpdate(cmd.Metadata, cmd.updateOptions()); err != nil {
+		return &applyResult{err: err}
+	}
+	stored.ExpTime = cmd.ExpTime
+	stored.UpdatedTime = cmd.Time
+	return &applyResult{}
+}
+
+func (f *fsm) done(cmd *command) *applyResult {
+	stored, ok := f.byID[cmd.ID]
+	if !ok || stored.IsDone() || stored.Owner != cmd.Owner {
+		return &applyResult{err: backend.ErrInvalidHandle}
+	}
+
+	if err := stored.ApplyUpdate(cmd.Metadata, cmd.updateOptions()); err != nil {
+		return &applyResult{err: err}
+	}
+	stored.DoneTime = cmd.Time
+	stored.UpdatedTime = cmd.Time
+	return &applyResult{}
+}
+
+func (f *fsm) transfer(cmd *command, index int64) *applyResult {
+	stored, ok := f.byID[cmd.ID]
+	if !ok || !stored.IsHeld(cmd.Time) || stored.Owner != cmd.Owner {
+		return &applyResult{err: backend.ErrInvalidHandle}
+	}
+
+	if cmd.NewID != uuid.Nil {
+		delete(f.byID, stored.ID)
+		stored.ID = cmd.NewID
+		f.byID[stored.ID] = stored
+	}
+	stored.PreviousOwner = stored.Owner
+	stored.Owner = cmd.NewOwner
+	stored.UpdatedTime = cmd.Time
+	stored.FencingToken = index
+	return &applyResult{handle: copyHandle(stored)}
+}
+
+func (f *fsm) markDone(cmd *command, index int64) *applyResult {
+	errs := make([]error, len(cmd.Records))
+	for i, rec := range cmd.Records {
+		key := fullName{Namespace: rec.Namespace, Name: rec.Name}
+		if stored := f.slot(key, 0); stored != nil && stored.IsDone() {
+			errs[i] = accord.ErrDone
+			continue
+		} else if stored != nil {
+			errs[i] = accord.ErrAcquired
+			continue
+		}
+
+		handle := &backend.HandleData{
+			ID:           rec.ID,
+			Namespace:    rec.Namespace,
+			Name:         rec.Name,
+			Owner:        rec.Owner,
+			CreatedTime:  cmd.Time,
+			UpdatedTime:  cmd.Time,
+			ExpTime:      rec.DoneTime,
+			DoneTime:     rec.DoneTime,
+			NumAcquired:  1,
+			MetaVersion:  1,
+			FencingToken: index,
+			Capacity:     1,
+			Metadata:     rec.Metadata,
+		}
+		f.asList = append(f.asList, handle)
+		f.byID[handle.ID] = handle
+		f.store(key, handle)
+	}
+	return &applyResult{errs: errs}
+}
+
+func (f *fsm) purge(cmd *command) *applyResult {
+	filter := &backend.PurgeFilter{Prefix: cmd.Namespace, Exclude: cmd.Exclude, Before: cmd.Time}
+
+	var num int64
+	retained := f.asList[:0]
+	for _, handle := range f.asList {
+		if !filter.Matches(handle) {
+			retained = append(retained, handle)
+			continue
+		}
+
+		delete(f.byID, handle.ID)
+		f.unstore(fullName{Namespace: handle.Namespace, Name: handle.Name}, handle.Slot)
+		num++
+	}
+	for i := len(retained); i < len(f.asList); i++ {
+		f.asList[i] = nil
+	}
+	f.asList = retained
+	return &applyResult{num: num}
+}
+
+// Get returns a copy of the stored handle.
+func (f *fsm) Get(handleID uuid.UUID) *backend.HandleData {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if stored, ok := f.byID[handleID]; ok {
+		return copyHandle(stored)
+	}
+	return nil
+}
+
+// List iterates over stored handles, newest first.
+func (f *fsm) List(req *rpc.ListRequest, iter backend.Iterator) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	cursor, err := backend.ParseListCursor(req)
+	if err != nil {
+		return err
+	}
+
+	filter, err := backend.ParseListFilter(req.GetFilter())
+	if err != nil {
+		return err
+	}
+
+	var handles []*backend.HandleData
+	for i := len(f.asList) - 1; i >= 0; i-- {
+		if handle := f.asList[i]; cursor.Precedes(handle) && filter.Matches(handle) {
+			handles = append(handles, handle)
+		}
+	}
+	backend.SortHandles(handles, req)
+
+	offset := req.GetOffset()
+	limit := req.GetLimit()
+	for _, handle := range handles {
+		if offset != 0 {
+			offset--
+			continue
+		}
+		if err := iter(copyHandle(handle)); err == backend.ErrIteratorDone {
+			break
+		} else if err != nil {
+			return err
+		}
+		if limit != 0 {
+			if limit--; limit == 0 {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Count counts stored handles matching the filter.
+func (f *fsm) Count(req *rpc.ListRequest_Filter) (int64, error) {
+	filter, err := backend.ParseListFilter(req)
+	if err != nil {
+		return 0, err
+	}
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var num int64
+	for _, handle := range f.asList {
+		if filter.Matches(handle) {
+			num++
+		}
+	}
+	return num, nil
+}
+
+// Snapshot implements the hraft.FSM interface.
+func (f *fsm) Snapshot() (hraft.FSMSnapshot, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	handles := make([]*backend.HandleData, 0, len(f.asList))
+	for _, handle := range f.asList {
+		handles = append(handles, copyHandle(handle))
+	}
+	return &fsmSnapshot{handles: handles}, nil
+}
+
+// Restore implements the hraft.FSM interface.
+func (f *fsm) Restore(rc io.ReadCloser) error {
+	defer rc.Close()
+
+	var handles []*backend.HandleData
+	if err := json.NewDecoder(rc).Decode(&handles); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.byName = make(map[fullName][]*backend.HandleData, len(handles))
+	f.byID = make(map[uuid.UUID]*backend.HandleData, len(handles))
+	f.asList = f.asList[:0]
+	for _, handle := range handles {
+		f.byID[handle.ID] = handle
+		f.store(fullName{Namespace: handle.Namespace, Name: handle.Name}, handle)
+		f.asList = append(f.asList, handle)
+	}
+	return nil
+}
+
+// --------------------------------------------------------------------
+
+type fsmSnapshot struct {
+	handles []*backend.HandleData
+}
+
+// Persist implements the hraft.FSMSnapshot interface.
+func (s *fsmSnapshot) Persist(sink hraft.SnapshotSink) error {
+	if err := json.NewEncoder(sink).Encode(s.handles); err != nil {
+		_ = sink.Cancel()
+		return err
+	}
+	return sink.Close()
+}
+
+// Release implements the hraft.FSMSnapshot interface.
+func (*fsmSnapshot) Release() {}
+
+// --------------------------------------------------------------------
+
+func copyHandle(h *backend.HandleData) *backend.HandleData {
+	c := *h
+	c.Metadata = nil
+	if h.Metadata != nil {
+		c.UpdateMetadata(h.Metadata)
+	}
+	return &c
+}
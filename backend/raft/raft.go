@@ -0,0 +1,473 @@
+// Package raft implements an embedded backend which replicates state across
+// a cluster of accord servers using the raft consensus protocol, removing
+// the need for an external database.
+//
+// All writes are appended to the replicated log by the current leader,
+// followers forward writes to the leader through its accord gRPC API. Reads
+// (Get and List) are served from the local state and may be stale on
+// followers, fencing tokens are always validated by the leader.
+package raft
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/rpc"
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-hclog"
+	hraft "github.com/hashicorp/raft"
+	raftboltdb "github.com/hashicorp/raft-boltdb/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+var (
+	// ErrNoLeader is returned when the cluster has no elected leader, i.e.
+	// when it has lost its quorum.
+	ErrNoLeader = errors.New("accord: raft cluster has no leader")
+
+	errUnknownCommand = errors.New("accord: unknown raft command")
+)
+
+var _ backend.Backend = (*Backend)(nil)
+
+// Peer describes a member of the cluster.
+type Peer struct {
+	ID      string // unique node identifier
+	Address string // raft transport address
+	RPCAddr string // accord gRPC address, used to forward writes to the leader
+}
+
+// Options contains options for the raft backend.
+type Options struct {
+	// Dir is the data directory for the raft log and snapshots.
+	// Required unless LogStore, StableStore and SnapshotStore are all set.
+	Dir string
+
+	// Config is a custom raft configuration, default: hraft.DefaultConfig().
+	// The LocalID will be set automatically.
+	Config *hraft.Config
+
+	// Custom storage and transport, useful for testing.
+	// Default: BoltDB log/stable store and file snapshot store within Dir
+	// and a TCP transport bound to the Address of the local peer.
+	LogStore      hraft.LogStore
+	StableStore   hraft.StableStore
+	SnapshotStore hraft.SnapshotStore
+	Transport     hraft.Transport
+
+	// ApplyTimeout limits the time to wait for a command to be committed.
+	// Default: 10s.
+	ApplyTimeout time.Duration
+
+	// Dial connects to the accord gRPC API of a peer. It is used by
+	// followers to forward writes to the leader.
+	// Default: insecure gRPC connection to Peer.RPCAddr.
+	Dial func(context.Context, Peer) (rpc.V1Client, error)
+}
+
+func (o *Options) norm() *Options {
+	var p Options
+	if o != nil {
+		p = *o
+	}
+
+	if p.Config == nil {
+		p.Config = hraft.DefaultConfig()
+	} else {
+		c := *p.Config
+		p.Config = &c
+	}
+	if p.ApplyTimeout <= 0 {
+		p.ApplyTimeout = 10 * time.Second
+	}
+	return &p
+}
+
+// Backend is a raft-replicated backend.
+type Backend struct {
+	raft  *hraft.Raft
+	fsm   *fsm
+	peers map[hraft.ServerID]Peer
+	opt   *Options
+
+	closers []io.Closer
+	conns   map[hraft.ServerID]rpc.V1Client
+	ccs     []*grpc.ClientConn
+	connMu  sync.Mutex
+}
+
+// Open starts a new raft node identified by self. The peers must contain
+// all members of the cluster, including self, and must be identical on all
+// nodes. A fresh cluster is bootstrapped automatically.
+func Open(self string, peers []Peer, opt *Options) (*Backend, error) {
+	opt = opt.norm()
+	opt.Config.LocalID = hraft.ServerID(self)
+
+	b := &Backend{
+		fsm:   newFSM(),
+		peers: make(map[hraft.ServerID]Peer, len(peers)),
+		opt:   opt,
+		conns: make(map[hraft.ServerID]rpc.V1Client),
+	}
+
+	servers := make([]hraft.Server, 0, len(peers))
+	for _, peer := range peers {
+		b.peers[hraft.ServerID(peer.ID)] = peer
+		servers = append(servers, hraft.Server{
+			ID:      hraft.ServerID(peer.ID),
+			Address: hraft.ServerAddress(peer.Address),
+		})
+	}
+	if _, ok := b.peers[opt.Config.LocalID]; !ok {
+		return nil, fmt.Errorf("accord: unknown raft peer %q", self)
+	}
+
+	if err := b.open(servers); err != nil {
+		_ = b.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *Backend) open(servers []hraft.Server) error {
+	opt := b.opt
+	if opt.LogStore == nil || opt.StableStore == nil || opt.SnapshotStore == nil {
+		if opt.Dir == "" {
+			return errors.New("accord: raft data directory is required")
+		}
+		if err := os.MkdirAll(opt.Dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	if opt.LogStore == nil || opt.StableStore == nil {
+		store, err := raftboltdb.NewBoltStore(filepath.Join(opt.Dir, "raft.db"))
+		if err != nil {
+			return err
+		}
+		b.closers = append(b.closers, store)
+
+		if opt.LogStore == nil {
+			opt.LogStore = store
+		}
+		if opt.StableStore == nil {
+			opt.StableStore = store
+		}
+	}
+
+	if opt.SnapshotStore == nil {
+		snaps, err := hraft.NewFileSnapshotStore(opt.Dir, 2, os.Stderr)
+		if err != nil {
+			return err
+		}
+		opt.SnapshotStore = snaps
+	}
+
+	if opt.Config.Logger == nil {
+		opt.Config.Logger = hclog.New(&hclog.LoggerOptions{Name: "accord-raft", Level: hclog.Warn})
+	}
+
+	if opt.Transport == nil {
+		self := b.peers[opt.Config.LocalID]
+		advertise, err := net.ResolveTCPAddr("tcp", self.Address)
+		if err != nil {
+			return err
+		}
+		trans, err := hraft.NewTCPTransportWithLogger(self.Address, advertise, 3, 10*time.Second, opt.Config.Logger)
+		if err != nil {
+			return err
+		}
+		b.closers = append(b.closers, trans)
+		opt.Transport = trans
+	}
+
+	hasState, err := hraft.HasExistingState(opt.LogStore, opt.StableStore, opt.SnapshotStore)
+	if err != nil {
+		return err
+	}
+	if !hasState {
+		if err := hraft.BootstrapCluster(opt.Config, opt.LogStore, opt.StableStore, opt.SnapshotStore, opt.Transport, hraft.Configuration{Servers: servers}); err != nil {
+			return err
+		}
+	}
+
+	node, err := hraft.NewRaft(opt.Config, b.fsm, opt.LogStore, opt.StableStore, opt.SnapshotStore, opt.Transport)
+	if err != nil {
+		return err
+	}
+	b.raft = node
+	return nil
+}
+
+// Raft returns the underlying raft node.
+func (b *Backend) Raft() *hraft.Raft {
+	return b.raft
+}
+
+// IsLeader returns true if the node is the current leader.
+func (b *Backend) IsLeader() bool {
+	return b.raft.State() == hraft.Leader
+}
+
+// Acquire implements the backend.Backend interface.
+func (b *Backend) Acquire(ctx context.Context, owner, namespace, name string, exp time.Time, metadata map[string]string, opt *backend.AcquireOptions) (*backend.HandleData, error) {
+	res, err := b.apply(ctx, &command{
+		Op:        opAcquire,
+		ID:        uuid.New(),
+		Owner:     owner,
+		Namespace: namespace,
+		Name:      name,
+		Time:      time.Now(),
+		ExpTime:   exp,
+		Metadata:  metadata,
+		Capacity:  opt.GetCapacity(),
+		Mode:      opt.GetMode(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.handle, nil
+}
+
+// Renew implements the backend.Backend interface.
+func (b *Backend) Renew(ctx context.Context, owner string, handleID uuid.UUID, exp time.Time, metadata map[string]string, opt *backend.UpdateOptions) error {
+	_, err := b.apply(ctx, &command{
+		Op:       opRenew,
+		ID:       handleID,
+		Owner:    owner,
+		Time:     time.Now(),
+		ExpTime:  exp,
+		Metadata: metadata,
+		Version:  opt.GetExpectedVersion(),
+		Delete:   opt.GetDeleteMeta(),
+		Replace:  opt.GetReplaceMeta(),
+		Token:    opt.GetFencingToken(),
+	})
+	return err
+}
+
+// Done implements the backend.Backend interface.
+func (b *Backend) Done(ctx context.Context, owner string, handleID uuid.UUID, metadata map[string]string, opt *backend.UpdateOptions) error {
+	_, err := b.apply(ctx, &command{
+		Op:       opDone,
+		ID:       handleID,
+		Owner:    owner,
+		Time:     time.Now(),
+		Metadata: metadata,
+		Version:  opt.GetExpectedVersion(),
+		Delete:   opt.GetDeleteMeta(),
+		Replace:  opt.GetReplaceMeta(),
+		Token:    opt.GetFencingToken(),
+	})
+	return err
+}
+
+// Transfer implements the backend.Backend interface.
+func (b *Backend) Transfer(ctx context.Context, owner string, handleID uuid.UUID, newOwner string, rotateID bool) (*backend.HandleData, error) {
+	cmd := &command{
+		Op:       opTransfer,
+		ID:       handleID,
+		Owner:    owner,
+		Time:     time.Now(),
+		NewOwner: newOwner,
+	}
+	if rotateID {
+		cmd.NewID = uuid.New()
+	}
+
+	res, err := b.apply(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return res.handle, nil
+}
+
+// MarkDone implements the backend.Backend interface.
+func (b *Backend) MarkDone(ctx context.Context, records []backend.DoneRecord) ([]error, error) {
+	now := time.Now()
+	cmd := &command{Op: opMarkDone, Time: now, Records: make([]doneRecord, 0, len(records))}
+	for _, rec := range records {
+		doneTime := rec.DoneTime
+		if doneTime.IsZero() {
+			doneTime = now
+		}
+		cmd.Records = append(cmd.Records, doneRecord{
+			ID:        uuid.New(),
+			Owner:     rec.Owner,
+			Namespace: rec.Namespace,
+			Name:      rec.Name,
+			DoneTime:  doneTime,
+			Metadata:  rec.Metadata,
+		})
+	}
+
+	res, err := b.apply(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return res.errs, nil
+}
+
+// Purge implements the backend.Backend interface.
+func (b *Backend) Purge(ctx context.Context, filter *backend.PurgeFilter) (int64, error) {
+	res, err := b.apply(ctx, &command{
+		Op:        opPurge,
+		Namespace: filter.Prefix,
+		Exclude:   filter.Exclude,
+		Time:      filter.Before,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.num, nil
+}
+
+// Get implements the backend.Backend interface.
+func (b *Backend) Get(_ context.Context, handleID uuid.UUID) (*backend.HandleData, error) {
+	return b.fsm.Get(handleID), nil
+}
+
+// ValidateToken implements the backend.Backend interface. Unlike Get, it is
+// served by the leader once all committed commands have been applied.
+func (b *Backend) ValidateToken(ctx context.Context, handleID uuid.UUID, token int64) error {
+	if b.IsLeader() {
+		err := b.raft.Barrier(b.timeout(ctx)).Error()
+		if err == nil {
+			return backend.ValidateFencingToken(b.fsm.Get(handleID), token)
+		} else if err != hraft.ErrNotLeader {
+			return err
+		}
+	}
+	return b.forwardValidateToken(ctx, handleID, token)
+}
+
+// List implements the backend.Backend interface.
+func (b *Backend) List(_ context.Context, req *rpc.ListRequest, iter backend.Iterator) error {
+	return b.fsm.List(req, iter)
+}
+
+// Count implements the backend.Backend interface.
+func (b *Backend) Count(_ context.Context, filter *rpc.ListRequest_Filter) (int64, error) {
+	return b.fsm.Count(filter)
+}
+
+// Ping implements the backend.Backend interface. It fails unless the
+// cluster has a leader which is able to reach a quorum of nodes. Followers
+// forward the check to the leader.
+func (b *Backend) Ping() error {
+	if b.IsLeader() {
+		err := b.raft.VerifyLeader().Error()
+		if err != hraft.ErrNotLeader {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.opt.ApplyTimeout)
+	defer cancel()
+
+	return b.forwardPing(ctx)
+}
+
+// Close implements the backend.Backend interface.
+func (b *Backend) Close() error {
+	var err error
+	if b.raft != nil {
+		if e2 := b.raft.Shutdown().Error(); e2 != nil {
+			err = e2
+		}
+	}
+	for _, c := range b.closers {
+		if e2 := c.Close(); e2 != nil {
+			err = e2
+		}
+	}
+
+	b.connMu.Lock()
+	defer b.connMu.Unlock()
+
+	for _, cc := range b.ccs {
+		if e2 := cc.Close(); e2 != nil {
+			err = e2
+		}
+	}
+	b.ccs = nil
+	return err
+}
+
+func (b *Backend) apply(ctx context.Context, cmd *command) (*applyResult, error) {
+	if !b.IsLeader() {
+		return b.forward(ctx, cmd)
+	}
+
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	future := b.raft.Apply(data, b.timeout(ctx))
+	if err := future.Error(); err == hraft.ErrNotLeader {
+		return b.forward(ctx, cmd)
+	} else if err != nil {
+		return nil, err
+	}
+
+	res := future.Response().(*applyResult)
+	return res, res.err
+}
+
+// timeout returns the ApplyTimeout, capped by the context deadline.
+func (b *Backend) timeout(ctx context.Context) time.Duration {
+	timeout := b.opt.ApplyTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+	return timeout
+}
+
+func (b *Backend) leaderClient(ctx context.Context) (rpc.V1Client, error) {
+	_, leaderID := b.raft.LeaderWithID()
+	if leaderID == "" {
+		return nil, ErrNoLeader
+	}
+
+	b.connMu.Lock()
+	defer b.connMu.Unlock()
+
+	if client, ok := b.conns[leaderID]; ok {
+		return client, nil
+	}
+
+	peer, ok := b.peers[leaderID]
+	if !ok {
+		return nil, fmt.Errorf("accord: unknown raft peer %q", leaderID)
+	}
+
+	var client rpc.V1Client
+	if b.opt.Dial != nil {
+		c, err := b.opt.Dial(ctx, peer)
+		if err != nil {
+			return nil, err
+		}
+		client = c
+	} else {
+		cc, err := grpc.NewClient(peer.RPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return nil, err
+		}
+		b.ccs = append(b.ccs, cc)
+		client = rpc.NewV1Client(cc)
+	}
+
+	b.conns[leaderID] = client
+	return client, nil
+}
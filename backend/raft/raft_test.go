@@ -0,0 +1,248 @@
+package raft_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/backend/backendtest"
+	"github.com/bsm/accord/backend/direct"
+	"github.com/bsm/accord/backend/raft"
+	"github.com/bsm/accord/rpc"
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+	"github.com/hashicorp/go-hclog"
+	hraft "github.com/hashicorp/raft"
+)
+
+var _ = Describe("Backend", func() {
+	var data backendtest.BehavesLikeBackendData
+	var cluster []*raft.Backend
+
+	BeforeEach(func() {
+		cluster = openCluster(3)
+		data.Subject = leaderOf(cluster)
+	})
+
+	AfterEach(func() {
+		for _, node := range cluster {
+			Expect(node.Close()).To(Succeed())
+		}
+	})
+
+	Context("defaults", backendtest.BehavesLikeBackend(&data))
+})
+
+var _ = Describe("Cluster", func() {
+	var cluster []*raft.Backend
+	var leader, follower *raft.Backend
+	var ctx = context.Background()
+
+	BeforeEach(func() {
+		cluster = openCluster(3)
+		leader = leaderOf(cluster)
+		for _, node := range cluster {
+			if node != leader {
+				follower = node
+				break
+			}
+		}
+	})
+
+	AfterEach(func() {
+		for _, node := range cluster {
+			Expect(node.Close()).To(Succeed())
+		}
+	})
+
+	It("should forward writes to the leader", func() {
+		h, err := follower.Acquire(ctx, "THEOWNER", "ns", "resource", time.Now().Add(time.Minute), map[string]string{"k": "v"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Owner).To(Equal("THEOWNER"))
+		Expect(h.ExpTime).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+
+		_, err = follower.Acquire(ctx, "OTHERONE", "ns", "resource", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).To(Equal(accord.ErrAcquired))
+
+		Expect(follower.Renew(ctx, "OTHERONE", h.ID, time.Now().Add(time.Minute), nil, nil)).To(Equal(backend.ErrInvalidHandle))
+
+		_, err = follower.Transfer(ctx, "OTHERONE", h.ID, "THEOWNER", false)
+		Expect(err).To(Equal(backend.ErrInvalidHandle))
+		transferred, err := follower.Transfer(ctx, "THEOWNER", h.ID, "OTHERONE", true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(transferred.ID).NotTo(Equal(h.ID))
+		Expect(transferred.Owner).To(Equal("OTHERONE"))
+		Expect(transferred.FencingToken).To(BeNumerically(">", h.FencingToken))
+		Expect(follower.ValidateToken(ctx, transferred.ID, transferred.FencingToken)).To(Succeed())
+		Expect(follower.ValidateToken(ctx, h.ID, h.FencingToken)).To(Equal(backend.ErrStaleToken))
+		h, err = follower.Transfer(ctx, "OTHERONE", transferred.ID, "THEOWNER", false)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(follower.Done(ctx, "THEOWNER", h.ID, map[string]string{"l": "w"}, nil)).To(Succeed())
+
+		_, err = follower.Acquire(ctx, "THEOWNER", "ns", "resource", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).To(Equal(accord.ErrDone))
+
+		stored, err := leader.Get(ctx, h.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.IsDone()).To(BeTrue())
+		Expect(stored.Metadata).To(Equal(map[string]string{"k": "v", "l": "w"}))
+
+		errs, err := follower.MarkDone(ctx, []backend.DoneRecord{
+			{Owner: "OTHERONE", Namespace: "ns", Name: "resource"},
+			{Owner: "OTHERONE", Namespace: "other", Name: "resource"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(errs).To(Equal([]error{accord.ErrDone, nil}))
+
+		Expect(follower.Purge(ctx, &backend.PurgeFilter{Prefix: "ns", Before: time.Now().Add(time.Minute)})).To(Equal(int64(1)))
+		Expect(leader.Get(ctx, h.ID)).To(BeNil())
+
+		opt := &backend.AcquireOptions{Capacity: 2}
+		_, err = follower.Acquire(ctx, "THEOWNER", "ns", "semaphore", time.Now().Add(time.Minute), nil, opt)
+		Expect(err).NotTo(HaveOccurred())
+		h, err = follower.Acquire(ctx, "OTHERONE", "ns", "semaphore", time.Now().Add(time.Minute), nil, opt)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Slot).To(Equal(1))
+		Expect(h.Capacity).To(Equal(2))
+
+		shared := &backend.AcquireOptions{Mode: rpc.Mode_SHARED}
+		h, err = follower.Acquire(ctx, "THEOWNER", "ns", "rwlock", time.Now().Add(time.Minute), nil, shared)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Mode).To(Equal(rpc.Mode_SHARED))
+		_, err = follower.Acquire(ctx, "OTHERONE", "ns", "rwlock", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).To(Equal(accord.ErrAcquired))
+		_, err = follower.Acquire(ctx, "THEOWNER", "ns", "rwlock", time.Now().Add(time.Minute), nil, shared)
+		Expect(err).To(Equal(accord.ErrAcquired))
+	})
+
+	It("should replicate state", func() {
+		h, err := leader.Acquire(ctx, "THEOWNER", "ns", "resource", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(leader.Done(ctx, "THEOWNER", h.ID, nil, nil)).To(Succeed())
+
+		for _, node := range cluster {
+			Eventually(func() (bool, error) {
+				stored, err := node.Get(ctx, h.ID)
+				return stored != nil && stored.IsDone(), err
+			}).Should(BeTrue())
+
+			var names []string
+			Expect(node.List(ctx, &rpc.ListRequest{}, func(h *backend.HandleData) error {
+				names = append(names, h.Name)
+				return nil
+			})).To(Succeed())
+			Expect(names).To(ConsistOf("resource"))
+		}
+	})
+
+	It("should restore from snapshots", func() {
+		h, err := leader.Acquire(ctx, "THEOWNER", "ns", "resource", time.Now().Add(time.Minute), map[string]string{"k": "v"}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		snaps := leader.Raft().Snapshot()
+		Expect(snaps.Error()).To(Succeed())
+		meta, rc, err := snaps.Open()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(meta.Size).To(BeNumerically(">", 0))
+
+		other := openSingleNode()
+		defer other.Close()
+		Expect(other.Raft().Restore(meta, rc, time.Second)).To(Succeed())
+		Expect(rc.Close()).To(Succeed())
+
+		stored, err := other.Get(ctx, h.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Name).To(Equal("resource"))
+		Expect(stored.Metadata).To(Equal(map[string]string{"k": "v"}))
+	})
+
+	It("should reflect quorum in ping", func() {
+		for _, node := range cluster {
+			Eventually(node.Ping).Should(Succeed())
+		}
+
+		for _, node := range cluster {
+			if node != follower {
+				Expect(node.Close()).To(Succeed())
+			}
+		}
+		Eventually(follower.Ping, 5*time.Second).Should(Equal(raft.ErrNoLeader))
+	})
+})
+
+// ------------------------------------------------------------------------
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "accord/backend/raft")
+}
+
+func testConfig() *hraft.Config {
+	config := hraft.DefaultConfig()
+	config.HeartbeatTimeout = 50 * time.Millisecond
+	config.ElectionTimeout = 50 * time.Millisecond
+	config.LeaderLeaseTimeout = 50 * time.Millisecond
+	config.CommitTimeout = 5 * time.Millisecond
+	config.Logger = hclog.New(&hclog.LoggerOptions{Output: io.Discard})
+	return config
+}
+
+func openCluster(n int) []*raft.Backend {
+	peers := make([]raft.Peer, 0, n)
+	transports := make([]*hraft.InmemTransport, 0, n)
+	for i := 0; i < n; i++ {
+		addr, trans := hraft.NewInmemTransport("")
+		peers = append(peers, raft.Peer{ID: fmt.Sprintf("node%d", i+1), Address: string(addr)})
+		transports = append(transports, trans)
+	}
+	for _, t1 := range transports {
+		for _, t2 := range transports {
+			t1.Connect(t2.LocalAddr(), t2)
+		}
+	}
+
+	nodes := make(map[string]*raft.Backend, n)
+	cluster := make([]*raft.Backend, 0, n)
+	for i, peer := range peers {
+		store := hraft.NewInmemStore()
+		node, err := raft.Open(peer.ID, peers, &raft.Options{
+			Config:        testConfig(),
+			LogStore:      store,
+			StableStore:   store,
+			SnapshotStore: hraft.NewInmemSnapshotStore(),
+			Transport:     transports[i],
+			Dial: func(_ context.Context, peer raft.Peer) (rpc.V1Client, error) {
+				return direct.Connect(nodes[peer.ID]), nil
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		nodes[peer.ID] = node
+		cluster = append(cluster, node)
+	}
+	return cluster
+}
+
+func leaderOf(cluster []*raft.Backend) *raft.Backend {
+	var leader *raft.Backend
+	Eventually(func() *raft.Backend {
+		for _, node := range cluster {
+			if node.IsLeader() {
+				leader = node
+			}
+		}
+		return leader
+	}, 5*time.Second).ShouldNot(BeNil())
+	return leader
+}
+
+func openSingleNode() *raft.Backend {
+	cluster := openCluster(1)
+	_ = leaderOf(cluster)
+	return cluster[0]
+}
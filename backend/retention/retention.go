@@ -0,0 +1,140 @@
+// Package retention purges done handles according to per-namespace
+// retention policies.
+//
+// Policies are matched by namespace prefix, the policy with the longest
+// matching prefix applies. A policy with a zero MaxAge retains handles
+// forever:
+//
+//	policies, _ := retention.ParsePolicies("=720h,reports/=0,tmp/=24h")
+//	go retention.Run(ctx, b, policies, nil)
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bsm/accord/backend"
+)
+
+// Policy defines how long done handles are retained within a namespace.
+type Policy struct {
+	Prefix string        // namespace prefix, an empty prefix matches all namespaces
+	MaxAge time.Duration // maximum age since done, zero retains forever
+}
+
+// ParsePolicies parses a comma-separated list of PREFIX=MAXAGE policies,
+// e.g. "=720h,reports/=0,tmp/=24h".
+func ParsePolicies(s string) ([]Policy, error) {
+	var policies []Policy
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		pos := strings.LastIndex(part, "=")
+		if pos < 0 {
+			return nil, fmt.Errorf("accord: invalid retention policy %q", part)
+		}
+
+		prefix := part[:pos]
+		maxAge, err := time.ParseDuration(part[pos+1:])
+		if err != nil || maxAge < 0 {
+			return nil, fmt.Errorf("accord: invalid retention policy %q", part)
+		}
+		if seen[prefix] {
+			return nil, fmt.Errorf("accord: duplicate retention policy for prefix %q", prefix)
+		}
+		seen[prefix] = true
+
+		policies = append(policies, Policy{Prefix: prefix, MaxAge: maxAge})
+	}
+	return policies, nil
+}
+
+// Purge applies policies once and returns the number of purged handles.
+func Purge(ctx context.Context, b backend.Backend, policies []Policy) (int64, error) {
+	var total int64
+	for _, filter := range filters(policies, time.Now()) {
+		num, err := b.Purge(ctx, filter)
+		total += num
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Options contains options for Run.
+type Options struct {
+	// Interval between purges, default: 1h.
+	Interval time.Duration
+	// Logger for results and errors, default: log.Default().
+	Logger *log.Logger
+}
+
+func (o *Options) norm() *Options {
+	var p Options
+	if o != nil {
+		p = *o
+	}
+
+	if p.Interval <= 0 {
+		p.Interval = time.Hour
+	}
+	if p.Logger == nil {
+		p.Logger = log.Default()
+	}
+	return &p
+}
+
+// Run applies policies immediately and then periodically, until the
+// context is cancelled.
+func Run(ctx context.Context, b backend.Backend, policies []Policy, opt *Options) {
+	opt = opt.norm()
+
+	ticker := time.NewTicker(opt.Interval)
+	defer ticker.Stop()
+
+	for {
+		if num, err := Purge(ctx, b, policies); err != nil && ctx.Err() == nil {
+			opt.Logger.Printf("retention: purge failed: %v\n", err)
+		} else if num != 0 {
+			opt.Logger.Printf("retention: purged %d handles\n", num)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// filters converts policies into purge filters. Namespaces covered by a
+// more specific policy are excluded from the filters of less specific ones.
+func filters(policies []Policy, now time.Time) []*backend.PurgeFilter {
+	sorted := make([]Policy, len(policies))
+	copy(sorted, policies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Prefix < sorted[j].Prefix })
+
+	var res []*backend.PurgeFilter
+	for i, p := range sorted {
+		if p.MaxAge <= 0 {
+			continue
+		}
+
+		filter := &backend.PurgeFilter{Prefix: p.Prefix, Before: now.Add(-p.MaxAge)}
+		for _, q := range sorted[i+1:] {
+			if q.Prefix != p.Prefix && strings.HasPrefix(q.Prefix, p.Prefix) {
+				filter.Exclude = append(filter.Exclude, q.Prefix)
+			}
+		}
+		res = append(res, filter)
+	}
+	return res
+}
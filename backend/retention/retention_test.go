@@ -0,0 +1,89 @@
+package retention_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/backend/mock"
+	"github.com/bsm/accord/backend/retention"
+	"github.com/bsm/accord/rpc"
+	. "github.com/bsm/ginkgo/v2"
+	. "github.com/bsm/gomega"
+)
+
+var _ = Describe("ParsePolicies", func() {
+	It("should parse", func() {
+		Expect(retention.ParsePolicies("")).To(BeEmpty())
+		Expect(retention.ParsePolicies("=720h, reports/=0,tmp/=24h")).To(Equal([]retention.Policy{
+			{Prefix: "", MaxAge: 720 * time.Hour},
+			{Prefix: "reports/", MaxAge: 0},
+			{Prefix: "tmp/", MaxAge: 24 * time.Hour},
+		}))
+	})
+
+	It("should reject invalid policies", func() {
+		_, err := retention.ParsePolicies("tmp/")
+		Expect(err).To(MatchError(`accord: invalid retention policy "tmp/"`))
+		_, err = retention.ParsePolicies("tmp/=-1h")
+		Expect(err).To(MatchError(`accord: invalid retention policy "tmp/=-1h"`))
+		_, err = retention.ParsePolicies("tmp/=1h,tmp/=2h")
+		Expect(err).To(MatchError(`accord: duplicate retention policy for prefix "tmp/"`))
+	})
+})
+
+var _ = Describe("Purge", func() {
+	var subject *mock.Backend
+	var ctx = context.Background()
+
+	BeforeEach(func() {
+		subject = mock.New()
+		for _, namespace := range []string{"a", "a/b", "a/b/c", "a/x", "b"} {
+			h, err := subject.Acquire(ctx, "owner", namespace, "resource", time.Now().Add(time.Minute), nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subject.Done(ctx, "owner", h.ID, nil, nil)).To(Succeed())
+		}
+	})
+
+	It("should apply the most specific policy", func() {
+		Expect(retention.Purge(ctx, subject, []retention.Policy{
+			{Prefix: "a", MaxAge: time.Nanosecond},
+			{Prefix: "a/b", MaxAge: 0},
+			{Prefix: "a/b/c", MaxAge: time.Nanosecond},
+			{Prefix: "b", MaxAge: time.Hour},
+		})).To(Equal(int64(3)))
+		Expect(listNamespaces(subject)).To(Equal([]string{"b", "a/b"}))
+	})
+
+	It("should run periodically", func() {
+		buf := new(bytes.Buffer)
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+
+		retention.Run(ctx, subject, []retention.Policy{{MaxAge: time.Nanosecond}}, &retention.Options{
+			Interval: 10 * time.Millisecond,
+			Logger:   log.New(buf, "", 0),
+		})
+		Expect(listNamespaces(subject)).To(BeEmpty())
+		Expect(buf.String()).To(Equal("retention: purged 5 handles\n"))
+	})
+})
+
+// ------------------------------------------------------------------------
+
+func TestSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "accord/backend/retention")
+}
+
+func listNamespaces(b backend.Backend) []string {
+	var namespaces []string
+	Expect(b.List(context.Background(), &rpc.ListRequest{}, func(h *backend.HandleData) error {
+		namespaces = append(namespaces, h.Namespace)
+		return nil
+	})).To(Succeed())
+	return namespaces
+}
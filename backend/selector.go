@@ -0,0 +1,192 @@
+package backend
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidSelector is returned when a metadata selector cannot be parsed.
+var ErrInvalidSelector = errors.New("accord: invalid metadata selector")
+
+// Operator is a selector requirement operator.
+type Operator uint8
+
+// Supported operators.
+const (
+	OpExists       Operator = iota + 1 // key
+	OpDoesNotExist                     // !key
+	OpEquals                           // key=value or key==value
+	OpNotEquals                        // key!=value
+	OpIn                               // key in (v1,v2)
+	OpNotIn                            // key notin (v1,v2)
+	OpHasPrefix                        // key^=prefix
+)
+
+// Requirement is a single metadata selector requirement.
+type Requirement struct {
+	Key      string
+	Operator Operator
+	Values   []string
+}
+
+// Matches returns true if the metadata satisfies the requirement.
+// Like in Kubernetes label selectors, != and notin match missing keys.
+func (r Requirement) Matches(metadata map[string]string) bool {
+	val, ok := metadata[r.Key]
+	switch r.Operator {
+	case OpExists:
+		return ok
+	case OpDoesNotExist:
+		return !ok
+	case OpEquals:
+		return ok && val == r.Values[0]
+	case OpNotEquals:
+		return !ok || val != r.Values[0]
+	case OpIn:
+		return ok && containsString(r.Values, val)
+	case OpNotIn:
+		return !ok || !containsString(r.Values, val)
+	case OpHasPrefix:
+		return ok && strings.HasPrefix(val, r.Values[0])
+	}
+	return false
+}
+
+// Selector is a list of requirements, all of which must be satisfied.
+type Selector []Requirement
+
+// ParseSelector parses a comma-separated list of requirements, e.g.
+//
+//	env in (prod,staging),!legacy,team,owner!=bob,path^=tmp/
+//
+// It returns nil for an empty string.
+func ParseSelector(s string) (Selector, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var sel Selector
+	for _, part := range splitTopLevel(s) {
+		req, err := parseRequirement(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		sel = append(sel, req)
+	}
+	return sel, nil
+}
+
+// Matches returns true if the metadata satisfies all requirements.
+func (s Selector) Matches(metadata map[string]string) bool {
+	for _, req := range s {
+		if !req.Matches(metadata) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseRequirement(s string) (Requirement, error) {
+	// key in (v1,v2) / key notin (v1,v2)
+	if pos := strings.IndexByte(s, '('); pos > -1 {
+		head := strings.Fields(s[:pos])
+		if len(head) != 2 || !isValidKey(head[0]) || !strings.HasSuffix(s, ")") {
+			return Requirement{}, ErrInvalidSelector
+		}
+
+		req := Requirement{Key: head[0]}
+		switch head[1] {
+		case "in":
+			req.Operator = OpIn
+		case "notin":
+			req.Operator = OpNotIn
+		default:
+			return Requirement{}, ErrInvalidSelector
+		}
+
+		for _, v := range strings.Split(s[pos+1:len(s)-1], ",") {
+			if v = strings.TrimSpace(v); !isValidValue(v) {
+				return Requirement{}, ErrInvalidSelector
+			}
+			req.Values = append(req.Values, v)
+		}
+		return req, nil
+	}
+
+	// !key
+	if strings.HasPrefix(s, "!") && !strings.HasPrefix(s, "!=") {
+		if key := strings.TrimSpace(s[1:]); isValidKey(key) {
+			return Requirement{Key: key, Operator: OpDoesNotExist}, nil
+		}
+		return Requirement{}, ErrInvalidSelector
+	}
+
+	// key=value, key==value, key!=value, key^=value
+	if pos := strings.IndexAny(s, "!=^"); pos > -1 {
+		key, rest := strings.TrimSpace(s[:pos]), s[pos:]
+
+		req := Requirement{Key: key}
+		switch {
+		case strings.HasPrefix(rest, "=="):
+			req.Operator, rest = OpEquals, rest[2:]
+		case strings.HasPrefix(rest, "="):
+			req.Operator, rest = OpEquals, rest[1:]
+		case strings.HasPrefix(rest, "!="):
+			req.Operator, rest = OpNotEquals, rest[2:]
+		case strings.HasPrefix(rest, "^="):
+			req.Operator, rest = OpHasPrefix, rest[2:]
+		default:
+			return Requirement{}, ErrInvalidSelector
+		}
+
+		value := strings.TrimSpace(rest)
+		if !isValidKey(key) || (value != "" && !isValidValue(value)) {
+			return Requirement{}, ErrInvalidSelector
+		}
+		req.Values = []string{value}
+		return req, nil
+	}
+
+	// key
+	if !isValidKey(s) {
+		return Requirement{}, ErrInvalidSelector
+	}
+	return Requirement{Key: s, Operator: OpExists}, nil
+}
+
+// splitTopLevel splits s by commas outside of parentheses.
+func splitTopLevel(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func isValidKey(s string) bool {
+	return s != "" && !strings.ContainsAny(s, " \t\r\n!=^(),")
+}
+
+func isValidValue(s string) bool {
+	return s != "" && !strings.ContainsAny(s, "(),=!^")
+}
+
+func containsString(vs []string, s string) bool {
+	for _, v := range vs {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"time"
+
+	"github.com/bsm/accord"
+	"github.com/bsm/accord/rpc"
+)
+
+// SelectSlot selects the slot of a resource to acquire. The slots argument
+// contains the currently stored handles of the resource, indexed by slot.
+//
+// Exclusive handles occupy slot 0, or one of the first Capacity slots of a
+// semaphore. Shared handles occupy the first vacant slot from 1 onwards and
+// are rejected with accord.ErrAcquired while slot 0 is held. Exclusive
+// handles which are acquired while shared handles are held must be stored as
+// queued. Queued handles block new shared handles and can be re-acquired by
+// their owner only.
+func SelectSlot(slots []*HandleData, owner string, opt *AcquireOptions, now time.Time) (slot int, queued bool, err error) {
+	if opt.GetMode() == rpc.Mode_SHARED {
+		slot, err = selectShared(slots, now)
+		return slot, false, err
+	}
+
+	capacity := opt.GetCapacity()
+	for slot := 0; slot < capacity; slot++ {
+		stored := slotAt(slots, slot)
+		if stored == nil || stored.IsReleased(now) || (stored.Queued && stored.Owner == owner) {
+			return slot, capacity == 1 && HasSharedHolders(slots, now), nil
+		} else if stored.IsDone() {
+			return 0, false, accord.ErrDone
+		}
+	}
+	return 0, false, accord.ErrAcquired
+}
+
+// HasSharedHolders returns true if any of the handles is a held shared
+// handle.
+func HasSharedHolders(handles []*HandleData, now time.Time) bool {
+	for _, h := range handles {
+		if h != nil && h.Mode == rpc.Mode_SHARED && h.IsHeld(now) {
+			return true
+		}
+	}
+	return false
+}
+
+func selectShared(slots []*HandleData, now time.Time) (int, error) {
+	if stored := slotAt(slots, 0); stored != nil && !stored.IsReleased(now) {
+		if stored.IsDone() {
+			return 0, accord.ErrDone
+		}
+		return 0, accord.ErrAcquired
+	}
+
+	for slot := 1; ; slot++ {
+		if stored := slotAt(slots, slot); stored == nil || stored.IsReleased(now) {
+			return slot, nil
+		}
+	}
+}
+
+func slotAt(slots []*HandleData, slot int) *HandleData {
+	if slot < len(slots) {
+		return slots[slot]
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package backend
+
+import (
+	"cmp"
+	"sort"
+	"strings"
+
+	"github.com/bsm/accord/rpc"
+)
+
+// IsDefaultSort returns true if the request lists handles newest first.
+func IsDefaultSort(req *rpc.ListRequest) bool {
+	return req.GetSort() == rpc.ListRequest_CREATED && req.GetOrder() == rpc.ListRequest_DESC
+}
+
+// ParseListCursor parses the cursor of a list request. Cursors can only be
+// combined with the default sort.
+func ParseListCursor(req *rpc.ListRequest) (*Cursor, error) {
+	cursor, err := ParseCursor(req.GetCursor())
+	if err != nil {
+		return nil, err
+	}
+	if cursor != nil && !IsDefaultSort(req) {
+		return nil, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// SortHandles sorts handles by the sort key and order of the request. Ties
+// are broken by namespace, name and slot, handles without a done time are sorted last when sorting
+// by done time.
+func SortHandles(handles []*HandleData, req *rpc.ListRequest) {
+	key, asc := req.GetSort(), req.GetOrder() == rpc.ListRequest_ASC
+	sort.Slice(handles, func(i, j int) bool {
+		a, b := handles[i], handles[j]
+		if key == rpc.ListRequest_DONE && a.IsDone() != b.IsDone() {
+			return a.IsDone()
+		}
+
+		c := compareBy(a, b, key)
+		if c == 0 {
+			c = compareResource(a, b.Namespace, b.Name, b.Slot)
+		}
+		if asc {
+			return c < 0
+		}
+		return c > 0
+	})
+}
+
+func compareBy(a, b *HandleData, key rpc.ListRequest_SortKey) int {
+	switch key {
+	case rpc.ListRequest_UPDATED:
+		return a.UpdatedTime.Compare(b.UpdatedTime)
+	case rpc.ListRequest_DONE:
+		return a.DoneTime.Compare(b.DoneTime)
+	case rpc.ListRequest_EXPIRES:
+		return a.ExpTime.Compare(b.ExpTime)
+	case rpc.ListRequest_NAME:
+		return strings.Compare(a.Name, b.Name)
+	}
+	return a.CreatedTime.Compare(b.CreatedTime)
+}
+
+// compareResource compares a handle by namespace, name and slot, which remain
+// unchanged over the lifetime of a stored handle.
+func compareResource(h *HandleData, namespace, name string, slot int) int {
+	if c := strings.Compare(h.Namespace, namespace); c != 0 {
+		return c
+	}
+	if c := strings.Compare(h.Name, name); c != 0 {
+		return c
+	}
+	return cmp.Compare(h.Slot, slot)
+}
@@ -2,6 +2,7 @@ package accord
 
 import (
 	"context"
+	"encoding/base64"
 	"io"
 	"os"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"github.com/bsm/accord/rpc"
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
 )
 
 // ClientOptions contains options for the client
@@ -22,8 +24,13 @@ type ClientOptions struct {
 	OnError   func(error)       // custom error handler for background tasks
 }
 
-func (o *ClientOptions) ttlSeconds() uint32 {
-	return uint32(o.TTL / time.Second)
+func ttlSeconds(ttl time.Duration) uint32 {
+	return uint32(ttl / time.Second)
+}
+
+// cacheKey returns the local cache key of a resource.
+func cacheKey(namespace, name string) string {
+	return namespace + "\x00" + name
 }
 
 func (o *ClientOptions) handleError(err error) {
@@ -117,22 +124,75 @@ func DialClient(ctx context.Context, target string, opt *ClientOptions, dialOpt
 	return ci, nil
 }
 
+// AcquireOption configures a single Acquire call.
+type AcquireOption func(*acquireOptions)
+
+type acquireOptions struct {
+	ttl       time.Duration
+	namespace string
+	capacity  int
+	mode      rpc.Mode
+	noCache   bool
+}
+
+// WithTTL overrides the client TTL. Acquire fails with ErrInvalidTTL if
+// ttl is below one second.
+func WithTTL(ttl time.Duration) AcquireOption {
+	return func(o *acquireOptions) { o.ttl = ttl }
+}
+
+// WithNamespace overrides the client namespace. Done resources of the client
+// namespace are loaded into the local cache on startup, resources of other
+// namespaces are cached once they have been seen as done.
+func WithNamespace(namespace string) AcquireOption {
+	return func(o *acquireOptions) { o.namespace = namespace }
+}
+
+// WithCapacity allows up to n concurrent holders of the resource, turning it
+// into a counting semaphore. Done releases the slot of the handle, rather
+// than marking the whole resource as done.
+func WithCapacity(n int) AcquireOption {
+	return func(o *acquireOptions) { o.capacity = n }
+}
+
+func withMode(mode rpc.Mode) AcquireOption {
+	return func(o *acquireOptions) { o.mode = mode }
+}
+
+// WithCache enables or disables the lookup of done resources in the local
+// cache. Enabled by default.
+func WithCache(enabled bool) AcquireOption {
+	return func(o *acquireOptions) { o.noCache = !enabled }
+}
+
 // Acquire implements ClientConn interface.
-func (c *Client) Acquire(ctx context.Context, name string, meta map[string]string) (*Handle, error) {
+func (c *Client) Acquire(ctx context.Context, name string, meta map[string]string, opts ...AcquireOption) (*Handle, error) {
+	o := acquireOptions{ttl: c.opt.TTL, namespace: c.opt.Namespace}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.ttl < time.Second {
+		return nil, ErrInvalidTTL
+	}
+
 	// check in cache first
-	if found, err := c.cache.Contains(name); err != nil {
-		return nil, err
-	} else if found {
-		return nil, ErrDone
+	if !o.noCache {
+		if found, err := c.cache.Contains(cacheKey(o.namespace, name)); err != nil {
+			return nil, err
+		} else if found {
+			return nil, ErrDone
+		}
 	}
 
 	// try to acquire
 	res, err := c.rpc.Acquire(ctx, &rpc.AcquireRequest{
 		Owner:     c.opt.Owner,
 		Name:      name,
-		Namespace: c.opt.Namespace,
-		Ttl:       c.opt.ttlSeconds(),
+		Namespace: o.namespace,
+		Ttl:       ttlSeconds(o.ttl),
 		Metadata:  c.opt.mergeMeta(meta),
+		Capacity:  uint32(max(o.capacity, 0)),
+		Mode:      o.mode,
 	})
 	if err != nil {
 		return nil, err
@@ -142,14 +202,68 @@ func (c *Client) Acquire(ctx context.Context, name string, meta map[string]strin
 	case rpc.Status_HELD:
 		return nil, ErrAcquired
 	case rpc.Status_DONE:
-		if err := c.cache.Add(name); err != nil {
+		if err := c.cache.Add(cacheKey(o.namespace, name)); err != nil {
 			return nil, err
 		}
 		return nil, ErrDone
 	}
 
 	handleID := uuid.Must(uuid.FromBytes(res.Handle.Id))
-	return newHandle(handleID, c.rpc, res.Handle.Metadata, c.opt), nil
+	return newHandle(handleID, c.rpc, res.Handle, c.opt, o.ttl), nil
+}
+
+// AcquireShared acquires a shared handle on the resource. Any number of
+// shared handles can be held at the same time, but only while no exclusive
+// handle is held or queued. Returns ErrAcquired otherwise.
+func (c *Client) AcquireShared(ctx context.Context, name string, meta map[string]string, opts ...AcquireOption) (*Handle, error) {
+	return c.Acquire(ctx, name, meta, append(opts, withMode(rpc.Mode_SHARED))...)
+}
+
+// AcquireExclusive acquires an exclusive handle on the resource. If shared
+// handles are currently held, the request is queued and ErrAcquired is
+// returned. Queued requests block new shared handles, retry to acquire the
+// handle once all shared handles have been released.
+func (c *Client) AcquireExclusive(ctx context.Context, name string, meta map[string]string, opts ...AcquireOption) (*Handle, error) {
+	return c.Acquire(ctx, name, meta, append(opts, withMode(rpc.Mode_EXCLUSIVE))...)
+}
+
+// Resume takes over a handle which has been transferred to this client's
+// owner via Handle.Transfer. It renews the handle immediately and continues
+// to renew it in the background.
+func (c *Client) Resume(ctx context.Context, token string) (*Handle, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidTransfer
+	}
+
+	data := new(rpc.Handle)
+	if err := proto.Unmarshal(raw, data); err != nil {
+		return nil, ErrInvalidTransfer
+	}
+	handleID, err := uuid.FromBytes(data.Id)
+	if err != nil || data.Owner != c.opt.Owner {
+		return nil, ErrInvalidTransfer
+	}
+
+	handle := newHandle(handleID, c.rpc, data, c.opt, c.opt.TTL)
+	if err := handle.Renew(ctx, nil); err != nil {
+		handle.close()
+		return nil, err
+	}
+	return handle, nil
+}
+
+// ValidateToken checks that token is the current fencing token of the
+// handle, i.e. that the resource has not been taken over or marked as done.
+func (c *Client) ValidateToken(ctx context.Context, handleID uuid.UUID, token uint64) (bool, error) {
+	res, err := c.rpc.ValidateToken(ctx, &rpc.ValidateTokenRequest{
+		HandleId:     handleID[:],
+		FencingToken: token,
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.Valid, nil
 }
 
 // RPC implements Client interface.
@@ -176,8 +290,8 @@ func (c *Client) Close() error {
 func (c *Client) fetchDone(ctx context.Context) error {
 	res, err := c.rpc.List(ctx, &rpc.ListRequest{
 		Filter: &rpc.ListRequest_Filter{
-			Prefix: c.opt.Namespace,
-			Status: rpc.ListRequest_Filter_DONE,
+			Namespace: c.opt.Namespace,
+			Status:    rpc.ListRequest_Filter_DONE,
 		},
 	})
 	if err != nil {
@@ -198,8 +312,9 @@ func (c *Client) fetchDone(ctx context.Context) error {
 			return err
 		}
 
+		// older servers may ignore the namespace filter
 		if handle.Namespace == c.opt.Namespace {
-			if err := wb.Add(handle.Name); err != nil {
+			if err := wb.Add(cacheKey(handle.Namespace, handle.Name)); err != nil {
 				return err
 			}
 		}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/bsm/accord"
+	backendpkg "github.com/bsm/accord/backend"
 	"github.com/bsm/accord/backend/direct"
 	"github.com/bsm/accord/backend/mock"
 	. "github.com/bsm/ginkgo/v2"
@@ -37,6 +38,18 @@ var _ = Describe("Client", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	restart := func() {
+		Expect(subject.Close()).To(Succeed())
+
+		var err error
+		subject, err = accord.RPCClient(ctx, direct.Connect(backend), &accord.ClientOptions{
+			Dir:       tempDir,
+			Owner:     "testclient",
+			Namespace: "test",
+		})
+		Expect(err).NotTo(HaveOccurred())
+	}
+
 	AfterEach(func() {
 		_ = handle.Discard()
 		Expect(subject.Close()).To(Succeed())
@@ -46,6 +59,12 @@ var _ = Describe("Client", func() {
 	It("should acquire", func() {
 		Expect(handle.ID()).To(HaveLen(16))
 		Expect(handle.Metadata()).To(Equal(map[string]string{"a": "2", "b": "1", "x": "+"}))
+		Expect(handle.Name()).To(Equal("resource"))
+		Expect(handle.Namespace()).To(Equal("test"))
+		Expect(handle.Attempt()).To(Equal(1))
+		Expect(handle.PreviousOwner()).To(BeEmpty())
+		Expect(handle.FencingToken()).To(Equal(uint64(1)))
+		Expect(handle.ExpiresAt()).To(BeTemporally("~", time.Now().Add(10*time.Minute), time.Second))
 
 		stored, err := backend.Get(ctx, handle.ID())
 		Expect(err).NotTo(HaveOccurred())
@@ -64,6 +83,7 @@ var _ = Describe("Client", func() {
 		expTime := stored.ExpTime
 
 		Expect(handle.Renew(ctx, nil)).To(Succeed())
+		Expect(handle.ExpiresAt()).To(BeTemporally("~", time.Now().Add(10*time.Minute), time.Second))
 
 		stored, err = backend.Get(ctx, handle.ID())
 		Expect(err).NotTo(HaveOccurred())
@@ -79,6 +99,60 @@ var _ = Describe("Client", func() {
 		Expect(stored.Metadata).To(Equal(map[string]string{"a": "2", "b": "1", "c": "3", "x": "-"}))
 	})
 
+	It("should delete and replace metadata", func() {
+		handle.DeleteMeta("a")
+		handle.SetMeta("c", "3")
+		Expect(handle.Metadata()).To(Equal(map[string]string{"b": "1", "c": "3", "x": "+"}))
+		Expect(handle.Renew(ctx, nil)).To(Succeed())
+
+		stored, err := backend.Get(ctx, handle.ID())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Metadata).To(Equal(map[string]string{"b": "1", "c": "3", "x": "+"}))
+
+		// keys written by others are replaced too
+		Expect(backend.Renew(ctx, "testclient", handle.ID(), stored.ExpTime, map[string]string{"z": "9"}, nil)).To(Succeed())
+		handle.ReplaceMeta(map[string]string{"d": "4"})
+		Expect(handle.Renew(ctx, nil)).To(Succeed())
+
+		stored, err = backend.Get(ctx, handle.ID())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Metadata).To(Equal(map[string]string{"d": "4"}))
+
+		// subsequent updates are merged again
+		Expect(backend.Renew(ctx, "testclient", handle.ID(), stored.ExpTime, map[string]string{"z": "9"}, nil)).To(Succeed())
+		handle.SetMeta("e", "5")
+		Expect(handle.Done(ctx, nil)).To(Succeed())
+
+		stored, err = backend.Get(ctx, handle.ID())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Metadata).To(Equal(map[string]string{"d": "4", "e": "5", "z": "9"}))
+	})
+
+	It("should track metadata versions", func() {
+		version := handle.MetadataVersion()
+		Expect(handle.Renew(ctx, nil, accord.WithExpectedVersion(version))).To(Succeed())
+		Expect(handle.MetadataVersion()).To(Equal(version))
+
+		Expect(handle.Renew(ctx, map[string]string{"c": "3"}, accord.WithExpectedVersion(version))).To(Succeed())
+		Expect(handle.MetadataVersion()).To(Equal(version + 1))
+
+		stored, err := backend.Get(ctx, handle.ID())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(uint64(stored.MetaVersion)).To(Equal(version + 1))
+
+		// a concurrent writer modifies metadata
+		Expect(backend.Renew(ctx, "testclient", handle.ID(), stored.ExpTime, map[string]string{"z": "9"}, nil)).To(Succeed())
+
+		// stale writes are rejected
+		Expect(handle.Renew(ctx, map[string]string{"c": "4"}, accord.WithExpectedVersion(handle.MetadataVersion()))).To(Equal(accord.ErrVersionMismatch))
+		Expect(handle.Done(ctx, nil, accord.WithExpectedVersion(handle.MetadataVersion()))).To(Equal(accord.ErrVersionMismatch))
+
+		stored, err = backend.Get(ctx, handle.ID())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.Metadata).To(Equal(map[string]string{"a": "2", "b": "1", "c": "3", "x": "+", "z": "9"}))
+		Expect(stored.IsDone()).To(BeFalse())
+	})
+
 	It("should discard", func() {
 		Expect(handle.Discard()).To(Succeed())
 
@@ -92,6 +166,31 @@ var _ = Describe("Client", func() {
 		Expect(handle.Done(ctx, nil)).To(Equal(accord.ErrClosed))
 	})
 
+	It("should take over discarded handles", func() {
+		Expect(handle.Discard()).To(Succeed())
+		Expect(os.Mkdir(tempDir+"/other", 0o755)).To(Succeed())
+
+		other, err := accord.RPCClient(ctx, direct.Connect(backend), &accord.ClientOptions{
+			Dir:       tempDir + "/other",
+			Owner:     "otherclient",
+			Namespace: "test",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		defer other.Close()
+
+		h2, err := other.Acquire(ctx, "resource", nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer h2.Discard()
+
+		Expect(h2.ID()).NotTo(Equal(handle.ID()))
+		Expect(h2.Attempt()).To(Equal(2))
+		Expect(h2.PreviousOwner()).To(Equal("testclient"))
+		Expect(h2.FencingToken()).To(Equal(uint64(2)))
+
+		Expect(other.ValidateToken(ctx, h2.ID(), h2.FencingToken())).To(BeTrue())
+		Expect(other.ValidateToken(ctx, handle.ID(), handle.FencingToken())).To(BeFalse())
+	})
+
 	It("should mark as done", func() {
 		Expect(handle.Done(ctx, map[string]string{"c": "3"})).To(Succeed())
 
@@ -104,4 +203,162 @@ var _ = Describe("Client", func() {
 		Expect(handle.Renew(ctx, nil)).To(Equal(accord.ErrClosed))
 		Expect(handle.Done(ctx, nil)).To(Equal(accord.ErrClosed))
 	})
+
+	It("should transfer", func() {
+		Expect(os.Mkdir(tempDir+"/other", 0o755)).To(Succeed())
+		other, err := accord.RPCClient(ctx, direct.Connect(backend), &accord.ClientOptions{
+			Dir:       tempDir + "/other",
+			Owner:     "otherclient",
+			Namespace: "test",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		defer other.Close()
+
+		handle.SetMeta("c", "3")
+		oldID := handle.ID()
+		token, err := handle.Transfer(ctx, "otherclient")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handle.ID()).NotTo(Equal(oldID))
+		Expect(handle.Renew(ctx, nil)).To(Equal(accord.ErrClosed))
+
+		_, err = subject.Resume(ctx, token)
+		Expect(err).To(Equal(accord.ErrInvalidTransfer))
+		_, err = other.Resume(ctx, "invalid")
+		Expect(err).To(Equal(accord.ErrInvalidTransfer))
+
+		h2, err := other.Resume(ctx, token)
+		Expect(err).NotTo(HaveOccurred())
+		defer h2.Discard()
+
+		Expect(h2.ID()).To(Equal(handle.ID()))
+		Expect(h2.Name()).To(Equal("resource"))
+		Expect(h2.PreviousOwner()).To(Equal("testclient"))
+		Expect(h2.FencingToken()).To(BeNumerically(">", handle.FencingToken()))
+		Expect(h2.Metadata()).To(Equal(map[string]string{"a": "2", "b": "1", "c": "3", "x": "+"}))
+		Expect(h2.Done(ctx, nil)).To(Succeed())
+	})
+
+	It("should acquire with options", func() {
+		h2, err := subject.Acquire(ctx, "resource", map[string]string{"c": "3"},
+			accord.WithNamespace("other"),
+			accord.WithTTL(time.Minute),
+		)
+		Expect(err).NotTo(HaveOccurred())
+		defer h2.Discard()
+
+		Expect(h2.Namespace()).To(Equal("other"))
+		Expect(h2.TTL()).To(Equal(time.Minute))
+		Expect(h2.ExpiresAt()).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+		Expect(h2.Metadata()).To(Equal(map[string]string{"c": "3", "x": "+"}))
+	})
+
+	It("should reject invalid TTLs", func() {
+		_, err := subject.Acquire(ctx, "other", nil, accord.WithTTL(time.Millisecond))
+		Expect(err).To(Equal(accord.ErrInvalidTTL))
+	})
+
+	It("should cache done resources by namespace", func() {
+		h2, err := subject.Acquire(ctx, "resource", nil, accord.WithNamespace("other"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h2.Done(ctx, nil)).To(Succeed())
+
+		_, err = subject.Acquire(ctx, "resource", nil, accord.WithNamespace("other"))
+		Expect(err).To(Equal(accord.ErrDone))
+
+		_, err = backend.Purge(ctx, &backendpkg.PurgeFilter{Prefix: "other", Before: time.Now().Add(time.Minute)})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = subject.Acquire(ctx, "resource", nil, accord.WithNamespace("other"))
+		Expect(err).To(Equal(accord.ErrDone))
+
+		// the default namespace is unaffected
+		_, err = subject.Acquire(ctx, "resource", nil)
+		Expect(err).To(Equal(accord.ErrAcquired))
+	})
+
+	It("should bypass the cache", func() {
+		Expect(handle.Done(ctx, nil)).To(Succeed())
+		_, err := subject.Acquire(ctx, "resource", nil)
+		Expect(err).To(Equal(accord.ErrDone))
+
+		_, err = backend.Purge(ctx, &backendpkg.PurgeFilter{Prefix: "test", Before: time.Now().Add(time.Minute)})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = subject.Acquire(ctx, "resource", nil)
+		Expect(err).To(Equal(accord.ErrDone))
+
+		h2, err := subject.Acquire(ctx, "resource", nil, accord.WithCache(false))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h2.Discard()).To(Succeed())
+	})
+
+	It("should acquire semaphores", func() {
+		h1, err := subject.Acquire(ctx, "quota", nil, accord.WithCapacity(2))
+		Expect(err).NotTo(HaveOccurred())
+		defer h1.Discard()
+		h2, err := subject.Acquire(ctx, "quota", nil, accord.WithCapacity(2))
+		Expect(err).NotTo(HaveOccurred())
+		defer h2.Discard()
+
+		Expect(h1.Slot()).To(Equal(0))
+		Expect(h2.Slot()).To(Equal(1))
+		_, err = subject.Acquire(ctx, "quota", nil, accord.WithCapacity(2))
+		Expect(err).To(Equal(accord.ErrAcquired))
+
+		Expect(h2.Done(ctx, nil)).To(Succeed())
+
+		// released slots are not cached as done
+		restart()
+
+		h3, err := subject.Acquire(ctx, "quota", nil, accord.WithCapacity(2))
+		Expect(err).NotTo(HaveOccurred())
+		defer h3.Discard()
+		Expect(h3.Slot()).To(Equal(1))
+	})
+
+	It("should acquire shared and exclusive handles", func() {
+		r1, err := subject.AcquireShared(ctx, "dataset", nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer r1.Discard()
+		r2, err := subject.AcquireShared(ctx, "dataset", nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer r2.Discard()
+
+		Expect(r1.Shared()).To(BeTrue())
+		Expect(r2.Slot()).To(Equal(2))
+
+		_, err = subject.AcquireExclusive(ctx, "dataset", nil)
+		Expect(err).To(Equal(accord.ErrAcquired))
+		_, err = subject.AcquireShared(ctx, "dataset", nil)
+		Expect(err).To(Equal(accord.ErrAcquired))
+
+		Expect(r1.Done(ctx, nil)).To(Succeed())
+		Expect(r2.Done(ctx, nil)).To(Succeed())
+
+		w, err := subject.AcquireExclusive(ctx, "dataset", nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Discard()
+		Expect(w.Shared()).To(BeFalse())
+		Expect(w.Slot()).To(Equal(0))
+
+		// released shared handles are not cached as done
+		r3, err := subject.AcquireShared(ctx, "reports", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r3.Done(ctx, nil)).To(Succeed())
+		restart()
+
+		r4, err := subject.AcquireShared(ctx, "reports", nil)
+		Expect(err).NotTo(HaveOccurred())
+		defer r4.Discard()
+	})
+
+	It("should change TTL", func() {
+		Expect(handle.SetTTL(ctx, time.Minute)).To(Succeed())
+		Expect(handle.TTL()).To(Equal(time.Minute))
+		Expect(handle.ExpiresAt()).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+
+		stored, err := backend.Get(ctx, handle.ID())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stored.ExpTime).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+
+		Expect(handle.SetTTL(ctx, time.Millisecond)).To(Equal(accord.ErrInvalidTTL))
+	})
 })
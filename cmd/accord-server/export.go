@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/bsm/accord/backend/ndjson"
+	"github.com/bsm/accord/rpc"
+)
+
+type dumpFlags struct {
+	file      string
+	namespace string
+	prefix    string
+	status    string
+}
+
+func parseDumpFlags(cmd string, args []string) (*dumpFlags, *rpc.ListRequest_Filter, error) {
+	var f dumpFlags
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.StringVar(&f.file, "file", "-", "NDJSON file, defaults to STDOUT on export and STDIN on import")
+	fs.StringVar(&f.namespace, "namespace", "", "Only include handles within this exact namespace")
+	fs.StringVar(&f.prefix, "prefix", "", "Only include handles with this namespace prefix")
+	fs.StringVar(&f.status, "status", "all", "Only include handles with this status: all, pending or done")
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	status, ok := rpc.ListRequest_Filter_Status_value[strings.ToUpper(f.status)]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid status %q", f.status)
+	}
+
+	return &f, &rpc.ListRequest_Filter{
+		Status:    rpc.ListRequest_Filter_Status(status),
+		Namespace: f.namespace,
+		Prefix:    f.prefix,
+	}, nil
+}
+
+// runExport runs the export subcommand.
+func runExport(ctx context.Context, args []string) error {
+	f, filter, err := parseDumpFlags("export", args)
+	if err != nil {
+		return err
+	}
+
+	b, err := openBackend(ctx)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	file := os.Stdout
+	if f.file != "-" {
+		if file, err = os.Create(f.file); err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+
+	num, err := ndjson.Export(ctx, b, file, filter)
+	if err != nil {
+		return err
+	}
+	log.Printf("Exported %d handles\n", num)
+	return nil
+}
+
+// runImport runs the import subcommand.
+func runImport(ctx context.Context, args []string) error {
+	f, filter, err := parseDumpFlags("import", args)
+	if err != nil {
+		return err
+	}
+
+	file := os.Stdin
+	if f.file != "-" {
+		if file, err = os.Open(f.file); err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+
+	b, err := openBackend(ctx)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	stats, err := ndjson.Import(ctx, b, file, filter)
+	if err != nil {
+		return err
+	}
+	log.Printf("Imported %d handles, skipped %d conflicts\n", stats.Imported, stats.Conflicts)
+	return nil
+}
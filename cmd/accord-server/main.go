@@ -2,45 +2,122 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"flag"
+	"fmt"
 	"log"
 	"net"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/backend/kubernetes"
 	"github.com/bsm/accord/backend/postgres"
+	"github.com/bsm/accord/backend/raft"
+	"github.com/bsm/accord/backend/retention"
 	"github.com/bsm/accord/internal/service"
 	"github.com/bsm/accord/rpc"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"google.golang.org/grpc"
 )
 
 var flags struct {
-	addr    string
-	backend string
+	addr           string
+	backend        string
+	backendReplica string
+
+	raftID    string
+	raftDir   string
+	raftPeers string
+
+	pgSchema      string
+	pgTablePrefix string
+	pgSkipMigrate bool
+	pgArchive     bool
+	pgMaxConns    int
+	pgMaxIdleTime time.Duration
+	pgStmtTimeout time.Duration
+	pgMaxLag      time.Duration
+
+	backendLog     bool
+	backendTimeout time.Duration
+	backendRetries int
+	metricsAddr    string
+	tracing        bool
+
+	retention         string
+	retentionInterval time.Duration
 }
 
 func init() {
 	flag.StringVar(&flags.addr, "addr", ":7475", "Address for the server to listen on")
-	flag.StringVar(&flags.backend, "backend", "postgres://127.0.0.1:5432/accord", "Backend URL")
+	flag.StringVar(&flags.backend, "backend", "postgres://127.0.0.1:5432/accord", "Backend URL, e.g. postgres://127.0.0.1:5432/accord or kubernetes://NAMESPACE")
+	flag.StringVar(&flags.backendReplica, "backend-replica", "", "Optional postgres read replica URL for List and Get queries")
+	flag.StringVar(&flags.raftID, "raft-id", "", "Node ID, enables the embedded raft backend instead of -backend")
+	flag.StringVar(&flags.raftDir, "raft-dir", "accord-raft", "Data directory for the raft backend")
+	flag.StringVar(&flags.raftPeers, "raft-peers", "", "Comma-separated raft cluster members as ID=RAFT_ADDR/RPC_ADDR, e.g. a=10.0.0.1:7476/10.0.0.1:7475")
+	flag.StringVar(&flags.pgSchema, "postgres-schema", "", "Postgres schema for the accord tables, defaults to the connection's default schema")
+	flag.StringVar(&flags.pgTablePrefix, "postgres-table-prefix", "", "Prefix for the accord table names in postgres, e.g. accord_")
+	flag.BoolVar(&flags.pgSkipMigrate, "postgres-skip-migrate", false, "Skip automatic postgres schema migrations on startup")
+	flag.BoolVar(&flags.pgArchive, "postgres-archive", false, "Move done handles into a separate postgres archive table")
+	flag.IntVar(&flags.pgMaxConns, "postgres-max-conns", 0, "Maximum number of postgres connections, defaults to max(4, NUM_CPU)")
+	flag.DurationVar(&flags.pgMaxIdleTime, "postgres-max-idle-time", 0, "Close idle postgres connections after this duration, defaults to 30m")
+	flag.DurationVar(&flags.pgStmtTimeout, "postgres-statement-timeout", 0, "Postgres statement timeout, disabled by default")
+	flag.DurationVar(&flags.pgMaxLag, "postgres-max-replica-lag", 0, "Maximum tolerated replication lag before reads fall back to the primary, defaults to 10s")
+	flag.BoolVar(&flags.backendLog, "backend-log", false, "Log all backend calls")
+	flag.DurationVar(&flags.backendTimeout, "backend-timeout", 0, "Timeout for backend calls, disabled by default")
+	flag.IntVar(&flags.backendRetries, "backend-retries", 0, "Number of retries for idempotent backend calls on transient errors")
+	flag.StringVar(&flags.metricsAddr, "metrics-addr", "", "Address to serve Prometheus metrics on, e.g. :9090, disabled by default")
+	flag.BoolVar(&flags.tracing, "tracing", false, "Enable OpenTelemetry tracing, configured via the standard OTEL_EXPORTER_OTLP_* environment variables")
+	flag.StringVar(&flags.retention, "retention", "", "Comma-separated retention policies for done handles as NAMESPACE_PREFIX=MAX_AGE, e.g. =720h,tmp/=24h, disabled by default")
+	flag.DurationVar(&flags.retentionInterval, "retention-interval", time.Hour, "Interval at which retention policies are applied")
 }
 
 func main() {
 	flag.Parse()
 
-	if err := run(context.Background()); err != nil {
+	var err error
+	switch flag.Arg(0) {
+	case "migrate":
+		err = runMigrate(context.Background(), flag.Args()[1:])
+	case "export":
+		err = runExport(context.Background(), flag.Args()[1:])
+	case "import":
+		err = runImport(context.Background(), flag.Args()[1:])
+	default:
+		err = run(context.Background())
+	}
+	if err != nil {
 		log.Fatalln(err)
 	}
 }
 
 func run(ctx context.Context) error {
-	driver := strings.SplitN(flags.backend, ":", 2)[0]
-	backend, err := postgres.Open(ctx, driver, flags.backend)
+	backend, err := openBackend(ctx)
 	if err != nil {
 		return err
 	}
-	log.Printf("Connected to %q backend\n", driver)
 	defer backend.Close()
 
+	backend, shutdown, err := applyMiddlewares(ctx, backend)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	policies, err := retention.ParsePolicies(flags.retention)
+	if err != nil {
+		return err
+	}
+	if len(policies) != 0 {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		go retention.Run(ctx, backend, policies, &retention.Options{Interval: flags.retentionInterval})
+	}
+
 	lis, err := net.Listen("tcp", flags.addr)
 	if err != nil {
 		return err
@@ -55,3 +132,154 @@ func run(ctx context.Context) error {
 	log.Printf("Listening on %s\n", flags.addr)
 	return srv.Serve(lis)
 }
+
+func openBackend(ctx context.Context) (backend.Backend, error) {
+	if flags.raftID != "" {
+		peers, err := parseRaftPeers(flags.raftPeers)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := raft.Open(flags.raftID, peers, &raft.Options{Dir: flags.raftDir})
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Started raft node %q with %d peers\n", flags.raftID, len(peers))
+		return b, nil
+	}
+
+	driver := strings.SplitN(flags.backend, ":", 2)[0]
+	if driver == "kubernetes" {
+		u, err := url.Parse(flags.backend)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := kubernetes.Open(&kubernetes.Options{Namespace: u.Host})
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Connected to %q backend\n", driver)
+		return b, nil
+	}
+
+	pgOpt := &postgres.Options{
+		Schema:        flags.pgSchema,
+		TablePrefix:   flags.pgTablePrefix,
+		SkipMigrate:   flags.pgSkipMigrate,
+		Archive:       flags.pgArchive,
+		MaxReplicaLag: flags.pgMaxLag,
+	}
+	if driver != "postgres" && driver != "postgresql" {
+		return openSQLBackend(ctx, driver, pgOpt)
+	}
+
+	pool, err := openPgxPool(ctx, flags.backend)
+	if err != nil {
+		return nil, err
+	}
+	if flags.backendReplica != "" {
+		replica, err := openPgxPool(ctx, flags.backendReplica)
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+		pgOpt.ReplicaPool = replica
+	}
+	closePools := func() {
+		pool.Close()
+		if pgOpt.ReplicaPool != nil {
+			pgOpt.ReplicaPool.Close()
+		}
+	}
+
+	b, err := postgres.OpenPgx(ctx, pool, pgOpt)
+	if err != nil {
+		closePools()
+		return nil, err
+	}
+	log.Printf("Connected to %q backend\n", driver)
+	return closeWith(b, closePools), nil
+}
+
+func openSQLBackend(ctx context.Context, driver string, pgOpt *postgres.Options) (backend.Backend, error) {
+	if flags.backendReplica == "" {
+		b, err := postgres.Open(ctx, driver, flags.backend, pgOpt)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Connected to %q backend\n", driver)
+		return b, nil
+	}
+
+	replica, err := sql.Open(driver, flags.backendReplica)
+	if err != nil {
+		return nil, err
+	}
+	pgOpt.Replica = replica
+
+	b, err := postgres.Open(ctx, driver, flags.backend, pgOpt)
+	if err != nil {
+		_ = replica.Close()
+		return nil, err
+	}
+	log.Printf("Connected to %q backend\n", driver)
+	return closeWith(b, func() { _ = replica.Close() }), nil
+}
+
+func openPgxPool(ctx context.Context, dsn string) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if flags.pgMaxConns > 0 {
+		config.MaxConns = int32(flags.pgMaxConns)
+	}
+	if flags.pgMaxIdleTime > 0 {
+		config.MaxConnIdleTime = flags.pgMaxIdleTime
+	}
+	if flags.pgStmtTimeout > 0 {
+		config.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(flags.pgStmtTimeout.Milliseconds(), 10)
+	}
+	return pgxpool.NewWithConfig(ctx, config)
+}
+
+// closeWith calls fn after the backend was closed.
+func closeWith(b backend.Backend, fn func()) backend.Backend {
+	return &closer{Backend: b, fn: fn}
+}
+
+type closer struct {
+	backend.Backend
+	fn func()
+}
+
+func (c *closer) Close() error {
+	err := c.Backend.Close()
+	c.fn()
+	return err
+}
+
+func parseRaftPeers(s string) ([]raft.Peer, error) {
+	var peers []raft.Peer
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+
+		id, addrs, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid raft peer %q", part)
+		}
+		raftAddr, rpcAddr, ok := strings.Cut(addrs, "/")
+		if !ok || id == "" || raftAddr == "" || rpcAddr == "" {
+			return nil, fmt.Errorf("invalid raft peer %q", part)
+		}
+		peers = append(peers, raft.Peer{ID: id, Address: raftAddr, RPCAddr: rpcAddr})
+	}
+	if len(peers) == 0 {
+		return nil, fmt.Errorf("no raft peers given")
+	}
+	return peers, nil
+}
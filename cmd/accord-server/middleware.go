@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/bsm/accord/backend"
+	"github.com/bsm/accord/backend/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// applyMiddlewares wraps b with the middlewares enabled via flags. The
+// returned shutdown function flushes and stops background components.
+func applyMiddlewares(ctx context.Context, b backend.Backend) (backend.Backend, func(), error) {
+	var (
+		middlewares []backend.Middleware
+		shutdowns   []func()
+	)
+	shutdown := func() {
+		for _, fn := range shutdowns {
+			fn()
+		}
+	}
+
+	if flags.backendLog {
+		middlewares = append(middlewares, middleware.Logging(log.Default()))
+	}
+
+	if flags.tracing {
+		exporter, err := otlptracegrpc.New(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+		shutdowns = append(shutdowns, func() { _ = provider.Shutdown(context.Background()) })
+		middlewares = append(middlewares, middleware.Tracing(provider))
+	}
+
+	if flags.metricsAddr != "" {
+		reg := prometheus.NewRegistry()
+		middlewares = append(middlewares, middleware.Metrics(reg))
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+		srv := &http.Server{Addr: flags.metricsAddr, Handler: mux}
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("Metrics server failed: %v\n", err)
+			}
+		}()
+		shutdowns = append(shutdowns, func() { _ = srv.Close() })
+		log.Printf("Serving metrics on %s\n", flags.metricsAddr)
+	}
+
+	if flags.backendRetries > 0 {
+		middlewares = append(middlewares, middleware.Retry(&middleware.RetryOptions{Attempts: flags.backendRetries + 1}))
+	}
+
+	if flags.backendTimeout > 0 {
+		middlewares = append(middlewares, middleware.Timeout(flags.backendTimeout))
+	}
+
+	return backend.Chain(b, middlewares...), shutdown, nil
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/bsm/accord/backend/postgres"
+)
+
+const migrateUsage = "usage: accord-server [flags] migrate up|down|status [-dry-run]"
+
+// runMigrate runs the migrate subcommand.
+func runMigrate(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	cmd := args[0]
+	fs := flag.NewFlagSet("migrate "+cmd, flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "Print the SQL statements instead of applying them")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	m, closer, err := openMigrator(ctx)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	current, latest, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	target := current
+	switch cmd {
+	case "up":
+		target = latest
+	case "down":
+		if target > 0 {
+			target--
+		}
+	case "status":
+		fmt.Printf("Current version: %d\nLatest version:  %d\n", current, latest)
+		if current >= latest {
+			return nil
+		}
+		fmt.Printf("\nPending SQL:\n\n")
+		return printPlan(ctx, m, latest)
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	if *dryRun {
+		return printPlan(ctx, m, target)
+	}
+	if err := m.MigrateTo(ctx, target); err != nil {
+		return err
+	}
+	fmt.Printf("Migrated from version %d to %d\n", current, target)
+	return nil
+}
+
+func openMigrator(ctx context.Context) (*postgres.Migrator, func(), error) {
+	opt := &postgres.Options{
+		Schema:      flags.pgSchema,
+		TablePrefix: flags.pgTablePrefix,
+	}
+
+	driver := strings.SplitN(flags.backend, ":", 2)[0]
+	if driver != "postgres" && driver != "postgresql" {
+		db, err := sql.Open(driver, flags.backend)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		m, err := postgres.NewMigrator(db, opt)
+		if err != nil {
+			_ = db.Close()
+			return nil, nil, err
+		}
+		return m, func() { _ = db.Close() }, nil
+	}
+
+	pool, err := openPgxPool(ctx, flags.backend)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := postgres.NewPgxMigrator(pool, opt)
+	if err != nil {
+		pool.Close()
+		return nil, nil, err
+	}
+	return m, pool.Close, nil
+}
+
+func printPlan(ctx context.Context, m *postgres.Migrator, target int) error {
+	queries, err := m.Plan(ctx, target)
+	if err != nil {
+		return err
+	}
+	for _, query := range queries {
+		fmt.Printf("%s;\n\n", query)
+	}
+	return nil
+}
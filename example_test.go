@@ -3,6 +3,7 @@ package accord_test
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/bsm/accord"
 )
@@ -40,3 +41,30 @@ func ExampleClient() {
 		panic(err)
 	}
 }
+
+func ExampleClient_Acquire() {
+	ctx := context.Background()
+
+	client, err := accord.DialClient(ctx, "10.0.0.1:8432", &accord.ClientOptions{
+		Namespace: "/custom/namespace",
+	})
+	if err != nil {
+		panic(err)
+	}
+	defer client.Close()
+
+	// Acquire a long-running resource in a different namespace.
+	handle, err := client.Acquire(ctx, "my::resource", map[string]string{"stage": "init"},
+		accord.WithNamespace("/custom/reports"),
+		accord.WithTTL(time.Hour),
+	)
+	if err != nil {
+		panic(err)
+	}
+	defer handle.Discard()
+
+	// Shorten the lease once the heavy lifting is done.
+	if err := handle.SetTTL(ctx, time.Minute); err != nil {
+		panic(err)
+	}
+}
@@ -2,18 +2,51 @@ package accord
 
 import (
 	"context"
+	"encoding/base64"
+	"maps"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/bsm/accord/rpc"
 	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
+// UpdateOption configures a single Renew or Done call.
+type UpdateOption func(*updateOptions)
+
+type updateOptions struct {
+	version uint64
+}
+
+// WithExpectedVersion fails the update with ErrVersionMismatch unless the
+// stored metadata version matches, i.e. if the metadata has been modified
+// by someone else since.
+func WithExpectedVersion(version uint64) UpdateOption {
+	return func(o *updateOptions) { o.version = version }
+}
+
 // Handle holds temporary ownership of a resource. It will automatically renew
 // its ownership in the background until either Done or Discard is called (first one wins).
 // After a call to Done or Discard, all operations on the handle fail with ErrClosed.
 type Handle struct {
-	id   uuid.UUID
+	id        atomic.Pointer[uuid.UUID]
+	name      string
+	namespace string
+	attempt   int
+	prevOwner string
+	token     uint64
+	slot      int
+	shared    bool
+	stored    map[string]string // stored metadata as of version, guarded by mu
+	version   atomic.Uint64     // metadata version
+	expTime   atomic.Int64      // unix nanoseconds
+	ttl       atomic.Int64      // lease duration
+	ttlSet    chan struct{}
+
 	rpc  rpc.V1Client
 	meta *metadata
 	opt  *ClientOptions
@@ -23,15 +56,29 @@ type Handle struct {
 	close context.CancelFunc
 }
 
-func newHandle(id uuid.UUID, rpc rpc.V1Client, meta map[string]string, opt *ClientOptions) *Handle {
+func newHandle(id uuid.UUID, client rpc.V1Client, data *rpc.Handle, opt *ClientOptions, ttl time.Duration) *Handle {
 	ctx, close := context.WithCancel(context.Background())
 	h := &Handle{
-		id:    id,
-		rpc:   rpc,
-		meta:  &metadata{kv: meta},
-		opt:   opt,
-		ctx:   ctx,
-		close: close,
+		name:      data.Name,
+		namespace: data.Namespace,
+		attempt:   int(data.NumAcquired),
+		prevOwner: data.PreviousOwner,
+		token:     data.FencingToken,
+		slot:      int(data.Slot),
+		shared:    data.Mode == rpc.Mode_SHARED,
+		rpc:       client,
+		stored:    maps.Clone(data.Metadata),
+		meta:      &metadata{kv: maps.Clone(data.Metadata)},
+		opt:       opt,
+		ttlSet:    make(chan struct{}, 1),
+		ctx:       ctx,
+		close:     close,
+	}
+	h.id.Store(&id)
+	h.ttl.Store(int64(ttl))
+	h.version.Store(data.MetadataVersion)
+	if exp := data.ExpTime(); !exp.IsZero() {
+		h.expTime.Store(exp.UnixNano())
 	}
 	go h.renewLoop()
 	return h
@@ -39,7 +86,56 @@ func newHandle(id uuid.UUID, rpc rpc.V1Client, meta map[string]string, opt *Clie
 
 // ID returns the handle ID.
 func (h *Handle) ID() uuid.UUID {
-	return h.id
+	return *h.id.Load()
+}
+
+// Name returns the resource name.
+func (h *Handle) Name() string {
+	return h.name
+}
+
+// Namespace returns the resource namespace.
+func (h *Handle) Namespace() string {
+	return h.namespace
+}
+
+// Attempt returns the number of times the resource has been acquired,
+// including this time.
+func (h *Handle) Attempt() int {
+	return h.attempt
+}
+
+// ExpiresAt returns the (estimated) expiration time of the current lease.
+// It is extended with every successful renewal.
+func (h *Handle) ExpiresAt() time.Time {
+	if ns := h.expTime.Load(); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
+
+// PreviousOwner returns the owner which held the resource before it was
+// taken over by this handle. It is empty on the first attempt.
+func (h *Handle) PreviousOwner() string {
+	return h.prevOwner
+}
+
+// FencingToken returns the fencing token of this acquisition. Tokens
+// strictly increase with every acquisition of the resource, downstream
+// systems can use them to reject writes from previous owners.
+func (h *Handle) FencingToken() uint64 {
+	return h.token
+}
+
+// Slot returns the slot occupied by the handle, zero for plain exclusive
+// handles.
+func (h *Handle) Slot() int {
+	return h.slot
+}
+
+// Shared returns true if the handle was acquired in shared mode.
+func (h *Handle) Shared() bool {
+	return h.shared
 }
 
 // Metadata returns metadata.
@@ -47,42 +143,148 @@ func (h *Handle) Metadata() map[string]string {
 	return h.meta.Snap()
 }
 
+// MetadataVersion returns the metadata version as of the acquisition or the
+// last successful Renew or Done call of this handle. It can be passed to
+// WithExpectedVersion to guard against concurrent modifications.
+func (h *Handle) MetadataVersion() uint64 {
+	return h.version.Load()
+}
+
 // SetMeta sets a metadata key.
 // It will only be persisted with the next (background) Renew or Done call.
 func (h *Handle) SetMeta(key, value string) {
 	h.meta.Set(key, value)
 }
 
+// DeleteMeta deletes a metadata key.
+// It will only be persisted with the next (background) Renew or Done call.
+func (h *Handle) DeleteMeta(key string) {
+	h.meta.Delete(key)
+}
+
+// ReplaceMeta replaces all metadata, deleting keys which are not in meta.
+// It will only be persisted with the next (background) Renew or Done call,
+// which replaces the stored metadata atomically.
+func (h *Handle) ReplaceMeta(meta map[string]string) {
+	h.meta.Replace(meta)
+}
+
+// TTL returns the lease duration.
+func (h *Handle) TTL() time.Duration {
+	return time.Duration(h.ttl.Load())
+}
+
+// SetTTL changes the lease duration and renews the ownership of the
+// resource immediately. Background renewals use the new duration.
+func (h *Handle) SetTTL(ctx context.Context, ttl time.Duration) error {
+	if h.isClosed() {
+		return ErrClosed
+	} else if ttl < time.Second {
+		return ErrInvalidTTL
+	}
+
+	h.ttl.Store(int64(ttl))
+	select {
+	case h.ttlSet <- struct{}{}:
+	default:
+	}
+	return h.renew(ctx, ttlSeconds(ttl), 0)
+}
+
 // Renew manually renews the ownership of the resource with custom metadata.
-func (h *Handle) Renew(ctx context.Context, meta map[string]string) error {
+func (h *Handle) Renew(ctx context.Context, meta map[string]string, opts ...UpdateOption) error {
 	if h.isClosed() {
 		return ErrClosed
 	}
 
+	var o updateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	h.meta.Update(meta)
-	return h.renew(ctx, h.opt.ttlSeconds())
+	return h.renew(ctx, ttlSeconds(h.TTL()), o.version)
 }
 
 // Done marks the resource as done and invalidates the handle.
-func (h *Handle) Done(ctx context.Context, meta map[string]string) error {
+func (h *Handle) Done(ctx context.Context, meta map[string]string, opts ...UpdateOption) error {
 	if h.isClosed() {
 		return ErrClosed
 	}
 
+	var o updateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	h.meta.Update(meta)
 
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	if h.isClosed() {
+		return ErrClosed
+	}
+
+	handleID := h.ID()
+	meta, deleted, replace := h.meta.Pending()
 	_, err := h.rpc.Done(ctx, &rpc.DoneRequest{
+		Owner:           h.opt.Owner,
+		HandleId:        handleID[:],
+		Metadata:        meta,
+		DeleteMetadata:  deleted,
+		ReplaceMetadata: replace != 0,
+		ExpectedVersion: o.version,
+		FencingToken:    h.token,
+	})
+	if err != nil {
+		return normError(err)
+	}
+
+	h.synced(meta, deleted, replace)
+	h.close()
+	return nil
+}
+
+// Transfer hands the resource over to newOwner and invalidates the handle.
+// Pending metadata changes are persisted first. It returns a token which the
+// new owner can pass to Client.Resume to take over renewals. Once transferred,
+// ID returns the rotated handle ID.
+func (h *Handle) Transfer(ctx context.Context, newOwner string) (string, error) {
+	// hold the lock throughout to pause background renewals
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.isClosed() {
+		return "", ErrClosed
+	}
+	if err := h.renewLocked(ctx, ttlSeconds(h.TTL()), 0); err != nil {
+		return "", err
+	}
+
+	handleID := h.ID()
+	res, err := h.rpc.Transfer(ctx, &rpc.TransferRequest{
 		Owner:    h.opt.Owner,
-		HandleId: h.id[:],
-		Metadata: h.meta.Snap(),
+		HandleId: handleID[:],
+		NewOwner: newOwner,
+		RotateId: true,
 	})
-	if err == nil {
-		h.close()
+	if err != nil {
+		return "", err
 	}
-	return err
+
+	newID, err := uuid.FromBytes(res.Handle.Id)
+	if err != nil {
+		return "", err
+	}
+	h.id.Store(&newID)
+	h.close()
+
+	data, err := proto.Marshal(res.Handle)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
 }
 
 // Discard discards the handle.
@@ -92,7 +294,7 @@ func (h *Handle) Discard() error {
 	}
 
 	defer h.close()
-	return h.renew(h.ctx, 0)
+	return h.renew(h.ctx, 0, 0)
 }
 
 func (h *Handle) isClosed() bool {
@@ -104,31 +306,92 @@ func (h *Handle) isClosed() bool {
 	}
 }
 
-func (h *Handle) renew(ctx context.Context, seconds uint32) error {
+func (h *Handle) renew(ctx context.Context, seconds uint32, version uint64) error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	if h.isClosed() {
+		return ErrClosed
+	}
+	return h.renewLocked(ctx, seconds, version)
+}
+
+// renewLocked renews the handle. It must be called while holding the lock.
+func (h *Handle) renewLocked(ctx context.Context, seconds uint32, version uint64) error {
+	start := time.Now()
+	handleID := h.ID()
+	meta, deleted, replace := h.meta.Pending()
 	_, err := h.rpc.Renew(ctx, &rpc.RenewRequest{
-		Owner:    h.opt.Owner,
-		HandleId: h.id[:],
-		Ttl:      seconds,
-		Metadata: h.meta.Snap(),
+		Owner:           h.opt.Owner,
+		HandleId:        handleID[:],
+		Ttl:             seconds,
+		Metadata:        meta,
+		DeleteMetadata:  deleted,
+		ReplaceMetadata: replace != 0,
+		ExpectedVersion: version,
+		FencingToken:    h.token,
 	})
+	if err != nil {
+		return normError(err)
+	}
+
+	h.meta.Ack(deleted, replace)
+	h.synced(meta, deleted, replace)
+	h.expTime.Store(start.Add(time.Duration(seconds) * time.Second).UnixNano())
+	return nil
+}
+
+// synced applies a successful update to the stored metadata and increments
+// the metadata version if it has changed, the same way backends do.
+// It must be called while holding the lock.
+func (h *Handle) synced(meta map[string]string, deleted []string, replace uint64) {
+	next := maps.Clone(meta)
+	if replace == 0 {
+		next = maps.Clone(h.stored)
+		if next == nil {
+			next = make(map[string]string, len(meta))
+		}
+		for _, k := range deleted {
+			delete(next, k)
+		}
+		maps.Copy(next, meta)
+	}
+
+	if !maps.Equal(h.stored, next) {
+		h.stored = next
+		h.version.Add(1)
+	}
+}
+
+// normError restores errors which were transmitted over gRPC.
+func normError(err error) error {
+	if status.Code(err) == codes.Aborted {
+		return ErrVersionMismatch
+	}
 	return err
 }
 
 func (h *Handle) renewLoop() {
-	ticker := time.NewTicker(h.opt.TTL * 3 / 10)
-	defer ticker.Stop()
+	timer := time.NewTimer(h.TTL() * 3 / 10)
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-h.ctx.Done():
 			return
-		case <-ticker.C:
+		case <-h.ttlSet:
+			// re-arm with the new TTL
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		case <-timer.C:
 			if err := h.Renew(h.ctx, nil); err != nil && err != context.Canceled && err != ErrClosed {
 				h.opt.handleError(err)
 			}
 		}
+		timer.Reset(h.TTL() * 3 / 10)
 	}
 }
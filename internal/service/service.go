@@ -2,6 +2,9 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
 	"time"
 
 	"github.com/bsm/accord"
@@ -10,6 +13,14 @@ import (
 	"github.com/google/uuid"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultPageSize   = 100
+	maxPageSize       = 1000
+	markDoneBatchSize = 500
+	maxCapacity       = 1000
 )
 
 // Service instances serve GRPC requests.
@@ -37,8 +48,14 @@ func (s *Service) Acquire(ctx context.Context, req *rpc.AcquireRequest) (*rpc.Ac
 	if req.Name == "" {
 		return nil, status.Error(codes.InvalidArgument, "invalid name")
 	}
+	if req.Capacity > maxCapacity || (req.Capacity > 1 && req.Mode == rpc.Mode_SHARED) {
+		return nil, status.Error(codes.InvalidArgument, "invalid capacity")
+	}
+	if _, ok := rpc.Mode_name[int32(req.Mode)]; !ok {
+		return nil, status.Error(codes.InvalidArgument, "invalid mode")
+	}
 
-	data, err := s.b.Acquire(ctx, req.Owner, req.Namespace, req.Name, expTime(req.Ttl), req.Metadata)
+	data, err := s.b.Acquire(ctx, req.Owner, req.Namespace, req.Name, expTime(req.Ttl), req.Metadata, acquireOptions(req))
 	if err == accord.ErrDone {
 		return &rpc.AcquireResponse{Status: rpc.Status_DONE}, nil
 	} else if err == accord.ErrAcquired {
@@ -64,8 +81,8 @@ func (s *Service) Renew(ctx context.Context, req *rpc.RenewRequest) (*rpc.RenewR
 		return nil, status.Error(codes.InvalidArgument, "invalid handle ID")
 	}
 
-	if err := s.b.Renew(ctx, req.Owner, handleID, expTime(req.Ttl), req.Metadata); err != nil {
-		return nil, err
+	if err := s.b.Renew(ctx, req.Owner, handleID, expTime(req.Ttl), req.Metadata, updateOptions(req)); err != nil {
+		return nil, normError(err)
 	}
 	return &rpc.RenewResponse{}, nil
 }
@@ -81,35 +98,308 @@ func (s *Service) Done(ctx context.Context, req *rpc.DoneRequest) (*rpc.DoneResp
 		return nil, status.Error(codes.InvalidArgument, "invalid handle ID")
 	}
 
-	if err := s.b.Done(ctx, req.Owner, handleID, req.Metadata); err != nil {
-		return nil, err
+	if err := s.b.Done(ctx, req.Owner, handleID, req.Metadata, updateOptions(req)); err != nil {
+		return nil, normError(err)
 	}
 	return &rpc.DoneResponse{}, nil
 }
 
+// Transfer implements rpc.V1Server.
+func (s *Service) Transfer(ctx context.Context, req *rpc.TransferRequest) (*rpc.TransferResponse, error) {
+	if req.Owner == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid owner")
+	}
+	if req.NewOwner == "" {
+		return nil, status.Error(codes.InvalidArgument, "invalid new owner")
+	}
+
+	handleID, err := uuid.FromBytes(req.HandleId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid handle ID")
+	}
+
+	data, err := s.b.Transfer(ctx, req.Owner, handleID, req.NewOwner, req.RotateId)
+	if err != nil {
+		return nil, normError(err)
+	}
+	return &rpc.TransferResponse{Handle: convertHandle(data)}, nil
+}
+
 // List implements rpc.V1Server.
 func (s *Service) List(req *rpc.ListRequest, srv rpc.V1_ListServer) error {
+	if _, err := backend.ParseListCursor(req); err != nil {
+		return status.Error(codes.InvalidArgument, "invalid cursor")
+	}
+	if _, err := backend.ParseListFilter(req.Filter); err != nil {
+		return status.Error(codes.InvalidArgument, "invalid metadata selector")
+	}
+
+	defaultSort := backend.IsDefaultSort(req)
 	return s.b.List(srv.Context(), req, func(data *backend.HandleData) error {
-		return srv.Send(convertHandle(data))
+		handle := convertHandle(data)
+		if defaultSort {
+			handle.Cursor = data.Cursor().String()
+		}
+		return srv.Send(handle)
+	})
+}
+
+// ListPage implements rpc.V1Server. Page tokens are cursors for the default
+// sort and offsets for all other sorts.
+func (s *Service) ListPage(ctx context.Context, req *rpc.ListRequest) (*rpc.ListPageResponse, error) {
+	defaultSort := backend.IsDefaultSort(req)
+	page := proto.Clone(req).(*rpc.ListRequest)
+	if defaultSort {
+		if _, err := backend.ParseListCursor(req); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+		}
+	} else if req.Cursor != "" {
+		offset, err := parseOffsetToken(req.Cursor)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid cursor")
+		}
+		page.Cursor, page.Offset = "", offset
+	}
+	if _, err := backend.ParseListFilter(req.Filter); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid metadata selector")
+	}
+
+	pageSize := req.Limit
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	} else if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	// fetch one extra handle to detect the last page
+	page.Limit = pageSize + 1
+
+	res := new(rpc.ListPageResponse)
+	var last *backend.HandleData
+	if err := s.b.List(ctx, page, func(data *backend.HandleData) error {
+		if len(res.Handles) == int(pageSize) {
+			if defaultSort {
+				res.NextPageToken = last.Cursor().String()
+			} else {
+				res.NextPageToken = offsetToken(page.Offset + uint64(pageSize))
+			}
+			return backend.ErrIteratorDone
+		}
+
+		handle := convertHandle(data)
+		if defaultSort {
+			handle.Cursor = data.Cursor().String()
+		}
+		res.Handles = append(res.Handles, handle)
+		last = data
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	num, err := s.b.Count(ctx, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	res.TotalEstimate = uint64(num)
+	return res, nil
+}
+
+// MarkDone implements rpc.V1Server.
+func (s *Service) MarkDone(srv rpc.V1_MarkDoneServer) error {
+	res := new(rpc.MarkDoneResponse)
+	batch := make([]backend.DoneRecord, 0, markDoneBatchSize)
+	offset := uint64(0)
+
+	flush := func() error {
+		errs, err := s.b.MarkDone(srv.Context(), batch)
+		if err != nil {
+			return err
+		}
+
+		for i, err := range errs {
+			rec := batch[i]
+			conflict := &rpc.MarkDoneResponse_Conflict{Index: offset + uint64(i), Namespace: rec.Namespace, Name: rec.Name}
+			switch err {
+			case nil:
+				res.NumMarked++
+				continue
+			case accord.ErrDone:
+				conflict.Status = rpc.Status_DONE
+			case accord.ErrAcquired:
+				conflict.Status = rpc.Status_HELD
+			default:
+				return err
+			}
+			res.Conflicts = append(res.Conflicts, conflict)
+		}
+
+		offset += uint64(len(batch))
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		req, err := srv.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if req.Owner == "" {
+			return status.Error(codes.InvalidArgument, "invalid owner")
+		}
+		if req.Name == "" {
+			return status.Error(codes.InvalidArgument, "invalid name")
+		}
+
+		batch = append(batch, backend.DoneRecord{
+			Owner:     req.Owner,
+			Namespace: req.Namespace,
+			Name:      req.Name,
+			DoneTime:  req.DoneTime(),
+			Metadata:  req.Metadata,
+		})
+		if len(batch) == markDoneBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(batch) != 0 {
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+	return srv.SendAndClose(res)
+}
+
+// Purge implements rpc.V1Server.
+func (s *Service) Purge(ctx context.Context, req *rpc.PurgeRequest) (*rpc.PurgeResponse, error) {
+	if req.DoneBeforeTms <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid cutoff time")
+	}
+
+	num, err := s.b.Purge(ctx, &backend.PurgeFilter{
+		Prefix:  req.Prefix,
+		Exclude: req.Exclude,
+		Before:  req.DoneBefore(),
 	})
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.PurgeResponse{NumPurged: uint64(num)}, nil
+}
+
+// ValidateToken implements rpc.V1Server.
+func (s *Service) ValidateToken(ctx context.Context, req *rpc.ValidateTokenRequest) (*rpc.ValidateTokenResponse, error) {
+	handleID, err := uuid.FromBytes(req.HandleId)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid handle ID")
+	}
+
+	err = s.b.ValidateToken(ctx, handleID, int64(req.FencingToken))
+	if err == backend.ErrStaleToken {
+		return &rpc.ValidateTokenResponse{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &rpc.ValidateTokenResponse{Valid: true}, nil
+}
+
+// Check implements rpc.V1Server.
+func (s *Service) Check(_ context.Context, _ *rpc.CheckRequest) (*rpc.CheckResponse, error) {
+	if err := s.b.Ping(); err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &rpc.CheckResponse{}, nil
 }
 
 func convertHandle(data *backend.HandleData) *rpc.Handle {
 	return &rpc.Handle{
-		Id:          data.ID[:],
-		Name:        data.Name,
-		Namespace:   data.Namespace,
-		ExpTms:      timeToMillis(data.ExpTime),
-		DoneTms:     timeToMillis(data.DoneTime),
-		NumAcquired: uint32(data.NumAcquired),
-		Metadata:    data.Metadata,
+		Id:              data.ID[:],
+		Name:            data.Name,
+		Namespace:       data.Namespace,
+		Owner:           data.Owner,
+		PreviousOwner:   data.PreviousOwner,
+		CreatedTms:      timeToMillis(data.CreatedTime),
+		UpdatedTms:      timeToMillis(data.UpdatedTime),
+		ExpTms:          timeToMillis(data.ExpTime),
+		DoneTms:         timeToMillis(data.DoneTime),
+		NumAcquired:     uint32(data.NumAcquired),
+		Metadata:        data.Metadata,
+		MetadataVersion: uint64(data.MetaVersion),
+		FencingToken:    uint64(data.FencingToken),
+		Slot:            uint32(data.Slot),
+		Capacity:        uint32(data.Capacity),
+		Mode:            data.Mode,
+		Queued:          data.Queued,
+	}
+}
+
+func acquireOptions(req *rpc.AcquireRequest) *backend.AcquireOptions {
+	if req.Capacity < 2 && req.Mode == rpc.Mode_EXCLUSIVE {
+		return nil
+	}
+	return &backend.AcquireOptions{Capacity: int(req.Capacity), Mode: req.Mode}
+}
+
+// updateRequest is implemented by rpc.RenewRequest and rpc.DoneRequest.
+type updateRequest interface {
+	GetExpectedVersion() uint64
+	GetDeleteMetadata() []string
+	GetReplaceMetadata() bool
+	GetFencingToken() uint64
+}
+
+func updateOptions(req updateRequest) *backend.UpdateOptions {
+	opt := &backend.UpdateOptions{
+		ExpectedVersion: int64(req.GetExpectedVersion()),
+		DeleteMeta:      req.GetDeleteMetadata(),
+		ReplaceMeta:     req.GetReplaceMetadata(),
+		FencingToken:    int64(req.GetFencingToken()),
 	}
+	if opt.ExpectedVersion == 0 && len(opt.DeleteMeta) == 0 && !opt.ReplaceMeta && opt.FencingToken == 0 {
+		return nil
+	}
+	return opt
+}
+
+// normError converts backend errors to gRPC status errors.
+func normError(err error) error {
+	switch err {
+	case backend.ErrVersionMismatch:
+		return status.Error(codes.Aborted, err.Error())
+	case backend.ErrStaleToken:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
 }
 
 func expTime(ttl uint32) time.Time {
 	return time.Now().Add(time.Second * time.Duration(ttl))
 }
 
+// offsetToken encodes a list offset as an opaque page token.
+func offsetToken(offset uint64) string {
+	return base64.RawURLEncoding.EncodeToString(binary.AppendUvarint(nil, offset))
+}
+
+// parseOffsetToken decodes a page token created by offsetToken.
+func parseOffsetToken(s string) (uint64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, err
+	}
+	offset, n := binary.Uvarint(b)
+	if n <= 0 || n != len(b) {
+		return 0, backend.ErrInvalidCursor
+	}
+	return offset, nil
+}
+
 func timeToMillis(t time.Time) int64 {
 	if u := t.Unix(); u > 0 {
 		return u*1e3 + int64(t.Nanosecond())/1e6
@@ -2,6 +2,7 @@ package service_test
 
 import (
 	"context"
+	"io"
 	"testing"
 	"time"
 
@@ -42,18 +43,56 @@ var _ = Describe("V1Service", func() {
 		Expect(res.Handle.Id).To(HaveLen(16))
 		Expect(res.Handle.Namespace).To(Equal("ns"))
 		Expect(res.Handle.Name).To(Equal("resource"))
+		Expect(res.Handle.Owner).To(Equal(owner))
+		Expect(res.Handle.PreviousOwner).To(BeEmpty())
+		Expect(res.Handle.CreatedTime()).To(BeTemporally("~", time.Now(), time.Second))
+		Expect(res.Handle.UpdatedTime()).To(BeTemporally("~", time.Now(), time.Second))
 		Expect(res.Handle.ExpTime()).To(BeTemporally("~", time.Now().Add(time.Minute), 2*time.Second))
 		Expect(res.Handle.NumAcquired).To(Equal(uint32(1)))
 		Expect(res.Handle.Metadata).To(Equal(map[string]string{"k": "v"}))
 	})
 
+	It("should acquire semaphores", func() {
+		_, err := subject.Acquire(ctx, &rpc.AcquireRequest{Owner: owner, Name: "resource", Capacity: 1001})
+		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid capacity`))
+
+		for i := 0; i < 2; i++ {
+			res, err := subject.Acquire(ctx, &rpc.AcquireRequest{Owner: owner, Name: "resource", Ttl: 60, Capacity: 2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.Status).To(Equal(rpc.Status_OK))
+			Expect(res.Handle.Slot).To(Equal(uint32(i)))
+			Expect(res.Handle.Capacity).To(Equal(uint32(2)))
+		}
+
+		res, err := subject.Acquire(ctx, &rpc.AcquireRequest{Owner: owner, Name: "resource", Ttl: 60, Capacity: 2})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Status).To(Equal(rpc.Status_HELD))
+	})
+
+	It("should acquire shared handles", func() {
+		_, err := subject.Acquire(ctx, &rpc.AcquireRequest{Owner: owner, Name: "resource", Capacity: 2, Mode: rpc.Mode_SHARED})
+		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid capacity`))
+		_, err = subject.Acquire(ctx, &rpc.AcquireRequest{Owner: owner, Name: "resource", Mode: 7})
+		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid mode`))
+
+		res, err := subject.Acquire(ctx, &rpc.AcquireRequest{Owner: owner, Name: "resource", Ttl: 60, Mode: rpc.Mode_SHARED})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Status).To(Equal(rpc.Status_OK))
+		Expect(res.Handle.Mode).To(Equal(rpc.Mode_SHARED))
+		Expect(res.Handle.Slot).To(Equal(uint32(1)))
+
+		res, err = subject.Acquire(ctx, &rpc.AcquireRequest{Owner: owner, Name: "resource", Ttl: 60})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Status).To(Equal(rpc.Status_HELD))
+	})
+
 	It("should renew", func() {
 		_, err := subject.Renew(ctx, &rpc.RenewRequest{})
 		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid owner`))
 		_, err = subject.Renew(ctx, &rpc.RenewRequest{Owner: owner})
 		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid handle ID`))
 
-		h, err := backend.Acquire(ctx, owner, "ns", "resource", time.Now(), nil)
+		h, err := backend.Acquire(ctx, owner, "ns", "resource", time.Now(), nil, nil)
 		Expect(err).NotTo(HaveOccurred())
 
 		_, err = subject.Renew(ctx, &rpc.RenewRequest{Owner: owner, HandleId: h.ID[:], Ttl: 60})
@@ -62,6 +101,17 @@ var _ = Describe("V1Service", func() {
 		h, err = backend.Get(ctx, h.ID)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(h.ExpTime).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+
+		_, err = subject.Renew(ctx, &rpc.RenewRequest{Owner: owner, HandleId: h.ID[:], Ttl: 60, Metadata: map[string]string{"k": "v"}, ExpectedVersion: 1})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = subject.Renew(ctx, &rpc.RenewRequest{Owner: owner, HandleId: h.ID[:], Ttl: 60, ExpectedVersion: 1})
+		Expect(err).To(MatchError(`rpc error: code = Aborted desc = accord: metadata version mismatch`))
+
+		_, err = subject.Renew(ctx, &rpc.RenewRequest{Owner: owner, HandleId: h.ID[:], Ttl: 60, Metadata: map[string]string{"l": "w"}, DeleteMetadata: []string{"k"}})
+		Expect(err).NotTo(HaveOccurred())
+		h, err = backend.Get(ctx, h.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(h.Metadata).To(Equal(map[string]string{"l": "w"}))
 	})
 
 	It("should mark done", func() {
@@ -70,7 +120,7 @@ var _ = Describe("V1Service", func() {
 		_, err = subject.Done(ctx, &rpc.DoneRequest{Owner: owner})
 		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid handle ID`))
 
-		h, err := backend.Acquire(ctx, owner, "ns", "resource", time.Now(), nil)
+		h, err := backend.Acquire(ctx, owner, "ns", "resource", time.Now(), nil, nil)
 		Expect(err).NotTo(HaveOccurred())
 
 		_, err = subject.Done(ctx, &rpc.DoneRequest{Owner: owner, HandleId: h.ID[:]})
@@ -82,10 +132,10 @@ var _ = Describe("V1Service", func() {
 	})
 
 	It("should list", func() {
-		Expect(backend.Acquire(ctx, owner, "", "res1", time.Now(), nil)).NotTo(BeNil())
-		Expect(backend.Acquire(ctx, owner, "", "res2", time.Now(), nil)).NotTo(BeNil())
+		Expect(backend.Acquire(ctx, owner, "", "res1", time.Now(), nil, nil)).NotTo(BeNil())
+		Expect(backend.Acquire(ctx, owner, "", "res2", time.Now(), nil, nil)).NotTo(BeNil())
 
-		h, err := backend.Acquire(ctx, owner, "", "res3", time.Now(), nil)
+		h, err := backend.Acquire(ctx, owner, "", "res3", time.Now(), nil, nil)
 		Expect(err).NotTo(HaveOccurred())
 		_, err = subject.Done(ctx, &rpc.DoneRequest{Owner: owner, HandleId: h.ID[:]})
 		Expect(err).NotTo(HaveOccurred())
@@ -101,6 +151,154 @@ var _ = Describe("V1Service", func() {
 		Expect(mock.sent[1].Name).To(Equal("res2"))
 		Expect(mock.sent[1].ExpTime()).To(BeTemporally("~", time.Now(), time.Second))
 		Expect(mock.sent[1].DoneTime()).To(BeZero())
+
+		Expect(subject.List(&rpc.ListRequest{Cursor: "invalid"}, mock)).To(MatchError(`rpc error: code = InvalidArgument desc = invalid cursor`))
+		Expect(subject.List(&rpc.ListRequest{Cursor: h.Cursor().String(), Sort: rpc.ListRequest_NAME}, mock)).To(MatchError(`rpc error: code = InvalidArgument desc = invalid cursor`))
+		Expect(subject.List(&rpc.ListRequest{
+			Filter: &rpc.ListRequest_Filter{MetadataSelector: "env in prod"},
+		}, mock)).To(MatchError(`rpc error: code = InvalidArgument desc = invalid metadata selector`))
+
+		mock.sent = nil
+		Expect(subject.List(&rpc.ListRequest{Cursor: h.Cursor().String()}, mock)).To(Succeed())
+		Expect(mock.sent).To(HaveLen(2))
+		Expect(mock.sent[0].Name).To(Equal("res2"))
+		Expect(mock.sent[0].Cursor).NotTo(BeEmpty())
+	})
+
+	It("should list pages", func() {
+		for _, name := range []string{"r1", "r2", "r3"} {
+			_, err := backend.Acquire(ctx, owner, "ns", name, time.Now().Add(time.Minute), nil, nil)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		_, err := backend.Acquire(ctx, "OTHER", "ns", "r4", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		req := &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Owner: owner}, Limit: 2}
+		res, err := subject.ListPage(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Handles).To(HaveLen(2))
+		Expect(res.Handles[0].Name).To(Equal("r3"))
+		Expect(res.Handles[1].Name).To(Equal("r2"))
+		Expect(res.NextPageToken).To(Equal(res.Handles[1].Cursor))
+		Expect(res.TotalEstimate).To(Equal(uint64(3)))
+		cursor := res.NextPageToken
+
+		req.Cursor = res.NextPageToken
+		res, err = subject.ListPage(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Handles).To(HaveLen(1))
+		Expect(res.Handles[0].Name).To(Equal("r1"))
+		Expect(res.NextPageToken).To(BeEmpty())
+		Expect(res.TotalEstimate).To(Equal(uint64(3)))
+
+		req = &rpc.ListRequest{Sort: rpc.ListRequest_NAME, Order: rpc.ListRequest_ASC, Limit: 3}
+		res, err = subject.ListPage(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Handles).To(HaveLen(3))
+		Expect(res.Handles[0].Name).To(Equal("r1"))
+		Expect(res.Handles[2].Name).To(Equal("r3"))
+		Expect(res.Handles[2].Cursor).To(BeEmpty())
+		Expect(res.NextPageToken).NotTo(BeEmpty())
+		Expect(res.TotalEstimate).To(Equal(uint64(4)))
+
+		req.Cursor = res.NextPageToken
+		res, err = subject.ListPage(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Handles).To(HaveLen(1))
+		Expect(res.Handles[0].Name).To(Equal("r4"))
+		Expect(res.NextPageToken).To(BeEmpty())
+
+		_, err = subject.ListPage(ctx, &rpc.ListRequest{Sort: rpc.ListRequest_NAME, Cursor: cursor})
+		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid cursor`))
+
+		_, err = subject.ListPage(ctx, &rpc.ListRequest{Cursor: "invalid"})
+		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid cursor`))
+	})
+
+	It("should mark done in bulk", func() {
+		Expect(subject.MarkDone(&mockMarkDoneServer{reqs: []*rpc.MarkDoneRequest{{Name: "resource"}}})).
+			To(MatchError(`rpc error: code = InvalidArgument desc = invalid owner`))
+
+		_, err := backend.Acquire(ctx, owner, "ns", "held", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		srv := &mockMarkDoneServer{reqs: []*rpc.MarkDoneRequest{
+			{Owner: owner, Namespace: "ns", Name: "r1", DoneTms: 1515151515000, Metadata: map[string]string{"k": "v"}},
+			{Owner: owner, Namespace: "ns", Name: "held"},
+			{Owner: owner, Namespace: "ns", Name: "r2"},
+			{Owner: owner, Namespace: "ns", Name: "r1"},
+		}}
+		Expect(subject.MarkDone(srv)).To(Succeed())
+		Expect(srv.res.NumMarked).To(Equal(uint64(2)))
+		Expect(srv.res.Conflicts).To(HaveLen(2))
+		Expect(srv.res.Conflicts[0].Index).To(Equal(uint64(1)))
+		Expect(srv.res.Conflicts[0].Name).To(Equal("held"))
+		Expect(srv.res.Conflicts[0].Status).To(Equal(rpc.Status_HELD))
+		Expect(srv.res.Conflicts[1].Index).To(Equal(uint64(3)))
+		Expect(srv.res.Conflicts[1].Status).To(Equal(rpc.Status_DONE))
+
+		res, err := subject.ListPage(ctx, &rpc.ListRequest{Filter: &rpc.ListRequest_Filter{Status: rpc.ListRequest_Filter_DONE}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Handles).To(HaveLen(2))
+		Expect(res.Handles[1].Name).To(Equal("r1"))
+		Expect(res.Handles[1].DoneTms).To(Equal(int64(1515151515000)))
+		Expect(res.Handles[1].Metadata).To(Equal(map[string]string{"k": "v"}))
+	})
+
+	It("should purge", func() {
+		_, err := subject.Purge(ctx, &rpc.PurgeRequest{})
+		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid cutoff time`))
+
+		h, err := backend.Acquire(ctx, owner, "ns", "resource", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(backend.Done(ctx, owner, h.ID, nil, nil)).To(Succeed())
+
+		res, err := subject.Purge(ctx, &rpc.PurgeRequest{Prefix: "ns", DoneBeforeTms: time.Now().Add(time.Minute).UnixNano() / 1e6})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.NumPurged).To(Equal(uint64(1)))
+		Expect(backend.Get(ctx, h.ID)).To(BeNil())
+	})
+
+	It("should transfer", func() {
+		_, err := subject.Transfer(ctx, &rpc.TransferRequest{})
+		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid owner`))
+		_, err = subject.Transfer(ctx, &rpc.TransferRequest{Owner: owner})
+		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid new owner`))
+		_, err = subject.Transfer(ctx, &rpc.TransferRequest{Owner: owner, NewOwner: "other"})
+		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid handle ID`))
+
+		h, err := backend.Acquire(ctx, owner, "ns", "resource", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := subject.Transfer(ctx, &rpc.TransferRequest{Owner: owner, HandleId: h.ID[:], NewOwner: "other", RotateId: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Handle.Id).NotTo(Equal(h.ID[:]))
+		Expect(res.Handle.Owner).To(Equal("other"))
+		Expect(res.Handle.PreviousOwner).To(Equal(owner))
+	})
+
+	It("should validate tokens", func() {
+		_, err := subject.ValidateToken(ctx, &rpc.ValidateTokenRequest{})
+		Expect(err).To(MatchError(`rpc error: code = InvalidArgument desc = invalid handle ID`))
+
+		h, err := backend.Acquire(ctx, owner, "ns", "resource", time.Now().Add(time.Minute), nil, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		res, err := subject.ValidateToken(ctx, &rpc.ValidateTokenRequest{HandleId: h.ID[:], FencingToken: uint64(h.FencingToken)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Valid).To(BeTrue())
+
+		res, err = subject.ValidateToken(ctx, &rpc.ValidateTokenRequest{HandleId: h.ID[:], FencingToken: uint64(h.FencingToken) + 1})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(res.Valid).To(BeFalse())
+
+		_, err = subject.Done(ctx, &rpc.DoneRequest{Owner: owner, HandleId: h.ID[:], FencingToken: uint64(h.FencingToken) + 1})
+		Expect(err).To(MatchError(`rpc error: code = FailedPrecondition desc = accord: stale fencing token`))
+	})
+
+	It("should check", func() {
+		_, err := subject.Check(ctx, &rpc.CheckRequest{})
+		Expect(err).NotTo(HaveOccurred())
 	})
 })
 
@@ -121,3 +319,23 @@ func (s *mockListServer) Send(h *rpc.Handle) error {
 	s.sent = append(s.sent, h)
 	return nil
 }
+
+type mockMarkDoneServer struct {
+	rpc.V1_MarkDoneServer
+	reqs []*rpc.MarkDoneRequest
+	res  *rpc.MarkDoneResponse
+}
+
+func (*mockMarkDoneServer) Context() context.Context { return context.Background() }
+func (s *mockMarkDoneServer) Recv() (*rpc.MarkDoneRequest, error) {
+	if len(s.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	return req, nil
+}
+func (s *mockMarkDoneServer) SendAndClose(res *rpc.MarkDoneResponse) error {
+	s.res = res
+	return nil
+}
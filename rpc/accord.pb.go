@@ -69,6 +69,153 @@ func (Status) EnumDescriptor() ([]byte, []int) {
 	return file_rpc_accord_proto_rawDescGZIP(), []int{0}
 }
 
+type Mode int32
+
+const (
+	Mode_EXCLUSIVE Mode = 0 // exclusive (write) ownership
+	Mode_SHARED    Mode = 1 // shared (read) ownership
+)
+
+// Enum value maps for Mode.
+var (
+	Mode_name = map[int32]string{
+		0: "EXCLUSIVE",
+		1: "SHARED",
+	}
+	Mode_value = map[string]int32{
+		"EXCLUSIVE": 0,
+		"SHARED":    1,
+	}
+)
+
+func (x Mode) Enum() *Mode {
+	p := new(Mode)
+	*p = x
+	return p
+}
+
+func (x Mode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Mode) Descriptor() protoreflect.EnumDescriptor {
+	return file_rpc_accord_proto_enumTypes[1].Descriptor()
+}
+
+func (Mode) Type() protoreflect.EnumType {
+	return &file_rpc_accord_proto_enumTypes[1]
+}
+
+func (x Mode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Mode.Descriptor instead.
+func (Mode) EnumDescriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{1}
+}
+
+type ListRequest_SortKey int32
+
+const (
+	ListRequest_CREATED ListRequest_SortKey = 0
+	ListRequest_UPDATED ListRequest_SortKey = 1
+	ListRequest_DONE    ListRequest_SortKey = 2
+	ListRequest_EXPIRES ListRequest_SortKey = 3
+	ListRequest_NAME    ListRequest_SortKey = 4
+)
+
+// Enum value maps for ListRequest_SortKey.
+var (
+	ListRequest_SortKey_name = map[int32]string{
+		0: "CREATED",
+		1: "UPDATED",
+		2: "DONE",
+		3: "EXPIRES",
+		4: "NAME",
+	}
+	ListRequest_SortKey_value = map[string]int32{
+		"CREATED": 0,
+		"UPDATED": 1,
+		"DONE":    2,
+		"EXPIRES": 3,
+		"NAME":    4,
+	}
+)
+
+func (x ListRequest_SortKey) Enum() *ListRequest_SortKey {
+	p := new(ListRequest_SortKey)
+	*p = x
+	return p
+}
+
+func (x ListRequest_SortKey) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ListRequest_SortKey) Descriptor() protoreflect.EnumDescriptor {
+	return file_rpc_accord_proto_enumTypes[2].Descriptor()
+}
+
+func (ListRequest_SortKey) Type() protoreflect.EnumType {
+	return &file_rpc_accord_proto_enumTypes[2]
+}
+
+func (x ListRequest_SortKey) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ListRequest_SortKey.Descriptor instead.
+func (ListRequest_SortKey) EnumDescriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{9, 0}
+}
+
+type ListRequest_SortOrder int32
+
+const (
+	ListRequest_DESC ListRequest_SortOrder = 0
+	ListRequest_ASC  ListRequest_SortOrder = 1
+)
+
+// Enum value maps for ListRequest_SortOrder.
+var (
+	ListRequest_SortOrder_name = map[int32]string{
+		0: "DESC",
+		1: "ASC",
+	}
+	ListRequest_SortOrder_value = map[string]int32{
+		"DESC": 0,
+		"ASC":  1,
+	}
+)
+
+func (x ListRequest_SortOrder) Enum() *ListRequest_SortOrder {
+	p := new(ListRequest_SortOrder)
+	*p = x
+	return p
+}
+
+func (x ListRequest_SortOrder) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ListRequest_SortOrder) Descriptor() protoreflect.EnumDescriptor {
+	return file_rpc_accord_proto_enumTypes[3].Descriptor()
+}
+
+func (ListRequest_SortOrder) Type() protoreflect.EnumType {
+	return &file_rpc_accord_proto_enumTypes[3]
+}
+
+func (x ListRequest_SortOrder) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ListRequest_SortOrder.Descriptor instead.
+func (ListRequest_SortOrder) EnumDescriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{9, 1}
+}
+
 type ListRequest_Filter_Status int32
 
 const (
@@ -102,11 +249,11 @@ func (x ListRequest_Filter_Status) String() string {
 }
 
 func (ListRequest_Filter_Status) Descriptor() protoreflect.EnumDescriptor {
-	return file_rpc_accord_proto_enumTypes[1].Descriptor()
+	return file_rpc_accord_proto_enumTypes[4].Descriptor()
 }
 
 func (ListRequest_Filter_Status) Type() protoreflect.EnumType {
-	return &file_rpc_accord_proto_enumTypes[1]
+	return &file_rpc_accord_proto_enumTypes[4]
 }
 
 func (x ListRequest_Filter_Status) Number() protoreflect.EnumNumber {
@@ -115,7 +262,7 @@ func (x ListRequest_Filter_Status) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use ListRequest_Filter_Status.Descriptor instead.
 func (ListRequest_Filter_Status) EnumDescriptor() ([]byte, []int) {
-	return file_rpc_accord_proto_rawDescGZIP(), []int{7, 0, 0}
+	return file_rpc_accord_proto_rawDescGZIP(), []int{9, 0, 0}
 }
 
 // Handle
@@ -138,6 +285,30 @@ type Handle struct {
 	NumAcquired uint32 `protobuf:"varint,6,opt,name=num_acquired,json=numAcquired,proto3" json:"num_acquired,omitempty"`
 	// Metadata.
 	Metadata map[string]string `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Opaque list cursor, only set on listed handles.
+	Cursor string `protobuf:"bytes,9,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Current/last owner.
+	Owner string `protobuf:"bytes,10,opt,name=owner,proto3" json:"owner,omitempty"`
+	// Owner before the handle was last taken over, empty if acquired once.
+	PreviousOwner string `protobuf:"bytes,13,opt,name=previous_owner,json=previousOwner,proto3" json:"previous_owner,omitempty"`
+	// Creation UNIX timestamp (millisecond precision)
+	CreatedTms int64 `protobuf:"varint,11,opt,name=created_tms,json=createdTms,proto3" json:"created_tms,omitempty"`
+	// Last update UNIX timestamp (millisecond precision)
+	UpdatedTms int64 `protobuf:"varint,12,opt,name=updated_tms,json=updatedTms,proto3" json:"updated_tms,omitempty"`
+	// Metadata version, starts at 1 and is incremented whenever the metadata
+	// changes.
+	MetadataVersion uint64 `protobuf:"varint,14,opt,name=metadata_version,json=metadataVersion,proto3" json:"metadata_version,omitempty"`
+	// Fencing token, strictly increasing with every acquisition of the
+	// resource.
+	FencingToken uint64 `protobuf:"varint,15,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+	// Semaphore slot occupied by the handle, zero for exclusive handles.
+	Slot uint32 `protobuf:"varint,16,opt,name=slot,proto3" json:"slot,omitempty"`
+	// Maximum number of concurrent holders of the resource.
+	Capacity uint32 `protobuf:"varint,17,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// Acquire mode.
+	Mode Mode `protobuf:"varint,18,opt,name=mode,proto3,enum=blacksquaremedia.accord.Mode" json:"mode,omitempty"`
+	// Exclusive handle queued behind shared holders.
+	Queued bool `protobuf:"varint,19,opt,name=queued,proto3" json:"queued,omitempty"`
 }
 
 func (x *Handle) Reset() {
@@ -221,6 +392,83 @@ func (x *Handle) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *Handle) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *Handle) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *Handle) GetPreviousOwner() string {
+	if x != nil {
+		return x.PreviousOwner
+	}
+	return ""
+}
+
+func (x *Handle) GetCreatedTms() int64 {
+	if x != nil {
+		return x.CreatedTms
+	}
+	return 0
+}
+
+func (x *Handle) GetUpdatedTms() int64 {
+	if x != nil {
+		return x.UpdatedTms
+	}
+	return 0
+}
+
+func (x *Handle) GetMetadataVersion() uint64 {
+	if x != nil {
+		return x.MetadataVersion
+	}
+	return 0
+}
+
+func (x *Handle) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
+func (x *Handle) GetSlot() uint32 {
+	if x != nil {
+		return x.Slot
+	}
+	return 0
+}
+
+func (x *Handle) GetCapacity() uint32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *Handle) GetMode() Mode {
+	if x != nil {
+		return x.Mode
+	}
+	return Mode_EXCLUSIVE
+}
+
+func (x *Handle) GetQueued() bool {
+	if x != nil {
+		return x.Queued
+	}
+	return false
+}
+
 type AcquireRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -236,6 +484,13 @@ type AcquireRequest struct {
 	Ttl uint32 `protobuf:"varint,4,opt,name=ttl,proto3" json:"ttl,omitempty"`
 	// Custom, optional metadata.
 	Metadata map[string]string `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Maximum number of concurrent holders, turning the resource into a
+	// counting semaphore. Defaults to 1, i.e. exclusive ownership.
+	Capacity uint32 `protobuf:"varint,6,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	// Acquire mode. Shared handles may be held by many owners at the same
+	// time, exclusive handles are queued while shared handles are held and
+	// block new shared handles until they expire.
+	Mode Mode `protobuf:"varint,7,opt,name=mode,proto3,enum=blacksquaremedia.accord.Mode" json:"mode,omitempty"`
 }
 
 func (x *AcquireRequest) Reset() {
@@ -305,6 +560,20 @@ func (x *AcquireRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *AcquireRequest) GetCapacity() uint32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *AcquireRequest) GetMode() Mode {
+	if x != nil {
+		return x.Mode
+	}
+	return Mode_EXCLUSIVE
+}
+
 type AcquireResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -373,6 +642,16 @@ type RenewRequest struct {
 	Ttl uint32 `protobuf:"varint,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
 	// Optional metadata.
 	Metadata map[string]string `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Optional expected metadata version. If set, the request fails with an
+	// ABORTED status unless the current metadata version matches.
+	ExpectedVersion uint64 `protobuf:"varint,5,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	// Optional metadata keys to delete, before metadata is merged.
+	DeleteMetadata []string `protobuf:"bytes,6,rep,name=delete_metadata,json=deleteMetadata,proto3" json:"delete_metadata,omitempty"`
+	// If set, replace stored metadata with metadata instead of merging.
+	ReplaceMetadata bool `protobuf:"varint,7,opt,name=replace_metadata,json=replaceMetadata,proto3" json:"replace_metadata,omitempty"`
+	// Optional fencing token. If set, the request fails with a
+	// FAILED_PRECONDITION status unless the token is current.
+	FencingToken uint64 `protobuf:"varint,8,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
 }
 
 func (x *RenewRequest) Reset() {
@@ -435,6 +714,34 @@ func (x *RenewRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *RenewRequest) GetExpectedVersion() uint64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+func (x *RenewRequest) GetDeleteMetadata() []string {
+	if x != nil {
+		return x.DeleteMetadata
+	}
+	return nil
+}
+
+func (x *RenewRequest) GetReplaceMetadata() bool {
+	if x != nil {
+		return x.ReplaceMetadata
+	}
+	return false
+}
+
+func (x *RenewRequest) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
 type RenewResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -484,6 +791,16 @@ type DoneRequest struct {
 	HandleId []byte `protobuf:"bytes,2,opt,name=handle_id,json=handleId,proto3" json:"handle_id,omitempty"`
 	// Optional metadata.
 	Metadata map[string]string `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Optional expected metadata version. If set, the request fails with an
+	// ABORTED status unless the current metadata version matches.
+	ExpectedVersion uint64 `protobuf:"varint,4,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	// Optional metadata keys to delete, before metadata is merged.
+	DeleteMetadata []string `protobuf:"bytes,5,rep,name=delete_metadata,json=deleteMetadata,proto3" json:"delete_metadata,omitempty"`
+	// If set, replace stored metadata with metadata instead of merging.
+	ReplaceMetadata bool `protobuf:"varint,6,opt,name=replace_metadata,json=replaceMetadata,proto3" json:"replace_metadata,omitempty"`
+	// Optional fencing token. If set, the request fails with a
+	// FAILED_PRECONDITION status unless the token is current.
+	FencingToken uint64 `protobuf:"varint,7,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
 }
 
 func (x *DoneRequest) Reset() {
@@ -539,6 +856,34 @@ func (x *DoneRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *DoneRequest) GetExpectedVersion() uint64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+func (x *DoneRequest) GetDeleteMetadata() []string {
+	if x != nil {
+		return x.DeleteMetadata
+	}
+	return nil
+}
+
+func (x *DoneRequest) GetReplaceMetadata() bool {
+	if x != nil {
+		return x.ReplaceMetadata
+	}
+	return false
+}
+
+func (x *DoneRequest) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
 type DoneResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -577,19 +922,23 @@ func (*DoneResponse) Descriptor() ([]byte, []int) {
 	return file_rpc_accord_proto_rawDescGZIP(), []int{6}
 }
 
-type ListRequest struct {
+type TransferRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Filter object.
-	Filter *ListRequest_Filter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
-	// Skip the first N records.
-	Offset uint64 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Current owner identifier.
+	Owner string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	// Handle identifier.
+	HandleId []byte `protobuf:"bytes,2,opt,name=handle_id,json=handleId,proto3" json:"handle_id,omitempty"`
+	// New owner identifier.
+	NewOwner string `protobuf:"bytes,3,opt,name=new_owner,json=newOwner,proto3" json:"new_owner,omitempty"`
+	// If set, assign a new handle ID.
+	RotateId bool `protobuf:"varint,4,opt,name=rotate_id,json=rotateId,proto3" json:"rotate_id,omitempty"`
 }
 
-func (x *ListRequest) Reset() {
-	*x = ListRequest{}
+func (x *TransferRequest) Reset() {
+	*x = TransferRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_rpc_accord_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -597,13 +946,13 @@ func (x *ListRequest) Reset() {
 	}
 }
 
-func (x *ListRequest) String() string {
+func (x *TransferRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListRequest) ProtoMessage() {}
+func (*TransferRequest) ProtoMessage() {}
 
-func (x *ListRequest) ProtoReflect() protoreflect.Message {
+func (x *TransferRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_rpc_accord_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -615,55 +964,65 @@ func (x *ListRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
-func (*ListRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use TransferRequest.ProtoReflect.Descriptor instead.
+func (*TransferRequest) Descriptor() ([]byte, []int) {
 	return file_rpc_accord_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ListRequest) GetFilter() *ListRequest_Filter {
+func (x *TransferRequest) GetOwner() string {
 	if x != nil {
-		return x.Filter
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *TransferRequest) GetHandleId() []byte {
+	if x != nil {
+		return x.HandleId
 	}
 	return nil
 }
 
-func (x *ListRequest) GetOffset() uint64 {
+func (x *TransferRequest) GetNewOwner() string {
 	if x != nil {
-		return x.Offset
+		return x.NewOwner
 	}
-	return 0
+	return ""
 }
 
-type ListRequest_Filter struct {
+func (x *TransferRequest) GetRotateId() bool {
+	if x != nil {
+		return x.RotateId
+	}
+	return false
+}
+
+type TransferResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// Namespace prefix.
-	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
-	// Only certain status.
-	Status ListRequest_Filter_Status `protobuf:"varint,2,opt,name=status,proto3,enum=blacksquaremedia.accord.ListRequest_Filter_Status" json:"status,omitempty"`
-	// Filter by metadata.
-	Metadata map[string]string `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The transferred handle.
+	Handle *Handle `protobuf:"bytes,1,opt,name=handle,proto3" json:"handle,omitempty"`
 }
 
-func (x *ListRequest_Filter) Reset() {
-	*x = ListRequest_Filter{}
+func (x *TransferResponse) Reset() {
+	*x = TransferResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_rpc_accord_proto_msgTypes[12]
+		mi := &file_rpc_accord_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ListRequest_Filter) String() string {
+func (x *TransferResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListRequest_Filter) ProtoMessage() {}
+func (*TransferResponse) ProtoMessage() {}
 
-func (x *ListRequest_Filter) ProtoReflect() protoreflect.Message {
-	mi := &file_rpc_accord_proto_msgTypes[12]
+func (x *TransferResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -674,38 +1033,849 @@ func (x *ListRequest_Filter) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListRequest_Filter.ProtoReflect.Descriptor instead.
-func (*ListRequest_Filter) Descriptor() ([]byte, []int) {
-	return file_rpc_accord_proto_rawDescGZIP(), []int{7, 0}
+// Deprecated: Use TransferResponse.ProtoReflect.Descriptor instead.
+func (*TransferResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *ListRequest_Filter) GetPrefix() string {
+func (x *TransferResponse) GetHandle() *Handle {
 	if x != nil {
-		return x.Prefix
+		return x.Handle
 	}
-	return ""
+	return nil
 }
 
-func (x *ListRequest_Filter) GetStatus() ListRequest_Filter_Status {
-	if x != nil {
-		return x.Status
-	}
-	return ListRequest_Filter_ALL
-}
+type ListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-func (x *ListRequest_Filter) GetMetadata() map[string]string {
-	if x != nil {
-		return x.Metadata
-	}
-	return nil
+	// Filter object.
+	Filter *ListRequest_Filter `protobuf:"bytes,1,opt,name=filter,proto3" json:"filter,omitempty"`
+	// Skip the first N records.
+	Offset uint64 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Opaque cursor, continue listing after the handle with this cursor.
+	Cursor string `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	// Maximum number of handles to return, 0 for no limit.
+	Limit uint32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Sort key, handles without a done time are sorted last when sorting by
+	// DONE. Cursors can only be used with the default sort.
+	Sort ListRequest_SortKey `protobuf:"varint,5,opt,name=sort,proto3,enum=blacksquaremedia.accord.ListRequest_SortKey" json:"sort,omitempty"`
+	// Sort order.
+	Order ListRequest_SortOrder `protobuf:"varint,6,opt,name=order,proto3,enum=blacksquaremedia.accord.ListRequest_SortOrder" json:"order,omitempty"`
 }
 
-var File_rpc_accord_proto protoreflect.FileDescriptor
-
-var file_rpc_accord_proto_rawDesc = []byte{
+func (x *ListRequest) Reset() {
+	*x = ListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_accord_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest) ProtoMessage() {}
+
+func (x *ListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest.ProtoReflect.Descriptor instead.
+func (*ListRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListRequest) GetFilter() *ListRequest_Filter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *ListRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ListRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListRequest) GetSort() ListRequest_SortKey {
+	if x != nil {
+		return x.Sort
+	}
+	return ListRequest_CREATED
+}
+
+func (x *ListRequest) GetOrder() ListRequest_SortOrder {
+	if x != nil {
+		return x.Order
+	}
+	return ListRequest_DESC
+}
+
+type PurgeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Namespace prefix.
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// Namespace prefixes to exclude.
+	Exclude []string `protobuf:"bytes,2,rep,name=exclude,proto3" json:"exclude,omitempty"`
+	// Cutoff UNIX timestamp (millisecond precision), handles marked as done
+	// before this time are purged.
+	DoneBeforeTms int64 `protobuf:"varint,3,opt,name=done_before_tms,json=doneBeforeTms,proto3" json:"done_before_tms,omitempty"`
+}
+
+func (x *PurgeRequest) Reset() {
+	*x = PurgeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_accord_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PurgeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeRequest) ProtoMessage() {}
+
+func (x *PurgeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeRequest.ProtoReflect.Descriptor instead.
+func (*PurgeRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PurgeRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *PurgeRequest) GetExclude() []string {
+	if x != nil {
+		return x.Exclude
+	}
+	return nil
+}
+
+func (x *PurgeRequest) GetDoneBeforeTms() int64 {
+	if x != nil {
+		return x.DoneBeforeTms
+	}
+	return 0
+}
+
+type ListPageResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Handles on this page.
+	Handles []*Handle `protobuf:"bytes,1,rep,name=handles,proto3" json:"handles,omitempty"`
+	// Token for the next page, empty on the last page.
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	// Estimated total number of handles matching the filter.
+	TotalEstimate uint64 `protobuf:"varint,3,opt,name=total_estimate,json=totalEstimate,proto3" json:"total_estimate,omitempty"`
+}
+
+func (x *ListPageResponse) Reset() {
+	*x = ListPageResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_accord_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListPageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPageResponse) ProtoMessage() {}
+
+func (x *ListPageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPageResponse.ProtoReflect.Descriptor instead.
+func (*ListPageResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListPageResponse) GetHandles() []*Handle {
+	if x != nil {
+		return x.Handles
+	}
+	return nil
+}
+
+func (x *ListPageResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *ListPageResponse) GetTotalEstimate() uint64 {
+	if x != nil {
+		return x.TotalEstimate
+	}
+	return 0
+}
+
+type MarkDoneRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Owner identifier, recorded as the handle owner.
+	Owner string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	// Resource name/identifier, unique within namespace.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// Custom namespace.
+	Namespace string `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Done at UNIX timestamp (millisecond precision), defaults to now.
+	DoneTms int64 `protobuf:"varint,4,opt,name=done_tms,json=doneTms,proto3" json:"done_tms,omitempty"`
+	// Metadata.
+	Metadata map[string]string `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *MarkDoneRequest) Reset() {
+	*x = MarkDoneRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_accord_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MarkDoneRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkDoneRequest) ProtoMessage() {}
+
+func (x *MarkDoneRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkDoneRequest.ProtoReflect.Descriptor instead.
+func (*MarkDoneRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *MarkDoneRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *MarkDoneRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MarkDoneRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *MarkDoneRequest) GetDoneTms() int64 {
+	if x != nil {
+		return x.DoneTms
+	}
+	return 0
+}
+
+func (x *MarkDoneRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+type MarkDoneResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of resources marked as done.
+	NumMarked uint64 `protobuf:"varint,1,opt,name=num_marked,json=numMarked,proto3" json:"num_marked,omitempty"`
+	// Resources which already existed.
+	Conflicts []*MarkDoneResponse_Conflict `protobuf:"bytes,2,rep,name=conflicts,proto3" json:"conflicts,omitempty"`
+}
+
+func (x *MarkDoneResponse) Reset() {
+	*x = MarkDoneResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_accord_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MarkDoneResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkDoneResponse) ProtoMessage() {}
+
+func (x *MarkDoneResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkDoneResponse.ProtoReflect.Descriptor instead.
+func (*MarkDoneResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MarkDoneResponse) GetNumMarked() uint64 {
+	if x != nil {
+		return x.NumMarked
+	}
+	return 0
+}
+
+func (x *MarkDoneResponse) GetConflicts() []*MarkDoneResponse_Conflict {
+	if x != nil {
+		return x.Conflicts
+	}
+	return nil
+}
+
+type PurgeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Number of purged handles.
+	NumPurged uint64 `protobuf:"varint,1,opt,name=num_purged,json=numPurged,proto3" json:"num_purged,omitempty"`
+}
+
+func (x *PurgeResponse) Reset() {
+	*x = PurgeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_accord_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PurgeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeResponse) ProtoMessage() {}
+
+func (x *PurgeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeResponse.ProtoReflect.Descriptor instead.
+func (*PurgeResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PurgeResponse) GetNumPurged() uint64 {
+	if x != nil {
+		return x.NumPurged
+	}
+	return 0
+}
+
+type ValidateTokenRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Handle identifier.
+	HandleId []byte `protobuf:"bytes,1,opt,name=handle_id,json=handleId,proto3" json:"handle_id,omitempty"`
+	// Fencing token.
+	FencingToken uint64 `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+}
+
+func (x *ValidateTokenRequest) Reset() {
+	*x = ValidateTokenRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_accord_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateTokenRequest) ProtoMessage() {}
+
+func (x *ValidateTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateTokenRequest.ProtoReflect.Descriptor instead.
+func (*ValidateTokenRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ValidateTokenRequest) GetHandleId() []byte {
+	if x != nil {
+		return x.HandleId
+	}
+	return nil
+}
+
+func (x *ValidateTokenRequest) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
+type ValidateTokenResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// True if the token is current.
+	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+}
+
+func (x *ValidateTokenResponse) Reset() {
+	*x = ValidateTokenResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_accord_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ValidateTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateTokenResponse) ProtoMessage() {}
+
+func (x *ValidateTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateTokenResponse.ProtoReflect.Descriptor instead.
+func (*ValidateTokenResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ValidateTokenResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+type CheckRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CheckRequest) Reset() {
+	*x = CheckRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_accord_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckRequest) ProtoMessage() {}
+
+func (x *CheckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckRequest.ProtoReflect.Descriptor instead.
+func (*CheckRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{17}
+}
+
+type CheckResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CheckResponse) Reset() {
+	*x = CheckResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_accord_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CheckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckResponse) ProtoMessage() {}
+
+func (x *CheckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckResponse.ProtoReflect.Descriptor instead.
+func (*CheckResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{18}
+}
+
+type ListRequest_Filter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Namespace prefix.
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// Only certain status.
+	Status ListRequest_Filter_Status `protobuf:"varint,2,opt,name=status,proto3,enum=blacksquaremedia.accord.ListRequest_Filter_Status" json:"status,omitempty"`
+	// Filter by metadata.
+	Metadata map[string]string `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Filter by metadata selector expression, similar to Kubernetes label
+	// selectors, e.g. "env in (prod,staging),!legacy,team,path^=tmp/".
+	MetadataSelector string `protobuf:"bytes,4,opt,name=metadata_selector,json=metadataSelector,proto3" json:"metadata_selector,omitempty"`
+	// Time-range filters, as unix timestamps in milliseconds, 0 to ignore.
+	// Both bounds are exclusive.
+	CreatedAfterTms  int64 `protobuf:"varint,5,opt,name=created_after_tms,json=createdAfterTms,proto3" json:"created_after_tms,omitempty"`
+	CreatedBeforeTms int64 `protobuf:"varint,6,opt,name=created_before_tms,json=createdBeforeTms,proto3" json:"created_before_tms,omitempty"`
+	UpdatedAfterTms  int64 `protobuf:"varint,7,opt,name=updated_after_tms,json=updatedAfterTms,proto3" json:"updated_after_tms,omitempty"`
+	UpdatedBeforeTms int64 `protobuf:"varint,8,opt,name=updated_before_tms,json=updatedBeforeTms,proto3" json:"updated_before_tms,omitempty"`
+	DoneAfterTms     int64 `protobuf:"varint,9,opt,name=done_after_tms,json=doneAfterTms,proto3" json:"done_after_tms,omitempty"`
+	DoneBeforeTms    int64 `protobuf:"varint,10,opt,name=done_before_tms,json=doneBeforeTms,proto3" json:"done_before_tms,omitempty"`
+	// Exact namespace.
+	Namespace string `protobuf:"bytes,11,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Name prefix.
+	NamePrefix string `protobuf:"bytes,12,opt,name=name_prefix,json=namePrefix,proto3" json:"name_prefix,omitempty"`
+	// Exact owner.
+	Owner string `protobuf:"bytes,13,opt,name=owner,proto3" json:"owner,omitempty"`
+}
+
+func (x *ListRequest_Filter) Reset() {
+	*x = ListRequest_Filter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_accord_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListRequest_Filter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRequest_Filter) ProtoMessage() {}
+
+func (x *ListRequest_Filter) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRequest_Filter.ProtoReflect.Descriptor instead.
+func (*ListRequest_Filter) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{9, 0}
+}
+
+func (x *ListRequest_Filter) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ListRequest_Filter) GetStatus() ListRequest_Filter_Status {
+	if x != nil {
+		return x.Status
+	}
+	return ListRequest_Filter_ALL
+}
+
+func (x *ListRequest_Filter) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *ListRequest_Filter) GetMetadataSelector() string {
+	if x != nil {
+		return x.MetadataSelector
+	}
+	return ""
+}
+
+func (x *ListRequest_Filter) GetCreatedAfterTms() int64 {
+	if x != nil {
+		return x.CreatedAfterTms
+	}
+	return 0
+}
+
+func (x *ListRequest_Filter) GetCreatedBeforeTms() int64 {
+	if x != nil {
+		return x.CreatedBeforeTms
+	}
+	return 0
+}
+
+func (x *ListRequest_Filter) GetUpdatedAfterTms() int64 {
+	if x != nil {
+		return x.UpdatedAfterTms
+	}
+	return 0
+}
+
+func (x *ListRequest_Filter) GetUpdatedBeforeTms() int64 {
+	if x != nil {
+		return x.UpdatedBeforeTms
+	}
+	return 0
+}
+
+func (x *ListRequest_Filter) GetDoneAfterTms() int64 {
+	if x != nil {
+		return x.DoneAfterTms
+	}
+	return 0
+}
+
+func (x *ListRequest_Filter) GetDoneBeforeTms() int64 {
+	if x != nil {
+		return x.DoneBeforeTms
+	}
+	return 0
+}
+
+func (x *ListRequest_Filter) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ListRequest_Filter) GetNamePrefix() string {
+	if x != nil {
+		return x.NamePrefix
+	}
+	return ""
+}
+
+func (x *ListRequest_Filter) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+type MarkDoneResponse_Conflict struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Position of the request within the stream, starting at 0.
+	Index uint64 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Resource namespace.
+	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	// Resource name.
+	Name string `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// Status of the existing resource, either HELD or DONE.
+	Status Status `protobuf:"varint,4,opt,name=status,proto3,enum=blacksquaremedia.accord.Status" json:"status,omitempty"`
+}
+
+func (x *MarkDoneResponse_Conflict) Reset() {
+	*x = MarkDoneResponse_Conflict{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_accord_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MarkDoneResponse_Conflict) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkDoneResponse_Conflict) ProtoMessage() {}
+
+func (x *MarkDoneResponse_Conflict) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_accord_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkDoneResponse_Conflict.ProtoReflect.Descriptor instead.
+func (*MarkDoneResponse_Conflict) Descriptor() ([]byte, []int) {
+	return file_rpc_accord_proto_rawDescGZIP(), []int{13, 0}
+}
+
+func (x *MarkDoneResponse_Conflict) GetIndex() uint64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *MarkDoneResponse_Conflict) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *MarkDoneResponse_Conflict) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MarkDoneResponse_Conflict) GetStatus() Status {
+	if x != nil {
+		return x.Status
+	}
+	return Status_OK
+}
+
+var File_rpc_accord_proto protoreflect.FileDescriptor
+
+var file_rpc_accord_proto_rawDesc = []byte{
 	0x0a, 0x10, 0x72, 0x70, 0x63, 0x2f, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x70, 0x72, 0x6f,
 	0x74, 0x6f, 0x12, 0x17, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d,
-	0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x22, 0xa9, 0x02, 0x0a, 0x06,
+	0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x22, 0x8b, 0x05, 0x0a, 0x06,
 	0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
 	0x28, 0x0c, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
 	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61,
@@ -720,117 +1890,321 @@ var file_rpc_accord_proto_rawDesc = []byte{
 	0x0b, 0x32, 0x2d, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d,
 	0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x48, 0x61, 0x6e, 0x64,
 	0x6c, 0x65, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79,
-	0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65,
-	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
-	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
-	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xfa, 0x01, 0x0a, 0x0e, 0x41, 0x63, 0x71, 0x75,
-	0x69, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77,
-	0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72,
-	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63,
-	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61,
-	0x63, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52,
-	0x03, 0x74, 0x74, 0x6c, 0x12, 0x51, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
-	0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x35, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71,
-	0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64,
-	0x2e, 0x41, 0x63, 0x71, 0x75, 0x69, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
-	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d,
-	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64,
-	0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x3a, 0x02, 0x38, 0x01, 0x22, 0x83, 0x01, 0x0a, 0x0f, 0x41, 0x63, 0x71, 0x75, 0x69, 0x72, 0x65,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
-	0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b,
+	0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75,
+	0x72, 0x73, 0x6f, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73,
+	0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x0e, 0x70, 0x72, 0x65, 0x76,
+	0x69, 0x6f, 0x75, 0x73, 0x5f, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0d, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x12,
+	0x1f, 0x0a, 0x0b, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x6d, 0x73, 0x18, 0x0b,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x54, 0x6d, 0x73,
+	0x12, 0x1f, 0x0a, 0x0b, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x6d, 0x73, 0x18,
+	0x0c, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x54, 0x6d,
+	0x73, 0x12, 0x29, 0x0a, 0x10, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x6d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d,
+	0x66, 0x65, 0x6e, 0x63, 0x69, 0x6e, 0x67, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0c, 0x66, 0x65, 0x6e, 0x63, 0x69, 0x6e, 0x67, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x6f, 0x74, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x04, 0x73, 0x6c, 0x6f, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74,
+	0x79, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x63, 0x61, 0x70, 0x61, 0x63, 0x69, 0x74,
+	0x79, 0x12, 0x31, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x1d, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64,
+	0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04,
+	0x6d, 0x6f, 0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x71, 0x75, 0x65, 0x75, 0x65, 0x64, 0x18, 0x13,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x71, 0x75, 0x65, 0x75, 0x65, 0x64, 0x1a, 0x3b, 0x0a, 0x0d,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xc9, 0x02, 0x0a, 0x0e, 0x41, 0x63,
+	0x71, 0x75, 0x69, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05,
+	0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e,
+	0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x12, 0x51, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x35, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b,
 	0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f,
-	0x72, 0x64, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
-	0x73, 0x12, 0x37, 0x0a, 0x06, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x1f, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d,
-	0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x48, 0x61, 0x6e, 0x64,
-	0x6c, 0x65, 0x52, 0x06, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x22, 0xe1, 0x01, 0x0a, 0x0c, 0x52,
-	0x65, 0x6e, 0x65, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6f,
+	0x72, 0x64, 0x2e, 0x41, 0x63, 0x71, 0x75, 0x69, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x61, 0x70,
+	0x61, 0x63, 0x69, 0x74, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x63, 0x61, 0x70,
+	0x61, 0x63, 0x69, 0x74, 0x79, 0x12, 0x31, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72,
+	0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4d, 0x6f,
+	0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61,
+	0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x83, 0x01, 0x0a, 0x0f, 0x41, 0x63, 0x71, 0x75, 0x69, 0x72,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x73, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x62, 0x6c, 0x61, 0x63,
+	0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63,
+	0x6f, 0x72, 0x64, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x37, 0x0a, 0x06, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65,
+	0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x48, 0x61, 0x6e,
+	0x64, 0x6c, 0x65, 0x52, 0x06, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x22, 0x85, 0x03, 0x0a, 0x0c,
+	0x52, 0x65, 0x6e, 0x65, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05,
+	0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e,
+	0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x49, 0x64, 0x12,
+	0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x74, 0x74,
+	0x6c, 0x12, 0x4f, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x33, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72,
+	0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x52, 0x65,
+	0x6e, 0x65, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x12, 0x29, 0x0a, 0x10, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x76,
+	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x65, 0x78,
+	0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x27, 0x0a,
+	0x0f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x06, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x4d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x29, 0x0a, 0x10, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63,
+	0x65, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0f, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x12, 0x23, 0x0a, 0x0d, 0x66, 0x65, 0x6e, 0x63, 0x69, 0x6e, 0x67, 0x5f, 0x74, 0x6f, 0x6b,
+	0x65, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x66, 0x65, 0x6e, 0x63, 0x69, 0x6e,
+	0x67, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
+	0x02, 0x38, 0x01, 0x22, 0x0f, 0x0a, 0x0d, 0x52, 0x65, 0x6e, 0x65, 0x77, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0xf1, 0x02, 0x0a, 0x0b, 0x44, 0x6f, 0x6e, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x61,
+	0x6e, 0x64, 0x6c, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x68,
+	0x61, 0x6e, 0x64, 0x6c, 0x65, 0x49, 0x64, 0x12, 0x4e, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x62, 0x6c, 0x61, 0x63,
+	0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63,
+	0x6f, 0x72, 0x64, 0x2e, 0x44, 0x6f, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x29, 0x0a, 0x10, 0x65, 0x78, 0x70, 0x65, 0x63,
+	0x74, 0x65, 0x64, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0f, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x56, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x27, 0x0a, 0x0f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x5f, 0x6d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x64, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x29, 0x0a, 0x10, 0x72,
+	0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63, 0x65, 0x4d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x23, 0x0a, 0x0d, 0x66, 0x65, 0x6e, 0x63, 0x69, 0x6e,
+	0x67, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x66,
+	0x65, 0x6e, 0x63, 0x69, 0x6e, 0x67, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x0e, 0x0a, 0x0c, 0x44, 0x6f, 0x6e, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x7e, 0x0a, 0x0f, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6f,
 	0x77, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e, 0x65,
 	0x72, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x49, 0x64, 0x12, 0x10,
-	0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x03, 0x74, 0x74, 0x6c,
-	0x12, 0x4f, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x04, 0x20, 0x03,
-	0x28, 0x0b, 0x32, 0x33, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65,
-	0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x52, 0x65, 0x6e,
-	0x65, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
-	0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
-	0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x0f,
-	0x0a, 0x0d, 0x52, 0x65, 0x6e, 0x65, 0x77, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
-	0xcd, 0x01, 0x0a, 0x0b, 0x44, 0x6f, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x14, 0x0a, 0x05, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
-	0x6f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x5f,
-	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65,
-	0x49, 0x64, 0x12, 0x4e, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61,
-	0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x44,
-	0x6f, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64,
-	0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
-	0x74, 0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e,
-	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
-	0x0e, 0x0a, 0x0c, 0x44, 0x6f, 0x6e, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
-	0x97, 0x03, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
-	0x43, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
-	0x2b, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64,
-	0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x06, 0x66, 0x69,
-	0x6c, 0x74, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x1a, 0xaa, 0x02, 0x0a,
-	0x06, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69,
-	0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12,
-	0x4a, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
-	0x32, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64,
-	0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x55, 0x0a, 0x08, 0x6d,
-	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x39, 0x2e,
-	0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61,
-	0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64,
-	0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
-	0x74, 0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x49, 0x64, 0x12, 0x1b,
+	0x0a, 0x09, 0x6e, 0x65, 0x77, 0x5f, 0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x6e, 0x65, 0x77, 0x4f, 0x77, 0x6e, 0x65, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x72,
+	0x6f, 0x74, 0x61, 0x74, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08,
+	0x72, 0x6f, 0x74, 0x61, 0x74, 0x65, 0x49, 0x64, 0x22, 0x4b, 0x0a, 0x10, 0x54, 0x72, 0x61, 0x6e,
+	0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x06,
+	0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x62,
+	0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e,
+	0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x52, 0x06, 0x68,
+	0x61, 0x6e, 0x64, 0x6c, 0x65, 0x22, 0xb7, 0x08, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x43, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75,
+	0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e,
+	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x46, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66,
+	0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73,
+	0x65, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x12, 0x40, 0x0a, 0x04, 0x73, 0x6f, 0x72, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2c,
+	0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69,
+	0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x4b, 0x65, 0x79, 0x52, 0x04, 0x73, 0x6f,
+	0x72, 0x74, 0x12, 0x44, 0x0a, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x2e, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d,
+	0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x53, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65,
+	0x72, 0x52, 0x05, 0x6f, 0x72, 0x64, 0x65, 0x72, 0x1a, 0xae, 0x05, 0x0a, 0x06, 0x46, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x4a, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x32, 0x2e, 0x62, 0x6c,
+	0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61,
+	0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x2e, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x55, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x39, 0x2e, 0x62, 0x6c, 0x61, 0x63,
+	0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63,
+	0x6f, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
+	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x2b,
+	0x0a, 0x11, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x73, 0x65, 0x6c, 0x65, 0x63,
+	0x74, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x12, 0x2a, 0x0a, 0x11, 0x63,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x5f, 0x74, 0x6d, 0x73,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41,
+	0x66, 0x74, 0x65, 0x72, 0x54, 0x6d, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x63, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x64, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x5f, 0x74, 0x6d, 0x73, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x10, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x42, 0x65, 0x66, 0x6f,
+	0x72, 0x65, 0x54, 0x6d, 0x73, 0x12, 0x2a, 0x0a, 0x11, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64,
+	0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x5f, 0x74, 0x6d, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0f, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x41, 0x66, 0x74, 0x65, 0x72, 0x54, 0x6d,
+	0x73, 0x12, 0x2c, 0x0a, 0x12, 0x75, 0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x62, 0x65, 0x66,
+	0x6f, 0x72, 0x65, 0x5f, 0x74, 0x6d, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10, 0x75,
+	0x70, 0x64, 0x61, 0x74, 0x65, 0x64, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x54, 0x6d, 0x73, 0x12,
+	0x24, 0x0a, 0x0e, 0x64, 0x6f, 0x6e, 0x65, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x5f, 0x74, 0x6d,
+	0x73, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x64, 0x6f, 0x6e, 0x65, 0x41, 0x66, 0x74,
+	0x65, 0x72, 0x54, 0x6d, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x64, 0x6f, 0x6e, 0x65, 0x5f, 0x62, 0x65,
+	0x66, 0x6f, 0x72, 0x65, 0x5f, 0x74, 0x6d, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d,
+	0x64, 0x6f, 0x6e, 0x65, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x54, 0x6d, 0x73, 0x12, 0x1c, 0x0a,
+	0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x6e,
+	0x61, 0x6d, 0x65, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x6e, 0x61, 0x6d, 0x65, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x14, 0x0a, 0x05,
+	0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e,
+	0x65, 0x72, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e,
 	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
 	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
 	0x28, 0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x4c, 0x4c,
 	0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x44, 0x4f, 0x4e, 0x45, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07,
-	0x50, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x2a, 0x24, 0x0a, 0x06, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x12, 0x06, 0x0a, 0x02, 0x4f, 0x4b, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x44,
-	0x4f, 0x4e, 0x45, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x45, 0x4c, 0x44, 0x10, 0x02, 0x32,
-	0xe0, 0x02, 0x0a, 0x02, 0x56, 0x31, 0x12, 0x5c, 0x0a, 0x07, 0x41, 0x63, 0x71, 0x75, 0x69, 0x72,
-	0x65, 0x12, 0x27, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d,
-	0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x41, 0x63, 0x71, 0x75,
-	0x69, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x62, 0x6c, 0x61,
-	0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63,
-	0x63, 0x6f, 0x72, 0x64, 0x2e, 0x41, 0x63, 0x71, 0x75, 0x69, 0x72, 0x65, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x56, 0x0a, 0x05, 0x52, 0x65, 0x6e, 0x65, 0x77, 0x12, 0x25, 0x2e,
-	0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61,
-	0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x52, 0x65, 0x6e, 0x65, 0x77, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61,
-	0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x52,
-	0x65, 0x6e, 0x65, 0x77, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x53, 0x0a, 0x04,
-	0x44, 0x6f, 0x6e, 0x65, 0x12, 0x24, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61,
-	0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x44,
-	0x6f, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x62, 0x6c, 0x61,
-	0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63,
-	0x63, 0x6f, 0x72, 0x64, 0x2e, 0x44, 0x6f, 0x6e, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x4f, 0x0a, 0x04, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x24, 0x2e, 0x62, 0x6c, 0x61, 0x63,
-	0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63,
-	0x6f, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x50, 0x45, 0x4e, 0x44, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x22, 0x44, 0x0a, 0x07, 0x53, 0x6f, 0x72,
+	0x74, 0x4b, 0x65, 0x79, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x44, 0x10,
+	0x00, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x50, 0x44, 0x41, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x08,
+	0x0a, 0x04, 0x44, 0x4f, 0x4e, 0x45, 0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x45, 0x58, 0x50, 0x49,
+	0x52, 0x45, 0x53, 0x10, 0x03, 0x12, 0x08, 0x0a, 0x04, 0x4e, 0x41, 0x4d, 0x45, 0x10, 0x04, 0x22,
+	0x1e, 0x0a, 0x09, 0x53, 0x6f, 0x72, 0x74, 0x4f, 0x72, 0x64, 0x65, 0x72, 0x12, 0x08, 0x0a, 0x04,
+	0x44, 0x45, 0x53, 0x43, 0x10, 0x00, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x53, 0x43, 0x10, 0x01, 0x22,
+	0x68, 0x0a, 0x0c, 0x50, 0x75, 0x72, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75,
+	0x64, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64,
+	0x65, 0x12, 0x26, 0x0a, 0x0f, 0x64, 0x6f, 0x6e, 0x65, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65,
+	0x5f, 0x74, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0d, 0x64, 0x6f, 0x6e, 0x65,
+	0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x54, 0x6d, 0x73, 0x22, 0x9c, 0x01, 0x0a, 0x10, 0x4c, 0x69,
+	0x73, 0x74, 0x50, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39,
+	0x0a, 0x07, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
 	0x1f, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64,
 	0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65,
-	0x30, 0x01, 0x42, 0x1b, 0x5a, 0x19, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
-	0x2f, 0x62, 0x73, 0x6d, 0x2f, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2f, 0x72, 0x70, 0x63, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x52, 0x07, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x6e, 0x65, 0x78,
+	0x74, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x6e, 0x65, 0x78, 0x74, 0x50, 0x61, 0x67, 0x65, 0x54, 0x6f, 0x6b, 0x65,
+	0x6e, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x65, 0x73, 0x74, 0x69, 0x6d,
+	0x61, 0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x45, 0x73, 0x74, 0x69, 0x6d, 0x61, 0x74, 0x65, 0x22, 0x85, 0x02, 0x0a, 0x0f, 0x4d, 0x61, 0x72,
+	0x6b, 0x44, 0x6f, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05,
+	0x6f, 0x77, 0x6e, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6f, 0x77, 0x6e,
+	0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x70,
+	0x61, 0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61, 0x6d, 0x65, 0x73,
+	0x70, 0x61, 0x63, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x64, 0x6f, 0x6e, 0x65, 0x5f, 0x74, 0x6d, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x64, 0x6f, 0x6e, 0x65, 0x54, 0x6d, 0x73, 0x12,
+	0x52, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x05, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x36, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d,
+	0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4d, 0x61, 0x72, 0x6b,
+	0x44, 0x6f, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61,
+	0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x22, 0x91, 0x02, 0x0a, 0x10, 0x4d, 0x61, 0x72, 0x6b, 0x44, 0x6f, 0x6e, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x75, 0x6d, 0x5f, 0x6d, 0x61, 0x72,
+	0x6b, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6e, 0x75, 0x6d, 0x4d, 0x61,
+	0x72, 0x6b, 0x65, 0x64, 0x12, 0x50, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73,
+	0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72,
+	0x64, 0x2e, 0x4d, 0x61, 0x72, 0x6b, 0x44, 0x6f, 0x6e, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x2e, 0x43, 0x6f, 0x6e, 0x66, 0x6c, 0x69, 0x63, 0x74, 0x52, 0x09, 0x63, 0x6f, 0x6e,
+	0x66, 0x6c, 0x69, 0x63, 0x74, 0x73, 0x1a, 0x8b, 0x01, 0x0a, 0x08, 0x43, 0x6f, 0x6e, 0x66, 0x6c,
+	0x69, 0x63, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x61, 0x6d,
+	0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x61,
+	0x6d, 0x65, 0x73, 0x70, 0x61, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x73,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1f, 0x2e, 0x62, 0x6c,
+	0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61,
+	0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x22, 0x2e, 0x0a, 0x0d, 0x50, 0x75, 0x72, 0x67, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x75, 0x6d, 0x5f, 0x70, 0x75, 0x72,
+	0x67, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x6e, 0x75, 0x6d, 0x50, 0x75,
+	0x72, 0x67, 0x65, 0x64, 0x22, 0x58, 0x0a, 0x14, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65,
+	0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1b, 0x0a, 0x09,
+	0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x08, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x49, 0x64, 0x12, 0x23, 0x0a, 0x0d, 0x66, 0x65, 0x6e,
+	0x63, 0x69, 0x6e, 0x67, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0c, 0x66, 0x65, 0x6e, 0x63, 0x69, 0x6e, 0x67, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x2d,
+	0x0a, 0x15, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x22, 0x0e, 0x0a,
+	0x0c, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x0f, 0x0a,
+	0x0d, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2a, 0x24,
+	0x0a, 0x06, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x06, 0x0a, 0x02, 0x4f, 0x4b, 0x10, 0x00,
+	0x12, 0x08, 0x0a, 0x04, 0x44, 0x4f, 0x4e, 0x45, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x45,
+	0x4c, 0x44, 0x10, 0x02, 0x2a, 0x21, 0x0a, 0x04, 0x4d, 0x6f, 0x64, 0x65, 0x12, 0x0d, 0x0a, 0x09,
+	0x45, 0x58, 0x43, 0x4c, 0x55, 0x53, 0x49, 0x56, 0x45, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x53,
+	0x48, 0x41, 0x52, 0x45, 0x44, 0x10, 0x01, 0x32, 0xa1, 0x07, 0x0a, 0x02, 0x56, 0x31, 0x12, 0x5c,
+	0x0a, 0x07, 0x41, 0x63, 0x71, 0x75, 0x69, 0x72, 0x65, 0x12, 0x27, 0x2e, 0x62, 0x6c, 0x61, 0x63,
+	0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63,
+	0x6f, 0x72, 0x64, 0x2e, 0x41, 0x63, 0x71, 0x75, 0x69, 0x72, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x28, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65,
+	0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x41, 0x63, 0x71,
+	0x75, 0x69, 0x72, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x56, 0x0a, 0x05,
+	0x52, 0x65, 0x6e, 0x65, 0x77, 0x12, 0x25, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75,
+	0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e,
+	0x52, 0x65, 0x6e, 0x65, 0x77, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x62,
+	0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e,
+	0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x52, 0x65, 0x6e, 0x65, 0x77, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x53, 0x0a, 0x04, 0x44, 0x6f, 0x6e, 0x65, 0x12, 0x24, 0x2e, 0x62,
+	0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e,
+	0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x44, 0x6f, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x25, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65,
+	0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x44, 0x6f, 0x6e,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5f, 0x0a, 0x08, 0x54, 0x72, 0x61,
+	0x6e, 0x73, 0x66, 0x65, 0x72, 0x12, 0x28, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75,
+	0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e,
+	0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x29, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64,
+	0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66,
+	0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4f, 0x0a, 0x04, 0x4c, 0x69,
+	0x73, 0x74, 0x12, 0x24, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65,
+	0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x73,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b,
+	0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f,
+	0x72, 0x64, 0x2e, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x30, 0x01, 0x12, 0x5b, 0x0a, 0x08, 0x4c,
+	0x69, 0x73, 0x74, 0x50, 0x61, 0x67, 0x65, 0x12, 0x24, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73,
+	0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72,
+	0x64, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29, 0x2e,
+	0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61,
+	0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x61, 0x67, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x61, 0x0a, 0x08, 0x4d, 0x61, 0x72, 0x6b,
+	0x44, 0x6f, 0x6e, 0x65, 0x12, 0x28, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61,
+	0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4d,
+	0x61, 0x72, 0x6b, 0x44, 0x6f, 0x6e, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29,
+	0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69,
+	0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x4d, 0x61, 0x72, 0x6b, 0x44, 0x6f, 0x6e,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x12, 0x56, 0x0a, 0x05, 0x50,
+	0x75, 0x72, 0x67, 0x65, 0x12, 0x25, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61,
+	0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x50,
+	0x75, 0x72, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x62, 0x6c,
+	0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61,
+	0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x50, 0x75, 0x72, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x6e, 0x0a, 0x0d, 0x56, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x54,
+	0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x2d, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61,
+	0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x56,
+	0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x2e, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72,
+	0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x56, 0x61,
+	0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x56, 0x0a, 0x05, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x25, 0x2e, 0x62,
+	0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72, 0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e,
+	0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x62, 0x6c, 0x61, 0x63, 0x6b, 0x73, 0x71, 0x75, 0x61, 0x72,
+	0x65, 0x6d, 0x65, 0x64, 0x69, 0x61, 0x2e, 0x61, 0x63, 0x63, 0x6f, 0x72, 0x64, 0x2e, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x1b, 0x5a, 0x19, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x62, 0x73, 0x6d, 0x2f, 0x61, 0x63,
+	0x63, 0x6f, 0x72, 0x64, 0x2f, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -845,49 +2219,86 @@ func file_rpc_accord_proto_rawDescGZIP() []byte {
 	return file_rpc_accord_proto_rawDescData
 }
 
-var file_rpc_accord_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_rpc_accord_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_rpc_accord_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_rpc_accord_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
 var file_rpc_accord_proto_goTypes = []interface{}{
-	(Status)(0),                    // 0: blacksquaremedia.accord.Status
-	(ListRequest_Filter_Status)(0), // 1: blacksquaremedia.accord.ListRequest.Filter.Status
-	(*Handle)(nil),                 // 2: blacksquaremedia.accord.Handle
-	(*AcquireRequest)(nil),         // 3: blacksquaremedia.accord.AcquireRequest
-	(*AcquireResponse)(nil),        // 4: blacksquaremedia.accord.AcquireResponse
-	(*RenewRequest)(nil),           // 5: blacksquaremedia.accord.RenewRequest
-	(*RenewResponse)(nil),          // 6: blacksquaremedia.accord.RenewResponse
-	(*DoneRequest)(nil),            // 7: blacksquaremedia.accord.DoneRequest
-	(*DoneResponse)(nil),           // 8: blacksquaremedia.accord.DoneResponse
-	(*ListRequest)(nil),            // 9: blacksquaremedia.accord.ListRequest
-	nil,                            // 10: blacksquaremedia.accord.Handle.MetadataEntry
-	nil,                            // 11: blacksquaremedia.accord.AcquireRequest.MetadataEntry
-	nil,                            // 12: blacksquaremedia.accord.RenewRequest.MetadataEntry
-	nil,                            // 13: blacksquaremedia.accord.DoneRequest.MetadataEntry
-	(*ListRequest_Filter)(nil),     // 14: blacksquaremedia.accord.ListRequest.Filter
-	nil,                            // 15: blacksquaremedia.accord.ListRequest.Filter.MetadataEntry
+	(Status)(0),                       // 0: blacksquaremedia.accord.Status
+	(Mode)(0),                         // 1: blacksquaremedia.accord.Mode
+	(ListRequest_SortKey)(0),          // 2: blacksquaremedia.accord.ListRequest.SortKey
+	(ListRequest_SortOrder)(0),        // 3: blacksquaremedia.accord.ListRequest.SortOrder
+	(ListRequest_Filter_Status)(0),    // 4: blacksquaremedia.accord.ListRequest.Filter.Status
+	(*Handle)(nil),                    // 5: blacksquaremedia.accord.Handle
+	(*AcquireRequest)(nil),            // 6: blacksquaremedia.accord.AcquireRequest
+	(*AcquireResponse)(nil),           // 7: blacksquaremedia.accord.AcquireResponse
+	(*RenewRequest)(nil),              // 8: blacksquaremedia.accord.RenewRequest
+	(*RenewResponse)(nil),             // 9: blacksquaremedia.accord.RenewResponse
+	(*DoneRequest)(nil),               // 10: blacksquaremedia.accord.DoneRequest
+	(*DoneResponse)(nil),              // 11: blacksquaremedia.accord.DoneResponse
+	(*TransferRequest)(nil),           // 12: blacksquaremedia.accord.TransferRequest
+	(*TransferResponse)(nil),          // 13: blacksquaremedia.accord.TransferResponse
+	(*ListRequest)(nil),               // 14: blacksquaremedia.accord.ListRequest
+	(*PurgeRequest)(nil),              // 15: blacksquaremedia.accord.PurgeRequest
+	(*ListPageResponse)(nil),          // 16: blacksquaremedia.accord.ListPageResponse
+	(*MarkDoneRequest)(nil),           // 17: blacksquaremedia.accord.MarkDoneRequest
+	(*MarkDoneResponse)(nil),          // 18: blacksquaremedia.accord.MarkDoneResponse
+	(*PurgeResponse)(nil),             // 19: blacksquaremedia.accord.PurgeResponse
+	(*ValidateTokenRequest)(nil),      // 20: blacksquaremedia.accord.ValidateTokenRequest
+	(*ValidateTokenResponse)(nil),     // 21: blacksquaremedia.accord.ValidateTokenResponse
+	(*CheckRequest)(nil),              // 22: blacksquaremedia.accord.CheckRequest
+	(*CheckResponse)(nil),             // 23: blacksquaremedia.accord.CheckResponse
+	nil,                               // 24: blacksquaremedia.accord.Handle.MetadataEntry
+	nil,                               // 25: blacksquaremedia.accord.AcquireRequest.MetadataEntry
+	nil,                               // 26: blacksquaremedia.accord.RenewRequest.MetadataEntry
+	nil,                               // 27: blacksquaremedia.accord.DoneRequest.MetadataEntry
+	(*ListRequest_Filter)(nil),        // 28: blacksquaremedia.accord.ListRequest.Filter
+	nil,                               // 29: blacksquaremedia.accord.ListRequest.Filter.MetadataEntry
+	nil,                               // 30: blacksquaremedia.accord.MarkDoneRequest.MetadataEntry
+	(*MarkDoneResponse_Conflict)(nil), // 31: blacksquaremedia.accord.MarkDoneResponse.Conflict
 }
 var file_rpc_accord_proto_depIdxs = []int32{
-	10, // 0: blacksquaremedia.accord.Handle.metadata:type_name -> blacksquaremedia.accord.Handle.MetadataEntry
-	11, // 1: blacksquaremedia.accord.AcquireRequest.metadata:type_name -> blacksquaremedia.accord.AcquireRequest.MetadataEntry
-	0,  // 2: blacksquaremedia.accord.AcquireResponse.status:type_name -> blacksquaremedia.accord.Status
-	2,  // 3: blacksquaremedia.accord.AcquireResponse.handle:type_name -> blacksquaremedia.accord.Handle
-	12, // 4: blacksquaremedia.accord.RenewRequest.metadata:type_name -> blacksquaremedia.accord.RenewRequest.MetadataEntry
-	13, // 5: blacksquaremedia.accord.DoneRequest.metadata:type_name -> blacksquaremedia.accord.DoneRequest.MetadataEntry
-	14, // 6: blacksquaremedia.accord.ListRequest.filter:type_name -> blacksquaremedia.accord.ListRequest.Filter
-	1,  // 7: blacksquaremedia.accord.ListRequest.Filter.status:type_name -> blacksquaremedia.accord.ListRequest.Filter.Status
-	15, // 8: blacksquaremedia.accord.ListRequest.Filter.metadata:type_name -> blacksquaremedia.accord.ListRequest.Filter.MetadataEntry
-	3,  // 9: blacksquaremedia.accord.V1.Acquire:input_type -> blacksquaremedia.accord.AcquireRequest
-	5,  // 10: blacksquaremedia.accord.V1.Renew:input_type -> blacksquaremedia.accord.RenewRequest
-	7,  // 11: blacksquaremedia.accord.V1.Done:input_type -> blacksquaremedia.accord.DoneRequest
-	9,  // 12: blacksquaremedia.accord.V1.List:input_type -> blacksquaremedia.accord.ListRequest
-	4,  // 13: blacksquaremedia.accord.V1.Acquire:output_type -> blacksquaremedia.accord.AcquireResponse
-	6,  // 14: blacksquaremedia.accord.V1.Renew:output_type -> blacksquaremedia.accord.RenewResponse
-	8,  // 15: blacksquaremedia.accord.V1.Done:output_type -> blacksquaremedia.accord.DoneResponse
-	2,  // 16: blacksquaremedia.accord.V1.List:output_type -> blacksquaremedia.accord.Handle
-	13, // [13:17] is the sub-list for method output_type
-	9,  // [9:13] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	24, // 0: blacksquaremedia.accord.Handle.metadata:type_name -> blacksquaremedia.accord.Handle.MetadataEntry
+	1,  // 1: blacksquaremedia.accord.Handle.mode:type_name -> blacksquaremedia.accord.Mode
+	25, // 2: blacksquaremedia.accord.AcquireRequest.metadata:type_name -> blacksquaremedia.accord.AcquireRequest.MetadataEntry
+	1,  // 3: blacksquaremedia.accord.AcquireRequest.mode:type_name -> blacksquaremedia.accord.Mode
+	0,  // 4: blacksquaremedia.accord.AcquireResponse.status:type_name -> blacksquaremedia.accord.Status
+	5,  // 5: blacksquaremedia.accord.AcquireResponse.handle:type_name -> blacksquaremedia.accord.Handle
+	26, // 6: blacksquaremedia.accord.RenewRequest.metadata:type_name -> blacksquaremedia.accord.RenewRequest.MetadataEntry
+	27, // 7: blacksquaremedia.accord.DoneRequest.metadata:type_name -> blacksquaremedia.accord.DoneRequest.MetadataEntry
+	5,  // 8: blacksquaremedia.accord.TransferResponse.handle:type_name -> blacksquaremedia.accord.Handle
+	28, // 9: blacksquaremedia.accord.ListRequest.filter:type_name -> blacksquaremedia.accord.ListRequest.Filter
+	2,  // 10: blacksquaremedia.accord.ListRequest.sort:type_name -> blacksquaremedia.accord.ListRequest.SortKey
+	3,  // 11: blacksquaremedia.accord.ListRequest.order:type_name -> blacksquaremedia.accord.ListRequest.SortOrder
+	5,  // 12: blacksquaremedia.accord.ListPageResponse.handles:type_name -> blacksquaremedia.accord.Handle
+	30, // 13: blacksquaremedia.accord.MarkDoneRequest.metadata:type_name -> blacksquaremedia.accord.MarkDoneRequest.MetadataEntry
+	31, // 14: blacksquaremedia.accord.MarkDoneResponse.conflicts:type_name -> blacksquaremedia.accord.MarkDoneResponse.Conflict
+	4,  // 15: blacksquaremedia.accord.ListRequest.Filter.status:type_name -> blacksquaremedia.accord.ListRequest.Filter.Status
+	29, // 16: blacksquaremedia.accord.ListRequest.Filter.metadata:type_name -> blacksquaremedia.accord.ListRequest.Filter.MetadataEntry
+	0,  // 17: blacksquaremedia.accord.MarkDoneResponse.Conflict.status:type_name -> blacksquaremedia.accord.Status
+	6,  // 18: blacksquaremedia.accord.V1.Acquire:input_type -> blacksquaremedia.accord.AcquireRequest
+	8,  // 19: blacksquaremedia.accord.V1.Renew:input_type -> blacksquaremedia.accord.RenewRequest
+	10, // 20: blacksquaremedia.accord.V1.Done:input_type -> blacksquaremedia.accord.DoneRequest
+	12, // 21: blacksquaremedia.accord.V1.Transfer:input_type -> blacksquaremedia.accord.TransferRequest
+	14, // 22: blacksquaremedia.accord.V1.List:input_type -> blacksquaremedia.accord.ListRequest
+	14, // 23: blacksquaremedia.accord.V1.ListPage:input_type -> blacksquaremedia.accord.ListRequest
+	17, // 24: blacksquaremedia.accord.V1.MarkDone:input_type -> blacksquaremedia.accord.MarkDoneRequest
+	15, // 25: blacksquaremedia.accord.V1.Purge:input_type -> blacksquaremedia.accord.PurgeRequest
+	20, // 26: blacksquaremedia.accord.V1.ValidateToken:input_type -> blacksquaremedia.accord.ValidateTokenRequest
+	22, // 27: blacksquaremedia.accord.V1.Check:input_type -> blacksquaremedia.accord.CheckRequest
+	7,  // 28: blacksquaremedia.accord.V1.Acquire:output_type -> blacksquaremedia.accord.AcquireResponse
+	9,  // 29: blacksquaremedia.accord.V1.Renew:output_type -> blacksquaremedia.accord.RenewResponse
+	11, // 30: blacksquaremedia.accord.V1.Done:output_type -> blacksquaremedia.accord.DoneResponse
+	13, // 31: blacksquaremedia.accord.V1.Transfer:output_type -> blacksquaremedia.accord.TransferResponse
+	5,  // 32: blacksquaremedia.accord.V1.List:output_type -> blacksquaremedia.accord.Handle
+	16, // 33: blacksquaremedia.accord.V1.ListPage:output_type -> blacksquaremedia.accord.ListPageResponse
+	18, // 34: blacksquaremedia.accord.V1.MarkDone:output_type -> blacksquaremedia.accord.MarkDoneResponse
+	19, // 35: blacksquaremedia.accord.V1.Purge:output_type -> blacksquaremedia.accord.PurgeResponse
+	21, // 36: blacksquaremedia.accord.V1.ValidateToken:output_type -> blacksquaremedia.accord.ValidateTokenResponse
+	23, // 37: blacksquaremedia.accord.V1.Check:output_type -> blacksquaremedia.accord.CheckResponse
+	28, // [28:38] is the sub-list for method output_type
+	18, // [18:28] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
 }
 
 func init() { file_rpc_accord_proto_init() }
@@ -981,6 +2392,30 @@ func file_rpc_accord_proto_init() {
 			}
 		}
 		file_rpc_accord_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransferRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_accord_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TransferResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_accord_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ListRequest); i {
 			case 0:
 				return &v.state
@@ -992,7 +2427,115 @@ func file_rpc_accord_proto_init() {
 				return nil
 			}
 		}
+		file_rpc_accord_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PurgeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_accord_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListPageResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 		file_rpc_accord_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MarkDoneRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_accord_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MarkDoneResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_accord_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PurgeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_accord_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateTokenRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_accord_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ValidateTokenResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_accord_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_accord_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CheckResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_accord_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ListRequest_Filter); i {
 			case 0:
 				return &v.state
@@ -1004,14 +2547,26 @@ func file_rpc_accord_proto_init() {
 				return nil
 			}
 		}
+		file_rpc_accord_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MarkDoneResponse_Conflict); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_rpc_accord_proto_rawDesc,
-			NumEnums:      2,
-			NumMessages:   14,
+			NumEnums:      5,
+			NumMessages:   27,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -28,8 +28,26 @@ type V1Client interface {
 	Renew(ctx context.Context, in *RenewRequest, opts ...grpc.CallOption) (*RenewResponse, error)
 	// Done marks an handle as completed.
 	Done(ctx context.Context, in *DoneRequest, opts ...grpc.CallOption) (*DoneResponse, error)
+	// Transfer atomically reassigns a held handle to a new owner.
+	Transfer(ctx context.Context, in *TransferRequest, opts ...grpc.CallOption) (*TransferResponse, error)
 	// List streams handles that are done.
 	List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (V1_ListClient, error)
+	// ListPage returns a single page of handles. The request cursor is used
+	// as the page token, the limit as the page size.
+	ListPage(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListPageResponse, error)
+	// MarkDone inserts resources as done without acquiring them, e.g. to
+	// seed accord from a legacy system. Resources which already exist are
+	// reported as conflicts. Records are committed in batches, a failed
+	// stream may have been applied partially.
+	MarkDone(ctx context.Context, opts ...grpc.CallOption) (V1_MarkDoneClient, error)
+	// Purge deletes handles which were marked as done before a cutoff time.
+	// This is an administrative operation.
+	Purge(ctx context.Context, in *PurgeRequest, opts ...grpc.CallOption) (*PurgeResponse, error)
+	// ValidateToken checks that a fencing token is still current, i.e. that
+	// the handle has not been taken over or marked as done since.
+	ValidateToken(ctx context.Context, in *ValidateTokenRequest, opts ...grpc.CallOption) (*ValidateTokenResponse, error)
+	// Check pings the backend and fails unless it is able to serve requests.
+	Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error)
 }
 
 type v1Client struct {
@@ -67,6 +85,15 @@ func (c *v1Client) Done(ctx context.Context, in *DoneRequest, opts ...grpc.CallO
 	return out, nil
 }
 
+func (c *v1Client) Transfer(ctx context.Context, in *TransferRequest, opts ...grpc.CallOption) (*TransferResponse, error) {
+	out := new(TransferResponse)
+	err := c.cc.Invoke(ctx, "/blacksquaremedia.accord.V1/Transfer", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *v1Client) List(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (V1_ListClient, error) {
 	stream, err := c.cc.NewStream(ctx, &V1_ServiceDesc.Streams[0], "/blacksquaremedia.accord.V1/List", opts...)
 	if err != nil {
@@ -99,6 +126,76 @@ func (x *v1ListClient) Recv() (*Handle, error) {
 	return m, nil
 }
 
+func (c *v1Client) ListPage(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListPageResponse, error) {
+	out := new(ListPageResponse)
+	err := c.cc.Invoke(ctx, "/blacksquaremedia.accord.V1/ListPage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *v1Client) MarkDone(ctx context.Context, opts ...grpc.CallOption) (V1_MarkDoneClient, error) {
+	stream, err := c.cc.NewStream(ctx, &V1_ServiceDesc.Streams[1], "/blacksquaremedia.accord.V1/MarkDone", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &v1MarkDoneClient{stream}
+	return x, nil
+}
+
+type V1_MarkDoneClient interface {
+	Send(*MarkDoneRequest) error
+	CloseAndRecv() (*MarkDoneResponse, error)
+	grpc.ClientStream
+}
+
+type v1MarkDoneClient struct {
+	grpc.ClientStream
+}
+
+func (x *v1MarkDoneClient) Send(m *MarkDoneRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *v1MarkDoneClient) CloseAndRecv() (*MarkDoneResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(MarkDoneResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *v1Client) Purge(ctx context.Context, in *PurgeRequest, opts ...grpc.CallOption) (*PurgeResponse, error) {
+	out := new(PurgeResponse)
+	err := c.cc.Invoke(ctx, "/blacksquaremedia.accord.V1/Purge", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *v1Client) ValidateToken(ctx context.Context, in *ValidateTokenRequest, opts ...grpc.CallOption) (*ValidateTokenResponse, error) {
+	out := new(ValidateTokenResponse)
+	err := c.cc.Invoke(ctx, "/blacksquaremedia.accord.V1/ValidateToken", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *v1Client) Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error) {
+	out := new(CheckResponse)
+	err := c.cc.Invoke(ctx, "/blacksquaremedia.accord.V1/Check", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // V1Server is the server API for V1 service.
 // All implementations must embed UnimplementedV1Server
 // for forward compatibility
@@ -109,8 +206,26 @@ type V1Server interface {
 	Renew(context.Context, *RenewRequest) (*RenewResponse, error)
 	// Done marks an handle as completed.
 	Done(context.Context, *DoneRequest) (*DoneResponse, error)
+	// Transfer atomically reassigns a held handle to a new owner.
+	Transfer(context.Context, *TransferRequest) (*TransferResponse, error)
 	// List streams handles that are done.
 	List(*ListRequest, V1_ListServer) error
+	// ListPage returns a single page of handles. The request cursor is used
+	// as the page token, the limit as the page size.
+	ListPage(context.Context, *ListRequest) (*ListPageResponse, error)
+	// MarkDone inserts resources as done without acquiring them, e.g. to
+	// seed accord from a legacy system. Resources which already exist are
+	// reported as conflicts. Records are committed in batches, a failed
+	// stream may have been applied partially.
+	MarkDone(V1_MarkDoneServer) error
+	// Purge deletes handles which were marked as done before a cutoff time.
+	// This is an administrative operation.
+	Purge(context.Context, *PurgeRequest) (*PurgeResponse, error)
+	// ValidateToken checks that a fencing token is still current, i.e. that
+	// the handle has not been taken over or marked as done since.
+	ValidateToken(context.Context, *ValidateTokenRequest) (*ValidateTokenResponse, error)
+	// Check pings the backend and fails unless it is able to serve requests.
+	Check(context.Context, *CheckRequest) (*CheckResponse, error)
 	mustEmbedUnimplementedV1Server()
 }
 
@@ -127,9 +242,27 @@ func (UnimplementedV1Server) Renew(context.Context, *RenewRequest) (*RenewRespon
 func (UnimplementedV1Server) Done(context.Context, *DoneRequest) (*DoneResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Done not implemented")
 }
+func (UnimplementedV1Server) Transfer(context.Context, *TransferRequest) (*TransferResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transfer not implemented")
+}
 func (UnimplementedV1Server) List(*ListRequest, V1_ListServer) error {
 	return status.Errorf(codes.Unimplemented, "method List not implemented")
 }
+func (UnimplementedV1Server) ListPage(context.Context, *ListRequest) (*ListPageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPage not implemented")
+}
+func (UnimplementedV1Server) MarkDone(V1_MarkDoneServer) error {
+	return status.Errorf(codes.Unimplemented, "method MarkDone not implemented")
+}
+func (UnimplementedV1Server) Purge(context.Context, *PurgeRequest) (*PurgeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Purge not implemented")
+}
+func (UnimplementedV1Server) ValidateToken(context.Context, *ValidateTokenRequest) (*ValidateTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateToken not implemented")
+}
+func (UnimplementedV1Server) Check(context.Context, *CheckRequest) (*CheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Check not implemented")
+}
 func (UnimplementedV1Server) mustEmbedUnimplementedV1Server() {}
 
 // UnsafeV1Server may be embedded to opt out of forward compatibility for this service.
@@ -197,6 +330,24 @@ func _V1_Done_Handler(srv interface{}, ctx context.Context, dec func(interface{}
 	return interceptor(ctx, in, info, handler)
 }
 
+func _V1_Transfer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransferRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(V1Server).Transfer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blacksquaremedia.accord.V1/Transfer",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(V1Server).Transfer(ctx, req.(*TransferRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _V1_List_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(ListRequest)
 	if err := stream.RecvMsg(m); err != nil {
@@ -218,6 +369,104 @@ func (x *v1ListServer) Send(m *Handle) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _V1_ListPage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(V1Server).ListPage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blacksquaremedia.accord.V1/ListPage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(V1Server).ListPage(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _V1_MarkDone_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(V1Server).MarkDone(&v1MarkDoneServer{stream})
+}
+
+type V1_MarkDoneServer interface {
+	SendAndClose(*MarkDoneResponse) error
+	Recv() (*MarkDoneRequest, error)
+	grpc.ServerStream
+}
+
+type v1MarkDoneServer struct {
+	grpc.ServerStream
+}
+
+func (x *v1MarkDoneServer) SendAndClose(m *MarkDoneResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *v1MarkDoneServer) Recv() (*MarkDoneRequest, error) {
+	m := new(MarkDoneRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _V1_Purge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(V1Server).Purge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blacksquaremedia.accord.V1/Purge",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(V1Server).Purge(ctx, req.(*PurgeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _V1_ValidateToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(V1Server).ValidateToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blacksquaremedia.accord.V1/ValidateToken",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(V1Server).ValidateToken(ctx, req.(*ValidateTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _V1_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(V1Server).Check(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/blacksquaremedia.accord.V1/Check",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(V1Server).Check(ctx, req.(*CheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // V1_ServiceDesc is the grpc.ServiceDesc for V1 service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -237,6 +486,26 @@ var V1_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Done",
 			Handler:    _V1_Done_Handler,
 		},
+		{
+			MethodName: "Transfer",
+			Handler:    _V1_Transfer_Handler,
+		},
+		{
+			MethodName: "ListPage",
+			Handler:    _V1_ListPage_Handler,
+		},
+		{
+			MethodName: "Purge",
+			Handler:    _V1_Purge_Handler,
+		},
+		{
+			MethodName: "ValidateToken",
+			Handler:    _V1_ValidateToken_Handler,
+		},
+		{
+			MethodName: "Check",
+			Handler:    _V1_Check_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -244,6 +513,11 @@ var V1_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _V1_List_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "MarkDone",
+			Handler:       _V1_MarkDone_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "rpc/accord.proto",
 }
@@ -9,6 +9,16 @@ func (h *Handle) IsDone() bool {
 	return h.DoneTms != 0
 }
 
+// CreatedTime converts CreatedTms to time.Time.
+func (h *Handle) CreatedTime() time.Time {
+	return millisToTime(h.CreatedTms)
+}
+
+// UpdatedTime converts UpdatedTms to time.Time.
+func (h *Handle) UpdatedTime() time.Time {
+	return millisToTime(h.UpdatedTms)
+}
+
 // ExpTime converts ExpTms to time.Time.
 func (h *Handle) ExpTime() time.Time {
 	return millisToTime(h.ExpTms)
@@ -19,6 +29,32 @@ func (h *Handle) DoneTime() time.Time {
 	return millisToTime(h.DoneTms)
 }
 
+// DoneBefore converts DoneBeforeTms to time.Time.
+func (r *PurgeRequest) DoneBefore() time.Time {
+	return millisToTime(r.DoneBeforeTms)
+}
+
+// CreatedAfter converts CreatedAfterTms to time.Time.
+func (f *ListRequest_Filter) CreatedAfter() time.Time { return millisToTime(f.GetCreatedAfterTms()) }
+
+// CreatedBefore converts CreatedBeforeTms to time.Time.
+func (f *ListRequest_Filter) CreatedBefore() time.Time { return millisToTime(f.GetCreatedBeforeTms()) }
+
+// UpdatedAfter converts UpdatedAfterTms to time.Time.
+func (f *ListRequest_Filter) UpdatedAfter() time.Time { return millisToTime(f.GetUpdatedAfterTms()) }
+
+// UpdatedBefore converts UpdatedBeforeTms to time.Time.
+func (f *ListRequest_Filter) UpdatedBefore() time.Time { return millisToTime(f.GetUpdatedBeforeTms()) }
+
+// DoneAfter converts DoneAfterTms to time.Time.
+func (f *ListRequest_Filter) DoneAfter() time.Time { return millisToTime(f.GetDoneAfterTms()) }
+
+// DoneBefore converts DoneBeforeTms to time.Time.
+func (f *ListRequest_Filter) DoneBefore() time.Time { return millisToTime(f.GetDoneBeforeTms()) }
+
+// DoneTime converts DoneTms to time.Time.
+func (r *MarkDoneRequest) DoneTime() time.Time { return millisToTime(r.GetDoneTms()) }
+
 func millisToTime(ms int64) time.Time {
 	if ms == 0 {
 		return time.Time{}